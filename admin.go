@@ -0,0 +1,467 @@
+package redCorn
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 管理接口查询调度时间的默认次数和最大次数
+const (
+	defaultAdminNextRuns = 5
+	maxAdminNextRuns     = 100
+)
+
+// defaultAdminSimulateWindow 管理接口模拟调度的默认时间窗口
+const defaultAdminSimulateWindow = 24 * time.Hour
+
+// maxAdminJobPayload 管理接口提交任务时参数的最大长度
+const maxAdminJobPayload = 1 << 20
+
+// AdminHTTPCfg 内嵌 HTTP 管理接口配置
+type AdminHTTPCfg struct {
+	Addr      string // 监听地址，如 ":8080"，为空表示不启用
+	AuthToken string // 访问令牌，请求需携带 "Authorization: Bearer <token>" 或 "?token=<token>"，为空表示不鉴权
+}
+
+// adminTask 管理接口返回的任务信息
+type adminTask struct {
+	Name        string      `json:"name"`
+	Group       string      `json:"group,omitempty"`
+	Spec        string      `json:"spec"`
+	Description string      `json:"description,omitempty"` // 表达式的易读描述
+	NextRun     time.Time   `json:"next_run"`
+	Paused      bool        `json:"paused"`
+	Status      *TaskStatus `json:"status,omitempty"`
+}
+
+// AdminHandler 返回管理接口的 http.Handler，可挂载到已有的 HTTP 服务上
+//
+//	GET  /                          任务面板页面
+//	GET  /state                     查看任务管理器的运行状态，未运行时返回 503，可用于健康检查
+//	GET  /stats                     查看当前节点上各任务的执行统计
+//	GET  /tasks                     列出当前节点注册的任务
+//	GET  /locks                     列出集群中当前被持有的锁
+//	GET  /tasks/{name}              查看任务详情及集群运行状态
+//	GET  /tasks/{name}/history      查看执行历史，支持 ?limit=N
+//	GET  /tasks/{name}/next         查看接下来的调度时间，支持 ?n=N，默认5次
+//	GET  /simulate                  列出时间窗口内所有任务的调度时刻，支持 ?from=<RFC3339>&to=<RFC3339>，默认从现在起24小时
+//	POST /tasks/{name}/trigger      立即触发一次执行
+//	POST /tasks/{name}/pause        暂停任务，并通知集群中所有节点
+//	POST /tasks/{name}/resume       恢复任务，并通知集群中所有节点
+//	POST /tasks/{name}/unlock       强制释放已下线节点遗留的锁
+//	GET  /cluster                   查看集群暂停标记
+//	POST /cluster/pause             暂停整个集群，支持 ?reason=<原因>
+//	POST /cluster/resume            恢复整个集群
+//	POST /jobs/{name}               提交任务，请求体为任务参数，加入工作队列立即执行；指定 ?at=<RFC3339> 或 ?delay=<时长> 时加入延迟任务队列
+func (dtm *DistributedTaskManager) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", dtm.handleDashboard)
+	mux.HandleFunc("/state", dtm.handleState)
+	mux.HandleFunc("/stats", dtm.handleStats)
+	mux.HandleFunc("/tasks", dtm.handleListTasks)
+	mux.HandleFunc("/locks", dtm.handleListLocks)
+	mux.HandleFunc("/simulate", dtm.handleSimulate)
+	mux.HandleFunc("/tasks/", dtm.handleTask)
+	mux.HandleFunc("/cluster", dtm.handleClusterPause)
+	mux.HandleFunc("/cluster/", dtm.handleClusterPause)
+	mux.HandleFunc("/jobs/", dtm.handleSubmitJob)
+	return dtm.adminAuth(mux)
+}
+
+// startAdminHTTP 启动内嵌 HTTP 管理接口
+func (dtm *DistributedTaskManager) startAdminHTTP() {
+	addr := dtm.cfg.AdminHTTP.Addr
+	if addr == "" {
+		return
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		dtm.log.Error("Failed to start admin HTTP server on ", addr, ": ", err)
+		return
+	}
+
+	dtm.adminServer = &http.Server{
+		Handler:           dtm.AdminHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := dtm.adminServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			dtm.log.Error("Admin HTTP server stopped: ", err)
+		}
+	}()
+	dtm.log.Info("Admin HTTP server listening on ", ln.Addr())
+}
+
+// stopAdminHTTP 关闭内嵌 HTTP 管理接口
+func (dtm *DistributedTaskManager) stopAdminHTTP() {
+	if dtm.adminServer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	if err := dtm.adminServer.Shutdown(ctx); err != nil {
+		dtm.log.Error("Error shutting down admin HTTP server: ", err)
+	}
+}
+
+// adminAuth 校验访问令牌
+func (dtm *DistributedTaskManager) adminAuth(next http.Handler) http.Handler {
+	token := dtm.cfg.AdminHTTP.AuthToken
+	if token == "" {
+		return next
+	}
+	expected := []byte(token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 浏览器访问面板时无法设置请求头，允许通过查询参数传递令牌
+		given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if given == "" {
+			given = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(given), expected) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleState GET /state
+func (dtm *DistributedTaskManager) handleState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	state := dtm.State()
+	code := http.StatusOK
+	if state.Status != StatusRunning {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, state)
+}
+
+// handleStats GET /stats
+func (dtm *DistributedTaskManager) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	writeJSON(w, http.StatusOK, dtm.Stats())
+}
+
+// handleListTasks GET /tasks
+func (dtm *DistributedTaskManager) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	dtm.mu.RLock()
+	tasks := make([]adminTask, 0, len(dtm.tasks))
+	for _, t := range dtm.tasks {
+		tasks = append(tasks, dtm.adminTask(t))
+	}
+	dtm.mu.RUnlock()
+
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+// handleListLocks GET /locks
+func (dtm *DistributedTaskManager) handleListLocks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	locks, err := dtm.ListLocks()
+	if errors.Is(err, ErrLockInspectUnsupported) {
+		writeError(w, http.StatusNotImplemented, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, locks)
+}
+
+// handleTask /tasks/{name}[/{action}]
+func (dtm *DistributedTaskManager) handleTask(w http.ResponseWriter, r *http.Request) {
+	name, action := strings.TrimPrefix(r.URL.Path, "/tasks/"), ""
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name, action = name[:i], name[i+1:]
+	}
+
+	switch action {
+	case "":
+		if r.Method != http.MethodGet {
+			break
+		}
+		dtm.mu.RLock()
+		t, exists := dtm.tasks[name]
+		dtm.mu.RUnlock()
+		if !exists {
+			writeError(w, http.StatusNotFound, ErrTaskNotFound)
+			return
+		}
+		info := dtm.adminTask(t)
+		if status, err := dtm.GetTaskStatus(name); err == nil {
+			info.Status = &status
+		}
+		writeJSON(w, http.StatusOK, info)
+		return
+
+	case "history":
+		if r.Method != http.MethodGet {
+			break
+		}
+		limit, _ := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64)
+		records, err := dtm.GetHistory(name, limit)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, records)
+		return
+
+	case "next":
+		if r.Method != http.MethodGet {
+			break
+		}
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		if n <= 0 {
+			n = defaultAdminNextRuns
+		}
+		if n > maxAdminNextRuns {
+			n = maxAdminNextRuns
+		}
+		runs, err := dtm.NextRuns(name, n)
+		if err != nil {
+			writeTaskError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, runs)
+		return
+
+	case "trigger", "pause", "resume":
+		if r.Method != http.MethodPost {
+			break
+		}
+		var err error
+		switch action {
+		case "trigger":
+			err = dtm.TriggerTask(name)
+		case "pause":
+			err = dtm.BroadcastPause(name)
+		case "resume":
+			err = dtm.BroadcastResume(name)
+		}
+		if err != nil {
+			writeTaskError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"task": name, "result": "ok"})
+		return
+
+	case "unlock":
+		if r.Method != http.MethodPost {
+			break
+		}
+		lock, err := dtm.ForceUnlock(name)
+		switch {
+		case errors.Is(err, ErrLockNotHeld), errors.Is(err, ErrLockHolderAlive):
+			writeError(w, http.StatusConflict, err)
+		case errors.Is(err, ErrForceUnlockUnsupported), errors.Is(err, ErrLockInspectUnsupported):
+			writeError(w, http.StatusNotImplemented, err)
+		case err != nil:
+			writeTaskError(w, err)
+		default:
+			writeJSON(w, http.StatusOK, lock)
+		}
+		return
+
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+}
+
+// handleClusterPause /cluster[/{action}]
+func (dtm *DistributedTaskManager) handleClusterPause(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimPrefix(r.URL.Path, "/cluster") {
+	case "", "/":
+		if r.Method != http.MethodGet {
+			break
+		}
+		pause, err := dtm.controller.ClusterPaused(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"paused": pause != nil, "pause": pause})
+		return
+
+	case "/pause":
+		if r.Method != http.MethodPost {
+			break
+		}
+		reason := r.URL.Query().Get("reason")
+		if err := dtm.controller.PauseCluster(r.Context(), reason, dtm.nodeID+" via "+r.RemoteAddr); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		dtm.log.Warn("Cluster paused via admin API: ", reason)
+		writeJSON(w, http.StatusOK, map[string]string{"result": "ok"})
+		return
+
+	case "/resume":
+		if r.Method != http.MethodPost {
+			break
+		}
+		if err := dtm.controller.ResumeCluster(r.Context(), dtm.nodeID+" via "+r.RemoteAddr); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		dtm.log.Info("Cluster resumed via admin API")
+		writeJSON(w, http.StatusOK, map[string]string{"result": "ok"})
+		return
+
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+}
+
+// handleSimulate GET /simulate
+func (dtm *DistributedTaskManager) handleSimulate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	query := r.URL.Query()
+	from := dtm.clock.Now()
+	if v := query.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid from %q: %v", v, err))
+			return
+		}
+		from = t
+	}
+	to := from.Add(defaultAdminSimulateWindow)
+	if v := query.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid to %q: %v", v, err))
+			return
+		}
+		to = t
+	}
+
+	runs, err := dtm.Simulate(from, to)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, runs)
+}
+
+// handleSubmitJob POST /jobs/{name}
+// 任务由集群中注册了 name 处理函数的节点执行，当前节点不需要注册处理函数
+func (dtm *DistributedTaskManager) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/jobs/")
+	if name == "" || strings.Contains(name, "/") {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	var dueAt time.Time
+	query := r.URL.Query()
+	if at := query.Get("at"); at != "" {
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid at: %v", err))
+			return
+		}
+		dueAt = t
+	} else if delay := query.Get("delay"); delay != "" {
+		d, err := time.ParseDuration(delay)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid delay: %v", err))
+			return
+		}
+		dueAt = dtm.clock.Now().Add(d)
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAdminJobPayload))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("failed to read payload: %v", err))
+		return
+	}
+
+	job := Job{Name: name, Payload: payload, DueAt: dueAt, EnqueuedBy: dtm.nodeID}
+	queue := "work"
+	if dueAt.IsZero() {
+		job, err = dtm.controller.PushJob(r.Context(), job)
+	} else {
+		queue = "delayed"
+		job, err = dtm.controller.EnqueueJob(r.Context(), job)
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	dtm.logRun(slog.LevelInfo, name, job.ID, "Job submitted via admin API", slog.String("queue", queue), slog.String("remote", r.RemoteAddr))
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{"id": job.ID, "name": name, "queue": queue, "due_at": job.DueAt})
+}
+
+// adminTask 生成任务的管理接口信息
+func (dtm *DistributedTaskManager) adminTask(t *distributedTask) adminTask {
+	return adminTask{
+		Name:        t.name,
+		Group:       t.opts.group,
+		Spec:        t.spec,
+		Description: t.description,
+		NextRun:     dtm.nextRun(t),
+		Paused:      t.paused.Load(),
+	}
+}
+
+// writeJSON 输出 JSON 响应
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError 输出错误响应
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+// writeTaskError 按错误类型输出任务操作的错误响应
+func writeTaskError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrTaskNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
@@ -0,0 +1,71 @@
+package redCorn
+
+import (
+	"hash/fnv"
+	"sort"
+)
+
+// setMembers 记录心跳发现的在线节点，供一致性哈希分配任务
+func (dtm *DistributedTaskManager) setMembers(alive map[string]bool) {
+	members := make([]string, 0, len(alive))
+	for node := range alive {
+		members = append(members, node)
+	}
+	sort.Strings(members)
+	dtm.members.Store(&members)
+}
+
+// assignedNode 按最高随机权重哈希（rendezvous hashing）把任务分配给一个在线节点
+// 节点加入或离开时只有分配给该节点的任务会改变归属；还没有节点列表时返回空
+func (dtm *DistributedTaskManager) assignedNode(taskName string) string {
+	members := dtm.members.Load()
+	if members == nil {
+		return ""
+	}
+
+	var owner string
+	var best uint64
+	for _, node := range *members {
+		h := fnv.New64a()
+		h.Write([]byte(node))
+		h.Write([]byte{0})
+		h.Write([]byte(taskName))
+		if sum := mix64(h.Sum64()); owner == "" || sum > best {
+			owner, best = node, sum
+		}
+	}
+	return owner
+}
+
+// mix64 打散 FNV 哈希的各位，FNV 对短字符串的高位分布不均匀，直接比较大小会把任务集中到同一节点
+func mix64(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// AssignedNode 在 SchedulingHash 模式下返回当前节点所见的、负责执行该任务的节点，其他模式或尚未发现节点时返回空
+func (dtm *DistributedTaskManager) AssignedNode(taskName string) string {
+	if dtm.cfg.SchedulingMode != SchedulingHash {
+		return ""
+	}
+	return dtm.assignedNode(taskName)
+}
+
+// assignedToSelf 任务是否分配给当前节点，节点列表为空或不包含当前节点时视为分配给自己，由锁保证不重复执行
+func (dtm *DistributedTaskManager) assignedToSelf(taskName string) (string, bool) {
+	owner := dtm.assignedNode(taskName)
+	if owner == "" || owner == dtm.nodeID {
+		return owner, true
+	}
+	members := dtm.members.Load()
+	for _, node := range *members {
+		if node == dtm.nodeID {
+			return owner, false
+		}
+	}
+	return owner, true
+}
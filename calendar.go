@@ -0,0 +1,167 @@
+package redCorn
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// maxCalendarSkips 按日历查找下一个调度时刻时最多跳过的天数，避免日历中没有工作日时无限循环
+const maxCalendarSkips = 3660
+
+// dateLayout 日历中的日期格式
+const dateLayout = "2006-01-02"
+
+// Calendar 工作日历，判断调度时刻所在的日期是否执行任务
+type Calendar interface {
+	IsBusinessDay(t time.Time) bool
+}
+
+// HolidayCalendar 节假日日历：周六、周日和节假日不是工作日，调休上班的周末是工作日
+// 按调度时刻所在时区的日期判断，设置 Location 后按该时区判断；可以在运行中修改，之后的调度按新的日历计算
+type HolidayCalendar struct {
+	Location *time.Location
+
+	mu       sync.RWMutex
+	holidays map[string]string // 日期 -> 节假日名称
+	workdays map[string]bool   // 调休上班的日期
+}
+
+// NewHolidayCalendar 创建节假日日历，holidays 为节假日日期
+func NewHolidayCalendar(holidays ...time.Time) *HolidayCalendar {
+	c := &HolidayCalendar{holidays: make(map[string]string), workdays: make(map[string]bool)}
+	for _, day := range holidays {
+		c.AddHoliday(day, "")
+	}
+	return c
+}
+
+// LoadHolidayCalendar 从文件加载节假日日历，格式见 ParseHolidayCalendar
+func LoadHolidayCalendar(path string) (*HolidayCalendar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open holiday calendar: %v", err)
+	}
+	defer f.Close()
+	return ParseHolidayCalendar(f)
+}
+
+// ParseHolidayCalendar 解析节假日列表，每行一个日期，日期后可以跟节假日名称，以 + 开头的日期为调休上班的工作日，# 开头的行为注释
+//
+//	# 2024 国庆节
+//	2024-10-01 国庆节
+//	2024-10-02 国庆节
+//	+2024-10-12
+func ParseHolidayCalendar(r io.Reader) (*HolidayCalendar, error) {
+	c := NewHolidayCalendar()
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		date, name := text, ""
+		if i := strings.IndexAny(text, " \t"); i >= 0 {
+			date, name = text[:i], strings.TrimSpace(text[i:])
+		}
+		workday := strings.HasPrefix(date, "+")
+		day, err := time.Parse(dateLayout, strings.TrimPrefix(date, "+"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse holiday calendar: line %d: invalid date %q", line, date)
+		}
+		if workday {
+			c.AddWorkday(day)
+		} else {
+			c.AddHoliday(day, name)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read holiday calendar: %v", err)
+	}
+	return c, nil
+}
+
+// AddHoliday 添加节假日，只使用 day 的年月日
+func (c *HolidayCalendar) AddHoliday(day time.Time, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.holidays[day.Format(dateLayout)] = name
+	delete(c.workdays, day.Format(dateLayout))
+}
+
+// AddWorkday 添加调休上班的工作日，只使用 day 的年月日
+func (c *HolidayCalendar) AddWorkday(day time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.workdays[day.Format(dateLayout)] = true
+	delete(c.holidays, day.Format(dateLayout))
+}
+
+// Holiday 返回 t 所在日期的节假日名称，不是节假日时返回 false
+func (c *HolidayCalendar) Holiday(t time.Time) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	name, ok := c.holidays[c.date(t)]
+	return name, ok
+}
+
+// IsBusinessDay t 所在日期是否为工作日
+func (c *HolidayCalendar) IsBusinessDay(t time.Time) bool {
+	if c.Location != nil {
+		t = t.In(c.Location)
+	}
+	date := t.Format(dateLayout)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, ok := c.holidays[date]; ok {
+		return false
+	}
+	if c.workdays[date] {
+		return true
+	}
+	return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+}
+
+// date 返回 t 在日历时区的日期
+func (c *HolidayCalendar) date(t time.Time) string {
+	if c.Location != nil {
+		t = t.In(c.Location)
+	}
+	return t.Format(dateLayout)
+}
+
+// WithCalendar 只在日历中的工作日调度任务，非工作日的调度时刻被跳过，例如 "0 0 8 * * *" 配合节假日日历即为每个工作日 8 点
+// 手动触发不受日历限制
+func WithCalendar(cal Calendar) TaskOption {
+	return func(o *taskOptions) {
+		o.calendar = cal
+	}
+}
+
+// calendarSchedule 跳过非工作日的调度
+type calendarSchedule struct {
+	cron.Schedule
+	calendar Calendar
+}
+
+// Next 返回 t 之后第一个在工作日的调度时刻，调度时刻不在工作日时从第二天零点继续查找
+func (s calendarSchedule) Next(t time.Time) time.Time {
+	next := s.Schedule.Next(t)
+	for i := 0; !next.IsZero() && !s.calendar.IsBusinessDay(next); i++ {
+		if i >= maxCalendarSkips {
+			return time.Time{}
+		}
+		y, m, d := next.Date()
+		tomorrow := time.Date(y, m, d+1, 0, 0, 0, 0, next.Location())
+		next = s.Schedule.Next(tomorrow.Add(-time.Second))
+	}
+	return next
+}
@@ -0,0 +1,126 @@
+package redCorn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ManualSpec 只在触发时执行的任务的表达式：不参与定时调度，由手动触发或上游任务执行成功后触发
+const ManualSpec = "@manual"
+
+// chainClaimTTL 链式触发认领记录的保留时间，覆盖各节点收到触发指令的时间差
+const chainClaimTTL = 10 * time.Minute
+
+// manualSchedule 从不触发的调度
+type manualSchedule struct{}
+
+// Next 始终返回零值
+func (manualSchedule) Next(time.Time) time.Time {
+	return time.Time{}
+}
+
+// manualParser 在 parser 的基础上识别 ManualSpec
+type manualParser struct {
+	cron.ScheduleParser
+}
+
+// Parse 解析表达式，ManualSpec 返回从不触发的调度
+func (p manualParser) Parse(spec string) (cron.Schedule, error) {
+	if strings.TrimSpace(spec) == ManualSpec {
+		return manualSchedule{}, nil
+	}
+	return p.ScheduleParser.Parse(spec)
+}
+
+// WithThen 任务执行成功后在集群中触发 next 中的任务各执行一次，用于替代按时间差错开的表达式
+// 下游任务可以在任意节点上注册，通常使用 ManualSpec 只由上游触发
+func WithThen(next ...string) TaskOption {
+	return func(o *taskOptions) {
+		o.then = append(o.then, next...)
+	}
+}
+
+// validateThen 检查下游任务，任务不能触发自身
+func (o taskOptions) validateThen(name string) error {
+	for _, next := range o.then {
+		if next == "" {
+			return errors.New("downstream task name is required")
+		}
+		if next == name {
+			return errors.New("task cannot trigger itself")
+		}
+	}
+	return nil
+}
+
+// triggerTasks 上游任务执行成功后通过控制频道广播链式触发指令，由集群中的某一个节点认领并执行下游任务
+func (dtm *DistributedTaskManager) triggerTasks(t *distributedTask, runID string, tasks ...string) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	for _, next := range tasks {
+		msg := controlMessage{Action: ControlChain, Task: next, Upstream: t.name, RunID: runID}
+		if err := dtm.controller.publishMessage(ctx, msg); err != nil {
+			dtm.logRun(slog.LevelError, t.name, runID, "Failed to trigger downstream task", slog.String("next", next), slog.Any("error", err))
+			continue
+		}
+		dtm.logRun(slog.LevelInfo, t.name, runID, "Triggered downstream task", slog.String("next", next))
+	}
+}
+
+// runChained 收到链式触发指令后认领并执行下游任务，同一次上游执行在集群中只有一个节点认领成功
+// 链式触发不是手动触发，暂停的下游任务跳过本次执行
+func (dtm *DistributedTaskManager) runChained(name, upstream, upstreamRunID string) error {
+	dtm.mu.RLock()
+	t, exists := dtm.tasks[name]
+	dtm.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+	if !dtm.chainCandidate(name) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+	claimed, err := dtm.redisClient.SetNX(ctx, dtm.key("chain", name, upstreamRunID), dtm.nodeID, chainClaimTTL).Result()
+	if err != nil {
+		return fmt.Errorf("failed to claim chained execution: %v", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	dtm.logRun(slog.LevelInfo, name, "", "Triggered by upstream task", slog.String("upstream", upstream), slog.String("upstream_run_id", upstreamRunID))
+	go dtm.dispatch(t, false, time.Time{})
+	return nil
+}
+
+// chainCandidate 当前节点能否认领链式触发：工作节点不认领，由调度节点加入工作队列；
+// 领导者模式下只由领导者认领，一致性哈希模式下只由分配到任务的节点认领
+func (dtm *DistributedTaskManager) chainCandidate(name string) bool {
+	switch {
+	case dtm.isWorker() || dtm.isStopping():
+		return false
+	case dtm.leaderMode():
+		return dtm.IsLeader()
+	case dtm.cfg.SchedulingMode == SchedulingHash:
+		_, ok := dtm.assignedToSelf(name)
+		return ok
+	}
+	return true
+}
+
+// Then 为已注册的任务 name 追加下游任务，见 WithThen，存在循环依赖时返回错误
+//
+//	scheduler.RegisterE("extract", "0 0 2 * * *", extract)
+//	scheduler.RegisterE("transform", redCorn.ManualSpec, transform)
+//	scheduler.Then("extract", "transform")
+func (ts *TaskScheduler) Then(name string, next ...string) error {
+	return ts.appendOption(name, WithThen(next...))
+}
@@ -0,0 +1,73 @@
+package redCorn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SlackNotifier 通过 Slack Incoming Webhook 发送告警
+type SlackNotifier struct {
+	WebhookURL string
+	Channel    string       // 覆盖 Webhook 默认频道，可选
+	Client     *http.Client // 默认 http.DefaultClient
+}
+
+// Notify 向 Slack 频道发送告警消息
+func (s *SlackNotifier) Notify(ctx context.Context, n Notification) error {
+	payload := map[string]string{"text": n.text("*", "`")}
+	if s.Channel != "" {
+		payload["channel"] = s.Channel
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode slack message: %v", err)
+	}
+	return postJSON(ctx, s.Client, s.WebhookURL, nil, body)
+}
+
+// TeamsNotifier 通过 Microsoft Teams Incoming Webhook 发送告警
+type TeamsNotifier struct {
+	WebhookURL string
+	Client     *http.Client // 默认 http.DefaultClient
+}
+
+// Notify 以 MessageCard 格式向 Teams 频道发送告警消息
+func (t *TeamsNotifier) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(map[string]string{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"themeColor": "D93F0B",
+		"summary":    n.title(),
+		"title":      n.title(),
+		"text":       strings.ReplaceAll(n.text("**", "`"), "\n", "\n\n"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode teams message: %v", err)
+	}
+	return postJSON(ctx, t.Client, t.WebhookURL, nil, body)
+}
+
+// title 告警标题
+func (n Notification) title() string {
+	return fmt.Sprintf("redCorn task %s: %s", n.Task, n.Kind)
+}
+
+// text 告警正文，bold 与 code 为目标平台的 Markdown 标记
+func (n Notification) text(bold, code string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%sredCorn task %s %s%s\n", bold, n.Task, n.Kind, bold)
+	fmt.Fprintf(&b, "Node: %s\n", n.Node)
+	if n.RunID != "" {
+		fmt.Fprintf(&b, "Run: %s%s%s\n", code, n.RunID, code)
+	}
+	if n.Duration > 0 {
+		fmt.Fprintf(&b, "Duration: %s\n", n.Duration)
+	}
+	if n.Error != "" {
+		fmt.Fprintf(&b, "Error: %s%s%s\n", code, n.Error, code)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
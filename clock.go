@@ -0,0 +1,332 @@
+package redCorn
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Clock 时钟，用于计算调度时刻、随机延迟和锁的过期时间，方法与 github.com/jonboulle/clockwork 类似，默认使用系统时钟，测试中可以使用 NewFakeClock
+// 等待通过 NewTimer 返回的 Timer 进行，放弃等待时调用 Stop，FakeClock 据此只统计仍在等待的协程
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Until(t time.Time) time.Duration
+	NewTimer(d time.Duration) Timer
+	Sleep(d time.Duration)
+}
+
+// Timer Clock 创建的单次定时器，与 time.Timer 一致：到期时向 Chan 发送当前时间，Stop 返回定时器是否尚未到期
+type Timer interface {
+	Chan() <-chan time.Time
+	Stop() bool
+}
+
+// realClock 系统时钟
+type realClock struct{}
+
+func (realClock) Now() time.Time                  { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration { return time.Since(t) }
+func (realClock) Until(t time.Time) time.Duration { return time.Until(t) }
+func (realClock) NewTimer(d time.Duration) Timer  { return realTimer{time.NewTimer(d)} }
+func (realClock) Sleep(d time.Duration)           { time.Sleep(d) }
+
+// realTimer 系统定时器
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) Chan() <-chan time.Time { return t.C }
+
+// FakeClock 只在调用 Advance 时前进的时钟，用于在单元测试中快进调度而不是等待
+//
+//	clock := redCorn.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+//	cfg.Clock = clock
+//	...
+//	dtm.Start()
+//	clock.BlockUntil(1)          // 等待调度器开始等待下一次调度时刻
+//	clock.Advance(time.Minute)   // 触发这一分钟内到期的调度
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeTimer
+}
+
+// fakeTimer 等待时钟到达 until 的定时器
+type fakeTimer struct {
+	clock *FakeClock
+	until time.Time
+	ch    chan time.Time
+}
+
+// NewFakeClock 创建从 now 开始的 FakeClock
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now 返回当前时间
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Since 返回当前时间与 t 的差
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Until 返回 t 与当前时间的差
+func (c *FakeClock) Until(t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
+
+// NewTimer 创建在时钟前进 d 之后到期的定时器，d 不为正数时立即到期
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, until: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.ch <- c.now
+		return t
+	}
+	c.waiters = append(c.waiters, t)
+	c.cond.Broadcast()
+	return t
+}
+
+// Sleep 阻塞到时钟前进 d
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.NewTimer(d).Chan()
+}
+
+// Advance 时钟前进 d，触发到期的定时器
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	waiters := c.waiters[:0]
+	for _, t := range c.waiters {
+		if t.until.After(c.now) {
+			waiters = append(waiters, t)
+			continue
+		}
+		t.ch <- c.now
+	}
+	clear(c.waiters[len(waiters):])
+	c.waiters = waiters
+	c.cond.Broadcast()
+}
+
+// BlockUntil 阻塞到至少有 n 个尚未到期也未停止的定时器，用于确认调度器等协程已经开始等待再调用 Advance
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+// Chan 返回定时器到期时收到当前时间的 channel
+func (t *fakeTimer) Chan() <-chan time.Time {
+	return t.ch
+}
+
+// Stop 停止定时器，定时器不再计入 BlockUntil；已到期或已停止时返回 false
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, w := range c.waiters {
+		if w == t {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+// cronScheduler 定时调度器，默认为 robfig/cron，配置了 Cfg.Clock 时为按该时钟调度的 clockCron
+type cronScheduler interface {
+	Start()
+	Stop() context.Context
+	Schedule(schedule cron.Schedule, job cron.Job) cron.EntryID
+	Remove(id cron.EntryID)
+	Entries() []cron.Entry
+	Entry(id cron.EntryID) cron.Entry
+}
+
+// clockCron 按 Clock 计算和等待调度时刻的调度器，行为与 robfig/cron 一致：
+// 到期的任务在独立的协程中执行，下一次调度时刻从触发时的当前时间开始计算，错过的调度不补
+type clockCron struct {
+	clock Clock
+
+	mu      sync.Mutex
+	entries map[cron.EntryID]*cron.Entry
+	nextID  cron.EntryID
+	running bool
+	wake    chan struct{} // 条目变化时唤醒调度协程重新计算等待时间
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func newClockCron(clock Clock) *clockCron {
+	return &clockCron{
+		clock:   clock,
+		entries: make(map[cron.EntryID]*cron.Entry),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Start 启动调度协程，已启动时不做任何操作
+func (c *clockCron) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.running {
+		return
+	}
+	c.running = true
+	now := c.clock.Now()
+	for _, e := range c.entries {
+		e.Next = e.Schedule.Next(now)
+	}
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+	go c.run(c.stop, c.done)
+}
+
+// Stop 停止调度协程，不等待正在执行的任务，返回的上下文在调度协程退出后结束
+func (c *clockCron) Stop() context.Context {
+	c.mu.Lock()
+	ctx, cancel := context.WithCancel(context.Background())
+	if !c.running {
+		c.mu.Unlock()
+		cancel()
+		return ctx
+	}
+	c.running = false
+	close(c.stop)
+	done := c.done
+	c.mu.Unlock()
+
+	go func() {
+		<-done
+		cancel()
+	}()
+	return ctx
+}
+
+// Schedule 添加条目，调度器运行中时立即参与调度
+func (c *clockCron) Schedule(schedule cron.Schedule, job cron.Job) cron.EntryID {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextID++
+	e := &cron.Entry{ID: c.nextID, Schedule: schedule, Job: job, WrappedJob: job}
+	if c.running {
+		e.Next = schedule.Next(c.clock.Now())
+	}
+	c.entries[e.ID] = e
+	c.notify()
+	return e.ID
+}
+
+// Remove 移除条目
+func (c *clockCron) Remove(id cron.EntryID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, id)
+	c.notify()
+}
+
+// Entries 返回所有条目的副本，按下一次调度时刻排序
+func (c *clockCron) Entries() []cron.Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := make([]cron.Entry, 0, len(c.entries))
+	for _, e := range c.entries {
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Next.IsZero() != entries[j].Next.IsZero() {
+			return !entries[i].Next.IsZero()
+		}
+		return entries[i].Next.Before(entries[j].Next)
+	})
+	return entries
+}
+
+// Entry 返回条目的副本，不存在时返回零值
+func (c *clockCron) Entry(id cron.EntryID) cron.Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[id]; ok {
+		return *e
+	}
+	return cron.Entry{}
+}
+
+// notify 唤醒调度协程，调用方需持有 c.mu
+func (c *clockCron) notify() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run 等待最早的调度时刻，到期后执行所有到期的条目
+func (c *clockCron) run(stop, done chan struct{}) {
+	defer close(done)
+	for {
+		c.mu.Lock()
+		var next time.Time
+		for _, e := range c.entries {
+			if !e.Next.IsZero() && (next.IsZero() || e.Next.Before(next)) {
+				next = e.Next
+			}
+		}
+		// 每次等待只创建一个定时器，被唤醒或停止时停止它
+		var timer Timer
+		var fire <-chan time.Time
+		if !next.IsZero() {
+			timer = c.clock.NewTimer(c.clock.Until(next))
+			fire = timer.Chan()
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-stop:
+			stopTimer(timer)
+			return
+		case <-c.wake:
+			stopTimer(timer)
+			continue
+		case <-fire:
+		}
+
+		c.mu.Lock()
+		now := c.clock.Now()
+		for _, e := range c.entries {
+			if e.Next.IsZero() || e.Next.After(now) {
+				continue
+			}
+			go e.Job.Run()
+			e.Prev = e.Next
+			e.Next = e.Schedule.Next(now)
+		}
+		c.mu.Unlock()
+	}
+}
+
+// stopTimer 停止可能为 nil 的定时器
+func stopTimer(t Timer) {
+	if t != nil {
+		t.Stop()
+	}
+}
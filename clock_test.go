@@ -0,0 +1,88 @@
+package redCorn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// pendingTimers FakeClock 上仍在等待的定时器数量
+func (c *FakeClock) pendingTimers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+func TestFakeTimerStop(t *testing.T) {
+	clock := NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	timer := clock.NewTimer(time.Minute)
+	if n := clock.pendingTimers(); n != 1 {
+		t.Fatalf("pending timers = %d, want 1", n)
+	}
+	if !timer.Stop() {
+		t.Fatal("Stop of a pending timer returned false")
+	}
+	if n := clock.pendingTimers(); n != 0 {
+		t.Fatalf("pending timers after Stop = %d, want 0", n)
+	}
+	if timer.Stop() {
+		t.Fatal("second Stop returned true")
+	}
+
+	clock.Advance(time.Minute)
+	select {
+	case <-timer.Chan():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+}
+
+func TestFakeTimerFires(t *testing.T) {
+	clock := NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	timer := clock.NewTimer(time.Minute)
+
+	clock.Advance(59 * time.Second)
+	select {
+	case <-timer.Chan():
+		t.Fatal("timer fired early")
+	default:
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case <-timer.Chan():
+	default:
+		t.Fatal("timer did not fire")
+	}
+	if timer.Stop() {
+		t.Fatal("Stop of a fired timer returned true")
+	}
+}
+
+// 调度协程被反复唤醒时停止上一次的定时器，不会累积放弃的等待
+func TestClockCronReusesTimer(t *testing.T) {
+	clock := NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := newClockCron(clock)
+	schedule, err := specParser.Parse("0 * * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Schedule(schedule, cron.FuncJob(func() {}))
+	c.Start()
+	defer c.Stop()
+
+	for i := 0; i < 100; i++ {
+		id := c.Schedule(schedule, cron.FuncJob(func() {}))
+		c.Remove(id)
+	}
+	clock.BlockUntil(1)
+	// 等待调度协程处理完所有唤醒
+	deadline := time.Now().Add(time.Second)
+	for clock.pendingTimers() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := clock.pendingTimers(); n != 1 {
+		t.Fatalf("pending timers = %d, want 1", n)
+	}
+}
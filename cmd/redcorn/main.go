@@ -0,0 +1,425 @@
+// redcorn 命令行工具，通过 Redis 操作 RedCorn 集群：查看任务和锁、触发执行、暂停/恢复任务、查看执行历史
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	goredislib "github.com/go-redis/redis/v8"
+	"github.com/kzdgt/redCorn"
+)
+
+const usage = `Usage: redcorn [flags] <command> [task]
+
+Commands:
+  tasks             列出集群中注册的任务及其运行状态
+  locks             查看任务锁的持有情况
+  running           列出当前被持有的锁（正在执行的任务），包括已下线节点遗留的锁
+  status  <task>    查看任务的最近执行时间和下一次调度时间
+  history <task>    查看任务的执行历史（条数由 -limit 指定）
+  trigger <task>    立即触发一次执行
+  pause   <task>    暂停任务
+  resume  <task>    恢复任务
+  unlock  <task>    强制释放已下线节点遗留的锁，持有锁的节点仍在运行时拒绝
+  nodes             列出集群中的节点及其最近一次心跳
+  audit             查看管理操作的审计记录（条数由 -limit 指定）
+  pause-group  <group>   暂停分组内的所有任务
+  resume-group <group>   恢复分组内的所有任务
+  submit <job> [payload] 提交任务，由注册了同名处理函数的节点执行；payload 为 "-" 时从标准输入读取，-delay 指定延迟执行
+  windows                列出维护窗口
+  window-add <target> <duration> [reason]
+                         添加从现在开始、持续 duration 的维护窗口，target 为任务名、group:<分组> 或 * 表示所有任务
+  window-remove <id>     删除维护窗口
+  dynamic                列出保存在 Redis 中的动态任务
+  dynamic-put <task> <cron> <handler> [payload]
+                         添加或更新动态任务，payload 为 JSON，为 "-" 时从标准输入读取
+  dynamic-remove <task>  删除动态任务
+  cluster                查看集群是否已暂停
+  pause-cluster [reason] 暂停整个集群，所有节点跳过任务执行，直到 resume-cluster
+  resume-cluster         恢复整个集群
+
+Flags:
+`
+
+func main() {
+	addrs := flag.String("addr", "localhost:6379", "Redis 地址，多个地址用逗号分隔")
+	password := flag.String("password", "", "Redis 密码")
+	db := flag.Int("db", 0, "Redis 数据库")
+	namespace := flag.String("namespace", "", "Redis 键命名空间，需与 Cfg.Namespace 一致，默认 redcorn")
+	limit := flag.Int64("limit", 20, "history、audit 命令返回的记录数")
+	timeout := flag.Duration("timeout", 10*time.Second, "命令超时时间")
+	delay := flag.Duration("delay", 0, "submit 命令的延迟时间，为0时加入工作队列立即执行")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	client := goredislib.NewUniversalClient(&goredislib.UniversalOptions{
+		Addrs:    strings.Split(*addrs, ","),
+		Password: *password,
+		DB:       *db,
+	})
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	ctl := redCorn.NewController(client, *namespace)
+	if err := run(ctx, ctl, flag.Args(), *limit, *delay); err != nil {
+		fmt.Fprintln(os.Stderr, "redcorn:", err)
+		os.Exit(1)
+	}
+}
+
+// run 执行命令
+func run(ctx context.Context, ctl *redCorn.Controller, args []string, limit int64, delay time.Duration) error {
+	command := args[0]
+	task := ""
+	switch command {
+	case "tasks", "locks", "running", "nodes", "audit", "windows", "dynamic", "cluster", "pause-cluster", "resume-cluster":
+	case "status", "history", "trigger", "pause", "resume", "unlock":
+		if len(args) < 2 {
+			return fmt.Errorf("%s: task name required", command)
+		}
+		task = args[1]
+	case "pause-group", "resume-group":
+		if len(args) < 2 {
+			return fmt.Errorf("%s: group name required", command)
+		}
+		task = args[1]
+	case "submit":
+		if len(args) < 2 {
+			return fmt.Errorf("%s: job name required", command)
+		}
+		task = args[1]
+	case "window-add":
+		if len(args) < 3 {
+			return fmt.Errorf("%s: target and duration required", command)
+		}
+		task = args[1]
+	case "window-remove":
+		if len(args) < 2 {
+			return fmt.Errorf("%s: window id required", command)
+		}
+		task = args[1]
+	case "dynamic-put":
+		if len(args) < 4 {
+			return fmt.Errorf("%s: task name, cron spec and handler required", command)
+		}
+		task = args[1]
+	case "dynamic-remove":
+		if len(args) < 2 {
+			return fmt.Errorf("%s: task name required", command)
+		}
+		task = args[1]
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	switch command {
+	case "tasks":
+		defs, err := ctl.Tasks(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "TASK\tGROUP\tSPEC\tLAST RUN\tLAST NODE\tLAST STATUS\tNEXT RUN")
+		for _, def := range defs {
+			status, _ := ctl.Status(ctx, def.Name)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", def.Name, orDash(def.Group), def.Spec,
+				formatTime(status.LastRun), orDash(status.LastNode), orDash(string(status.LastStatus)), formatTime(status.NextRun))
+		}
+
+	case "locks":
+		locks, err := ctl.Locks(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "TASK\tKEY\tHOLDER\tTTL")
+		for _, l := range locks {
+			holder, ttl := "-", "-"
+			if l.Locked {
+				holder, ttl = l.Holder, l.TTL.Round(time.Millisecond).String()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", l.Task, l.Key, holder, ttl)
+		}
+
+	case "running":
+		locks, err := ctl.ActiveLocks(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "TASK\tKEY\tNODE\tRUN ID\tTTL")
+		for _, l := range locks {
+			node, runID, ttl := l.Node, l.RunID, "-"
+			if node == "" {
+				node, runID = l.Holder, "-"
+			}
+			if l.TTL > 0 {
+				ttl = l.TTL.Round(time.Millisecond).String()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", l.Task, l.Key, node, runID, ttl)
+		}
+
+	case "status":
+		status, err := ctl.Status(ctx, task)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Task:\t%s\n", status.Task)
+		fmt.Fprintf(w, "Last run:\t%s\n", formatTime(status.LastRun))
+		fmt.Fprintf(w, "Last node:\t%s\n", orDash(status.LastNode))
+		fmt.Fprintf(w, "Last status:\t%s\n", orDash(string(status.LastStatus)))
+		fmt.Fprintf(w, "Last duration:\t%s\n", status.LastDuration)
+		fmt.Fprintf(w, "Next run:\t%s\n", formatTime(status.NextRun))
+
+	case "history":
+		records, err := ctl.History(ctx, task, limit)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "STARTED AT\tNODE\tSTATUS\tDURATION\tDETAIL")
+		for _, rec := range records {
+			detail := rec.Error
+			if rec.SkipReason != "" {
+				detail = rec.SkipReason
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", formatTime(rec.StartedAt), rec.Node, rec.Status, rec.Duration, orDash(detail))
+		}
+
+	case "trigger":
+		if err := ctl.Trigger(ctx, task); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Triggered %s\n", task)
+
+	case "pause":
+		if err := ctl.Pause(ctx, task); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Paused %s\n", task)
+
+	case "resume":
+		if err := ctl.Resume(ctx, task); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Resumed %s\n", task)
+
+	case "unlock":
+		lock, err := ctl.ForceUnlock(ctx, task, operator())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Released lock %s held by %s\n", lock.Key, lock.Holder)
+
+	case "nodes":
+		nodes, err := ctl.Nodes(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "NODE\tLAST SEEN\tALIVE")
+		for _, n := range nodes {
+			fmt.Fprintf(w, "%s\t%s\t%t\n", n.Node, formatTime(n.LastSeen), n.Alive)
+		}
+
+	case "audit":
+		entries, err := ctl.AuditLog(ctx, limit)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "TIME\tACTION\tTASK\tHOLDER\tREASON\tOPERATOR")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", formatTime(e.Time), e.Action, orDash(e.Task), orDash(e.Holder), orDash(e.Reason), e.Operator)
+		}
+
+	case "pause-group":
+		if err := ctl.PauseGroup(ctx, task); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Paused group %s\n", task)
+
+	case "resume-group":
+		if err := ctl.ResumeGroup(ctx, task); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Resumed group %s\n", task)
+
+	case "submit":
+		var payload []byte
+		if len(args) > 2 {
+			payload = []byte(args[2])
+			if args[2] == "-" {
+				data, err := io.ReadAll(os.Stdin)
+				if err != nil {
+					return fmt.Errorf("failed to read payload: %v", err)
+				}
+				payload = data
+			}
+		}
+		job := redCorn.Job{Name: task, Payload: payload, EnqueuedBy: operator()}
+		var err error
+		if delay > 0 {
+			job.DueAt = time.Now().Add(delay)
+			job, err = ctl.EnqueueJob(ctx, job)
+		} else {
+			job, err = ctl.PushJob(ctx, job)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Submitted %s %s, due at %s\n", job.Name, job.ID, formatTime(job.DueAt))
+
+	case "windows":
+		windows, err := ctl.MaintenanceWindows(ctx)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		fmt.Fprintln(w, "ID\tTARGET\tSCHEDULE\tACTIVE\tREASON\tCREATED BY")
+		for _, mw := range windows {
+			target := "*"
+			switch {
+			case mw.Task != "":
+				target = mw.Task
+			case mw.Group != "":
+				target = "group:" + mw.Group
+			}
+			schedule := formatTime(mw.Start) + " ~ " + formatTime(mw.End)
+			if mw.Spec != "" {
+				schedule = mw.Spec + " for " + mw.Duration.String()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\t%s\n", mw.ID, target, schedule, mw.Active(now), orDash(mw.Reason), orDash(mw.CreatedBy))
+		}
+
+	case "window-add":
+		d, err := time.ParseDuration(args[2])
+		if err != nil {
+			return fmt.Errorf("%s: invalid duration: %v", command, err)
+		}
+		mw := redCorn.MaintenanceWindow{Start: time.Now(), CreatedBy: operator()}
+		mw.End = mw.Start.Add(d)
+		switch {
+		case task == "*":
+		case strings.HasPrefix(task, "group:"):
+			mw.Group = strings.TrimPrefix(task, "group:")
+		default:
+			mw.Task = task
+		}
+		if len(args) > 3 {
+			mw.Reason = strings.Join(args[3:], " ")
+		}
+		mw, err = ctl.AddMaintenanceWindow(ctx, mw)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Added maintenance window %s until %s\n", mw.ID, formatTime(mw.End))
+
+	case "window-remove":
+		if err := ctl.RemoveMaintenanceWindow(ctx, task); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Removed maintenance window %s\n", task)
+
+	case "dynamic":
+		tasks, err := ctl.DynamicTasks(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "TASK\tGROUP\tSPEC\tHANDLER\tPAYLOAD")
+		for _, dt := range tasks {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", dt.Name, orDash(dt.Group), dt.Cron, dt.Handler, orDash(string(dt.Payload)))
+		}
+
+	case "dynamic-put":
+		dt := redCorn.DynamicTask{Name: task, Cron: args[2], Handler: args[3]}
+		if len(args) > 4 {
+			dt.Payload = json.RawMessage(args[4])
+			if args[4] == "-" {
+				data, err := io.ReadAll(os.Stdin)
+				if err != nil {
+					return fmt.Errorf("failed to read payload: %v", err)
+				}
+				dt.Payload = json.RawMessage(data)
+			}
+		}
+		if err := ctl.PutDynamicTask(ctx, dt); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Saved dynamic task %s\n", task)
+
+	case "dynamic-remove":
+		if err := ctl.RemoveDynamicTask(ctx, task); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Removed dynamic task %s\n", task)
+
+	case "cluster":
+		pause, err := ctl.ClusterPaused(ctx)
+		if err != nil {
+			return err
+		}
+		if pause == nil {
+			fmt.Fprintln(w, "Cluster is running")
+			break
+		}
+		fmt.Fprintf(w, "Paused:\t%s\n", formatTime(pause.PausedAt))
+		fmt.Fprintf(w, "Paused by:\t%s\n", pause.PausedBy)
+		fmt.Fprintf(w, "Reason:\t%s\n", orDash(pause.Reason))
+
+	case "pause-cluster":
+		reason := strings.Join(args[1:], " ")
+		if err := ctl.PauseCluster(ctx, reason, operator()); err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "Paused cluster, all executions are skipped until resume-cluster")
+
+	case "resume-cluster":
+		if err := ctl.ResumeCluster(ctx, operator()); err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "Resumed cluster")
+	}
+	return nil
+}
+
+// operator 审计记录中的操作者：用户名@主机名
+func operator() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	hostname, _ := os.Hostname()
+	return name + "@" + hostname
+}
+
+// formatTime 格式化时间，零值显示为 "-"
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// orDash 空字符串显示为 "-"
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
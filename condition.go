@@ -0,0 +1,41 @@
+package redCorn
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+)
+
+// SkipReasonCondition 执行条件不满足时的跳过原因
+const SkipReasonCondition = "condition not met"
+
+// WithCondition 获取锁后先调用 cond 判断本次是否需要执行，返回 false 时以 SkipReasonCondition 跳过
+// 适合功能开关关闭、没有待处理数据等情况下低成本地跳过；cond 应尽快返回，手动触发不检查条件
+func WithCondition(cond func(ctx context.Context) bool) TaskOption {
+	return func(o *taskOptions) {
+		o.condition = cond
+	}
+}
+
+// checkCondition 调用任务的执行条件，不满足或发生 panic 时在执行记录中写入跳过原因
+func (dtm *DistributedTaskManager) checkCondition(ctx context.Context, t *distributedTask, rec *ExecutionRecord) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			pe := &PanicError{Value: r, Stack: debug.Stack()}
+			rec.Status = ExecutionSkipped
+			rec.SkipReason = SkipReasonCondition
+			rec.setError(fmt.Errorf("condition panicked: %v", r))
+			dtm.logRun(slog.LevelError, t.name, rec.RunID, "Condition panicked, skipping execution", slog.Any("error", pe), slog.String("stack", string(pe.Stack)))
+			ok = false
+		}
+	}()
+
+	if !t.opts.condition(ctx) {
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonCondition
+		dtm.logRun(slog.LevelInfo, t.name, rec.RunID, "Condition not met, skipping execution")
+		return false
+	}
+	return true
+}
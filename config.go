@@ -0,0 +1,223 @@
+package redCorn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFormat 调度配置文件的格式
+type ConfigFormat string
+
+const (
+	ConfigYAML ConfigFormat = "yaml"
+	ConfigJSON ConfigFormat = "json"
+	ConfigTOML ConfigFormat = "toml"
+)
+
+// ScheduleConfig 调度配置文件，任务名到调度配置的映射，任务函数在代码中按名称绑定
+//
+//	tasks:
+//	  data-sync:
+//	    cron: "0 */5 * * * *"
+//	    group: sync
+//	    timeout: 2m
+//	  daily-report:
+//	    cron: "0 0 8 * * *"
+//	    handler: report   # 默认使用与任务同名的任务函数
+//	    timezone: Asia/Shanghai
+type ScheduleConfig struct {
+	Tasks map[string]TaskConfig `json:"tasks" yaml:"tasks" toml:"tasks"`
+}
+
+// TaskConfig 配置文件中单个任务的调度配置，时长使用 time.ParseDuration 的格式，如 "30s"、"5m"
+type TaskConfig struct {
+	Cron       string   `json:"cron" yaml:"cron" toml:"cron"`
+	Handler    string   `json:"handler,omitempty" yaml:"handler,omitempty" toml:"handler,omitempty"` // 任务函数名称，默认为任务名
+	Group      string   `json:"group,omitempty" yaml:"group,omitempty" toml:"group,omitempty"`
+	Timezone   string   `json:"timezone,omitempty" yaml:"timezone,omitempty" toml:"timezone,omitempty"`
+	CronFormat string   `json:"cron_format,omitempty" yaml:"cron_format,omitempty" toml:"cron_format,omitempty"`
+	Timeout    string   `json:"timeout,omitempty" yaml:"timeout,omitempty" toml:"timeout,omitempty"`
+	Jitter     string   `json:"jitter,omitempty" yaml:"jitter,omitempty" toml:"jitter,omitempty"`
+	Misfire    string   `json:"misfire,omitempty" yaml:"misfire,omitempty" toml:"misfire,omitempty"`
+	RunOnStart bool     `json:"run_on_start,omitempty" yaml:"run_on_start,omitempty" toml:"run_on_start,omitempty"`
+	Then       []string `json:"then,omitempty" yaml:"then,omitempty" toml:"then,omitempty"`
+	DependsOn  []string `json:"depends_on,omitempty" yaml:"depends_on,omitempty" toml:"depends_on,omitempty"`
+	Disabled   bool     `json:"disabled,omitempty" yaml:"disabled,omitempty" toml:"disabled,omitempty"` // 不注册该任务
+}
+
+// configFormatOf 按扩展名判断配置文件格式
+func configFormatOf(path string) (ConfigFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return ConfigYAML, nil
+	case ".json":
+		return ConfigJSON, nil
+	case ".toml":
+		return ConfigTOML, nil
+	default:
+		return "", fmt.Errorf("unknown config format of %s: extension must be .yaml, .yml, .json or .toml", path)
+	}
+}
+
+// LoadScheduleConfig 读取调度配置文件，按扩展名（.yaml、.yml、.json、.toml）解析
+func LoadScheduleConfig(path string) (ScheduleConfig, error) {
+	format, err := configFormatOf(path)
+	if err != nil {
+		return ScheduleConfig{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ScheduleConfig{}, fmt.Errorf("failed to read schedule config: %v", err)
+	}
+	return ParseScheduleConfig(data, format)
+}
+
+// ParseScheduleConfig 解析调度配置，未知的字段视为错误，避免拼写错误的配置被静默忽略
+func ParseScheduleConfig(data []byte, format ConfigFormat) (ScheduleConfig, error) {
+	var cfg ScheduleConfig
+	switch format {
+	case ConfigYAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+			return ScheduleConfig{}, fmt.Errorf("failed to parse schedule config: %v", err)
+		}
+	case ConfigJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&cfg); err != nil {
+			return ScheduleConfig{}, fmt.Errorf("failed to parse schedule config: %v", err)
+		}
+	case ConfigTOML:
+		md, err := toml.Decode(string(data), &cfg)
+		if err != nil {
+			return ScheduleConfig{}, fmt.Errorf("failed to parse schedule config: %v", err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return ScheduleConfig{}, fmt.Errorf("failed to parse schedule config: unknown field %s", undecoded[0])
+		}
+	default:
+		return ScheduleConfig{}, fmt.Errorf("unknown config format %q", format)
+	}
+	return cfg, nil
+}
+
+// LoadSchedulerFromFile 从配置文件创建任务调度器，配置文件中的任务按名称绑定 handlers 中的任务函数
+// 使调度成为可以评审的配置而不是代码；任务引用了不存在的任务函数或配置无效时返回错误
+//
+//	scheduler, err := redCorn.LoadSchedulerFromFile("tasks.yaml", map[string]func(ctx context.Context) error{
+//		"data-sync": syncData,
+//		"report":    buildReport,
+//	})
+func LoadSchedulerFromFile(path string, handlers map[string]func(ctx context.Context) error) (*TaskScheduler, error) {
+	cfg, err := LoadScheduleConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Scheduler(handlers)
+}
+
+// Scheduler 按配置创建任务调度器，任务函数按 TaskConfig.Handler（默认为任务名）从 handlers 中查找
+func (c ScheduleConfig) Scheduler(handlers map[string]func(ctx context.Context) error) (*TaskScheduler, error) {
+	// 按名称顺序注册，错误信息稳定
+	names := make([]string, 0, len(c.Tasks))
+	for name := range c.Tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ts := NewTaskScheduler()
+	for _, name := range names {
+		tc := c.Tasks[name]
+		if tc.Disabled {
+			continue
+		}
+		handlerName := tc.Handler
+		if handlerName == "" {
+			handlerName = name
+		}
+		handler, ok := handlers[handlerName]
+		if !ok {
+			return nil, fmt.Errorf("failed to load task %s: handler %s is not bound", name, handlerName)
+		}
+		opts, err := tc.options()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load task %s: %v", name, err)
+		}
+		if err := ts.register(name, TaskSchedule{TaskE: handler, Cron: tc.Cron, Options: opts}); err != nil {
+			return nil, err
+		}
+	}
+	return ts, nil
+}
+
+// options 把调度配置转换为任务选项
+func (tc TaskConfig) options() ([]TaskOption, error) {
+	if tc.Cron == "" {
+		return nil, errors.New("cron is required")
+	}
+
+	var opts []TaskOption
+	if tc.Group != "" {
+		opts = append(opts, WithGroup(tc.Group))
+	}
+	if tc.Timezone != "" {
+		loc, err := time.LoadLocation(tc.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %v", tc.Timezone, err)
+		}
+		opts = append(opts, WithTimezone(loc))
+	}
+	if tc.CronFormat != "" {
+		format := CronFormat(tc.CronFormat)
+		if _, err := format.parser(); err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithCronFormat(format))
+	}
+	if tc.Timeout != "" {
+		d, err := time.ParseDuration(tc.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout %q: %v", tc.Timeout, err)
+		}
+		opts = append(opts, WithTimeout(d))
+	}
+	if tc.Jitter != "" {
+		d, err := time.ParseDuration(tc.Jitter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jitter %q: %v", tc.Jitter, err)
+		}
+		opts = append(opts, WithJitter(d))
+	}
+	if tc.Misfire != "" {
+		policy := MisfirePolicy(tc.Misfire)
+		switch policy {
+		case MisfireIgnore, MisfireRunOnce, MisfireRunAll:
+		default:
+			return nil, fmt.Errorf("invalid misfire policy %q", tc.Misfire)
+		}
+		opts = append(opts, WithMisfirePolicy(policy))
+	}
+	if tc.RunOnStart {
+		opts = append(opts, WithRunOnStart())
+	}
+	if len(tc.Then) > 0 {
+		opts = append(opts, WithThen(tc.Then...))
+	}
+	if len(tc.DependsOn) > 0 {
+		opts = append(opts, WithDependsOn(tc.DependsOn...))
+	}
+	return opts, nil
+}
@@ -0,0 +1,127 @@
+package redCorn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// registerTask 将任务定义写入 Redis 中的任务注册表，供命令行工具等外部程序查询
+func (dtm *DistributedTaskManager) registerTask(t *distributedTask) {
+	data, err := json.Marshal(TaskDefinition{
+		Name:    t.name,
+		Group:   t.opts.group,
+		Spec:    t.spec,
+		LockKey: dtm.lockKey(t),
+		Node:    dtm.nodeID,
+	})
+	if err != nil {
+		dtm.logRun(slog.LevelError, t.name, "", "Failed to encode task definition", slog.Any("error", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	if err := dtm.redisClient.HSet(ctx, dtm.key("tasks"), t.name, data).Err(); err != nil {
+		dtm.logRun(slog.LevelError, t.name, "", "Failed to register task definition", slog.Any("error", err))
+	}
+}
+
+// unregisterTask 从 Redis 中的任务注册表移除任务定义
+func (dtm *DistributedTaskManager) unregisterTask(t *distributedTask) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	if err := dtm.redisClient.HDel(ctx, dtm.key("tasks"), t.name).Err(); err != nil {
+		dtm.logRun(slog.LevelError, t.name, "", "Failed to unregister task definition", slog.Any("error", err))
+	}
+}
+
+// startControlListener 订阅控制频道，执行命令行工具等外部程序广播的控制指令
+func (dtm *DistributedTaskManager) startControlListener() {
+	pubsub := dtm.redisClient.Subscribe(dtm.ctx, dtm.key("control"))
+	dtm.controlSub = pubsub
+
+	go func() {
+		for msg := range pubsub.Channel() {
+			var cmd controlMessage
+			if err := json.Unmarshal([]byte(msg.Payload), &cmd); err != nil {
+				dtm.log.Warn("Ignoring malformed control message: ", msg.Payload)
+				continue
+			}
+			dtm.handleControl(cmd)
+		}
+	}()
+}
+
+// stopControlListener 取消订阅控制频道
+func (dtm *DistributedTaskManager) stopControlListener() {
+	if dtm.controlSub == nil {
+		return
+	}
+	if err := dtm.controlSub.Close(); err != nil {
+		dtm.log.Error("Error closing control subscription: ", err)
+	}
+}
+
+// handleControl 执行控制指令，未在当前节点注册的任务直接忽略
+func (dtm *DistributedTaskManager) handleControl(cmd controlMessage) {
+	var err error
+	switch cmd.Action {
+	case ControlTrigger:
+		err = dtm.TriggerTask(cmd.Task)
+	case ControlPause:
+		err = dtm.PauseTask(cmd.Task)
+	case ControlResume:
+		err = dtm.ResumeTask(cmd.Task)
+	case ControlPauseGroup:
+		dtm.PauseGroup(cmd.Group)
+	case ControlResumeGroup:
+		dtm.ResumeGroup(cmd.Group)
+	case ControlChain:
+		err = dtm.runChained(cmd.Task, cmd.Upstream, cmd.RunID)
+	case ControlSyncDynamic:
+		dtm.requestDynamicSync()
+	default:
+		dtm.log.Warn("Ignoring unknown control action: ", cmd.Action)
+		return
+	}
+
+	if err != nil && !errors.Is(err, ErrTaskNotFound) {
+		dtm.logRun(slog.LevelError, cmd.Task, "", "Failed to apply control action", slog.String("action", cmd.Action), slog.Any("error", err))
+	}
+}
+
+// BroadcastPause 在当前节点暂停任务，并通过控制频道通知集群中所有节点暂停，各节点收到指令后立即生效
+// 当前节点未注册该任务时只要集群的任务注册表中存在仍然广播；都不存在时返回 ErrTaskNotFound
+func (dtm *DistributedTaskManager) BroadcastPause(name string) error {
+	return dtm.broadcastPaused(name, true)
+}
+
+// BroadcastResume 在当前节点恢复任务，并通过控制频道通知集群中所有节点恢复，见 BroadcastPause
+func (dtm *DistributedTaskManager) BroadcastResume(name string) error {
+	return dtm.broadcastPaused(name, false)
+}
+
+// broadcastPaused 设置当前节点上任务的暂停状态并广播到集群
+// 指令通过 Pub/Sub 投递，之后启动的节点不会收到
+func (dtm *DistributedTaskManager) broadcastPaused(name string, paused bool) error {
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+
+	if err := dtm.setPaused(name, paused); err != nil {
+		registered, err := dtm.redisClient.HExists(ctx, dtm.key("tasks"), name).Result()
+		if err != nil {
+			return fmt.Errorf("failed to look up task %s: %v", name, err)
+		}
+		if !registered {
+			return fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+		}
+	}
+
+	if paused {
+		return dtm.controller.Pause(ctx, name)
+	}
+	return dtm.controller.Resume(ctx, name)
+}
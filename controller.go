@@ -0,0 +1,338 @@
+package redCorn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	goredislib "github.com/go-redis/redis/v8"
+)
+
+// 控制指令
+const (
+	ControlTrigger = "trigger" // 立即触发一次执行
+	ControlPause   = "pause"   // 暂停任务
+	ControlResume  = "resume"  // 恢复任务
+
+	ControlPauseGroup  = "pause_group"  // 暂停分组内的所有任务
+	ControlResumeGroup = "resume_group" // 恢复分组内的所有任务
+
+	ControlChain = "chain" // 上游任务执行成功后触发下游任务
+
+	ControlSyncDynamic = "sync_dynamic" // 动态任务定义已变更，立即同步
+)
+
+// TaskDefinition 注册到 Redis 中的任务定义
+type TaskDefinition struct {
+	Name    string `json:"name"`
+	Group   string `json:"group,omitempty"`
+	Spec    string `json:"spec"`
+	LockKey string `json:"lock_key"`
+	Node    string `json:"node"` // 最近一次注册该任务的节点
+}
+
+// LockInfo 任务锁的持有情况
+type LockInfo struct {
+	Task   string        `json:"task"`
+	Key    string        `json:"key"`
+	Locked bool          `json:"locked"`
+	Holder string        `json:"holder,omitempty"` // 锁的值
+	Node   string        `json:"node,omitempty"`   // 持有锁的节点，从锁的值中解析
+	RunID  string        `json:"run_id,omitempty"` // 持有锁的执行标识，从锁的值中解析
+	TTL    time.Duration `json:"ttl,omitempty"`
+}
+
+// parseHolders 从锁的值中解析持有锁的节点和执行标识
+func parseHolders(locks []LockInfo) {
+	for i := range locks {
+		if locks[i].Locked {
+			locks[i].Node, locks[i].RunID = splitLockValue(locks[i].Holder)
+		}
+	}
+}
+
+// scanCount 扫描锁的键时每批返回的数量提示
+const scanCount = 100
+
+// globEscaper 转义 SCAN 匹配模式中的特殊字符
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// controlMessage 通过 Redis Pub/Sub 广播的控制指令
+type controlMessage struct {
+	Action string `json:"action"`
+	Task   string `json:"task,omitempty"`
+	Group  string `json:"group,omitempty"`
+
+	Upstream string `json:"upstream,omitempty"` // 链式触发的上游任务
+	RunID    string `json:"run_id,omitempty"`   // 链式触发的上游执行标识
+}
+
+// Controller 集群控制器，只通过 Redis 与集群交互，无需启动任务管理器，供命令行工具等外部程序使用
+type Controller struct {
+	client    goredislib.UniversalClient
+	namespace string
+	clock     Clock // 写入记录的时间，独立使用时为实际时间，任务管理器内使用 Cfg.Clock
+}
+
+// NewController 创建集群控制器，namespace 需与任务管理器的 Cfg.Namespace 一致，为空时使用默认值
+func NewController(client goredislib.UniversalClient, namespace string) *Controller {
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	return &Controller{client: client, namespace: namespace, clock: realClock{}}
+}
+
+// namespacedKey 生成命名空间下的 Redis 键
+func namespacedKey(namespace string, parts ...string) string {
+	return namespace + ":" + strings.Join(parts, ":")
+}
+
+// Tasks 列出集群中注册过的所有任务，按名称排序
+func (c *Controller) Tasks(ctx context.Context) ([]TaskDefinition, error) {
+	items, err := c.client.HGetAll(ctx, namespacedKey(c.namespace, "tasks")).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %v", err)
+	}
+
+	defs := make([]TaskDefinition, 0, len(items))
+	for _, item := range items {
+		var def TaskDefinition
+		if err := json.Unmarshal([]byte(item), &def); err != nil {
+			return nil, fmt.Errorf("failed to decode task definition: %v", err)
+		}
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs, nil
+}
+
+// Locks 查询集群中所有任务锁的持有情况
+func (c *Controller) Locks(ctx context.Context) ([]LockInfo, error) {
+	defs, err := c.Tasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	locks := make([]LockInfo, 0, len(defs))
+	for _, def := range defs {
+		locks = append(locks, LockInfo{Task: def.Name, Key: def.LockKey})
+	}
+	if err := inspectLocks(ctx, c.client, locks); err != nil {
+		return nil, err
+	}
+	parseHolders(locks)
+	return locks, nil
+}
+
+// ActiveLocks 列出集群中当前被持有的任务锁，即正在执行的任务，按键排序
+// 按已注册任务的锁前缀扫描 Redis，因此也能看到已下线节点遗留的锁
+func (c *Controller) ActiveLocks(ctx context.Context) ([]LockInfo, error) {
+	defs, err := c.Tasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 锁的键到任务名的映射
+	keys := make(map[string]string, len(defs))
+	prefixes := make(map[string]bool)
+	for _, def := range defs {
+		keys[def.LockKey] = def.Name
+		prefixes[strings.TrimSuffix(def.LockKey, def.Name)] = true
+	}
+
+	// 前缀为空时无法区分锁和其他键，只查询已注册任务的锁
+	for prefix := range prefixes {
+		if prefix == "" {
+			continue
+		}
+		scanned, err := scanKeys(ctx, c.client, prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range scanned {
+			if _, ok := keys[key]; !ok {
+				keys[key] = strings.TrimPrefix(key, prefix)
+			}
+		}
+	}
+
+	locks := make([]LockInfo, 0, len(keys))
+	for key, task := range keys {
+		locks = append(locks, LockInfo{Task: task, Key: key})
+	}
+	if err := inspectLocks(ctx, c.client, locks); err != nil {
+		return nil, err
+	}
+	return heldLocks(locks), nil
+}
+
+// heldLocks 筛选出被持有的锁，解析持有者并按键排序
+func heldLocks(locks []LockInfo) []LockInfo {
+	held := make([]LockInfo, 0, len(locks))
+	for _, lock := range locks {
+		if lock.Locked {
+			held = append(held, lock)
+		}
+	}
+	parseHolders(held)
+	sort.Slice(held, func(i, j int) bool { return held[i].Key < held[j].Key })
+	return held
+}
+
+// scanKeys 扫描以 prefix 开头的键，集群模式下逐个扫描主节点
+func scanKeys(ctx context.Context, client goredislib.UniversalClient, prefix string) ([]string, error) {
+	pattern := globEscaper.Replace(prefix) + "*"
+	var mu sync.Mutex
+	var keys []string
+	scan := func(ctx context.Context, c goredislib.Cmdable) error {
+		iter := c.Scan(ctx, 0, pattern, scanCount).Iterator()
+		for iter.Next(ctx) {
+			mu.Lock()
+			keys = append(keys, iter.Val())
+			mu.Unlock()
+		}
+		return iter.Err()
+	}
+
+	var err error
+	if cluster, ok := client.(*goredislib.ClusterClient); ok {
+		err = cluster.ForEachMaster(ctx, func(ctx context.Context, c *goredislib.Client) error {
+			return scan(ctx, c)
+		})
+	} else {
+		err = scan(ctx, client)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan locks: %v", err)
+	}
+	return keys, nil
+}
+
+// inspectLocks 根据 Key 批量查询锁的持有者和剩余时间
+func inspectLocks(ctx context.Context, client goredislib.UniversalClient, locks []LockInfo) error {
+	pipe := client.Pipeline()
+	holders := make([]*goredislib.StringCmd, len(locks))
+	ttls := make([]*goredislib.DurationCmd, len(locks))
+	for i, l := range locks {
+		holders[i] = pipe.Get(ctx, l.Key)
+		ttls[i] = pipe.PTTL(ctx, l.Key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != goredislib.Nil {
+		return fmt.Errorf("failed to inspect locks: %v", err)
+	}
+
+	for i := range locks {
+		if holder, err := holders[i].Result(); err == nil {
+			locks[i].Locked = true
+			locks[i].Holder = holder
+			locks[i].TTL = ttls[i].Val()
+		}
+	}
+	return nil
+}
+
+// Trigger 通知集群立即执行一次任务，所有注册了该任务的节点争抢分布式锁，只有一个节点执行
+func (c *Controller) Trigger(ctx context.Context, name string) error {
+	return c.publish(ctx, ControlTrigger, name)
+}
+
+// Pause 通知集群中所有节点暂停任务
+func (c *Controller) Pause(ctx context.Context, name string) error {
+	return c.publish(ctx, ControlPause, name)
+}
+
+// Resume 通知集群中所有节点恢复任务
+func (c *Controller) Resume(ctx context.Context, name string) error {
+	return c.publish(ctx, ControlResume, name)
+}
+
+// publish 广播控制指令
+func (c *Controller) publish(ctx context.Context, action, name string) error {
+	if err := c.publishMessage(ctx, controlMessage{Action: action, Task: name}); err != nil {
+		return fmt.Errorf("failed to publish %s of task %s: %v", action, name, err)
+	}
+	return nil
+}
+
+// PauseGroup 通知集群中所有节点暂停分组内的任务
+func (c *Controller) PauseGroup(ctx context.Context, group string) error {
+	return c.publishGroup(ctx, ControlPauseGroup, group)
+}
+
+// ResumeGroup 通知集群中所有节点恢复分组内的任务
+func (c *Controller) ResumeGroup(ctx context.Context, group string) error {
+	return c.publishGroup(ctx, ControlResumeGroup, group)
+}
+
+// publishGroup 广播针对分组的控制指令
+func (c *Controller) publishGroup(ctx context.Context, action, group string) error {
+	if err := c.publishMessage(ctx, controlMessage{Action: action, Group: group}); err != nil {
+		return fmt.Errorf("failed to publish %s of group %s: %v", action, group, err)
+	}
+	return nil
+}
+
+// publishMessage 广播控制指令
+func (c *Controller) publishMessage(ctx context.Context, msg controlMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return c.client.Publish(ctx, namespacedKey(c.namespace, "control"), data).Err()
+}
+
+// History 获取任务在整个集群中的执行历史，最新的记录在最前，limit<=0 时返回全部保留的记录
+func (c *Controller) History(ctx context.Context, name string, limit int64) ([]ExecutionRecord, error) {
+	stop := int64(-1)
+	if limit > 0 {
+		stop = limit - 1
+	}
+
+	items, err := c.client.LRange(ctx, namespacedKey(c.namespace, "history", name), 0, stop).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history of task %s: %v", name, err)
+	}
+
+	records := make([]ExecutionRecord, 0, len(items))
+	for _, item := range items {
+		var rec ExecutionRecord
+		if err := json.Unmarshal([]byte(item), &rec); err != nil {
+			return nil, fmt.Errorf("failed to decode history of task %s: %v", name, err)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// Status 获取任务在整个集群中的最近执行时间和下一次调度时间
+func (c *Controller) Status(ctx context.Context, name string) (TaskStatus, error) {
+	fields, err := c.client.HGetAll(ctx, namespacedKey(c.namespace, "status", name)).Result()
+	if err != nil {
+		return TaskStatus{}, fmt.Errorf("failed to get status of task %s: %v", name, err)
+	}
+	if len(fields) == 0 {
+		return TaskStatus{}, fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+
+	status := TaskStatus{
+		Task:       name,
+		LastNode:   fields[statusFieldLastNode],
+		LastStatus: ExecutionStatus(fields[statusFieldLastStatus]),
+	}
+	if v, ok := fields[statusFieldLastRun]; ok {
+		status.LastRun, _ = time.Parse(time.RFC3339Nano, v)
+	}
+	if v, ok := fields[statusFieldNextRun]; ok {
+		status.NextRun, _ = time.Parse(time.RFC3339Nano, v)
+	}
+	if v, ok := fields[statusFieldLastDuration]; ok {
+		d, _ := strconv.ParseInt(v, 10, 64)
+		status.LastDuration = time.Duration(d)
+	}
+	return status, nil
+}
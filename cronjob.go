@@ -0,0 +1,313 @@
+package redCorn
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// cronJobTaskAnnotation 保存任务名的注解，任务名不符合 Kubernetes 资源名规则时导出为修改后的资源名，导入时据此还原
+const cronJobTaskAnnotation = "redcorn.io/task"
+
+// cronJobNameMax CronJob 名称的最大长度，Kubernetes 需要在其后追加 Job 的时间戳后缀
+const cronJobNameMax = 52
+
+// Kubernetes CronJob 的并发策略
+const (
+	cronJobAllow   = "Allow"
+	cronJobForbid  = "Forbid"
+	cronJobReplace = "Replace"
+)
+
+// cronJob Kubernetes batch/v1 CronJob 清单中与调度相关的部分，导入时忽略其余字段
+type cronJob struct {
+	APIVersion string          `yaml:"apiVersion"`
+	Kind       string          `yaml:"kind"`
+	Metadata   cronJobMetadata `yaml:"metadata"`
+	Spec       cronJobSpec     `yaml:"spec"`
+}
+
+type cronJobMetadata struct {
+	Name        string            `yaml:"name"`
+	Namespace   string            `yaml:"namespace,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+type cronJobSpec struct {
+	Schedule          string          `yaml:"schedule"`
+	TimeZone          string          `yaml:"timeZone,omitempty"`
+	ConcurrencyPolicy string          `yaml:"concurrencyPolicy,omitempty"`
+	Suspend           bool            `yaml:"suspend,omitempty"`
+	JobTemplate       cronJobTemplate `yaml:"jobTemplate"`
+}
+
+type cronJobTemplate struct {
+	Spec struct {
+		ActiveDeadlineSeconds *int64 `yaml:"activeDeadlineSeconds,omitempty"`
+		BackoffLimit          *int   `yaml:"backoffLimit,omitempty"`
+		Template              struct {
+			Spec struct {
+				Containers    []cronJobContainer `yaml:"containers"`
+				RestartPolicy string             `yaml:"restartPolicy,omitempty"`
+			} `yaml:"spec"`
+		} `yaml:"template"`
+	} `yaml:"spec"`
+}
+
+type cronJobContainer struct {
+	Name    string   `yaml:"name"`
+	Image   string   `yaml:"image"`
+	Command []string `yaml:"command,omitempty"`
+	Args    []string `yaml:"args,omitempty"`
+}
+
+// LoadCronJobs 读取 Kubernetes CronJob 清单并创建任务调度器，见 ParseCronJobs
+func LoadCronJobs(path string, handlers map[string]func(ctx context.Context) error) (*TaskScheduler, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CronJob manifests: %v", err)
+	}
+	return ParseCronJobs(data, handlers)
+}
+
+// ParseCronJobs 从 Kubernetes CronJob 清单（可以是用 --- 分隔的多个文档）创建任务调度器，其他类型的资源被忽略
+// 任务名为注解 redcorn.io/task 或 metadata.name，任务函数按任务名从 handlers 中查找；
+// 导入 schedule、timeZone、concurrencyPolicy、activeDeadlineSeconds（WithTimeout）和 backoffLimit（WithRetry），
+// suspend 为 true 的 CronJob 不注册
+//
+// 任务锁保证同一任务不会重叠执行，即 Forbid；Allow（也是 Kubernetes 的默认值）按 Forbid 导入，
+// Replace 需要取消正在执行的任务，无法等价导入，返回错误
+func ParseCronJobs(data []byte, handlers map[string]func(ctx context.Context) error) (*TaskScheduler, error) {
+	ts := NewTaskScheduler()
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for doc := 1; ; doc++ {
+		var job cronJob
+		err := dec.Decode(&job)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CronJob manifest %d: %v", doc, err)
+		}
+		if job.Kind != "CronJob" || job.Spec.Suspend {
+			continue
+		}
+
+		name := job.taskName()
+		if name == "" {
+			return nil, fmt.Errorf("failed to import CronJob manifest %d: metadata.name is required", doc)
+		}
+		if ts.exists(name) {
+			return nil, fmt.Errorf("failed to import CronJob %s: task %s already exists", job.Metadata.Name, name)
+		}
+		handler, ok := handlers[name]
+		if !ok {
+			return nil, fmt.Errorf("failed to import CronJob %s: handler %s is not bound", job.Metadata.Name, name)
+		}
+		opts, err := job.options()
+		if err != nil {
+			return nil, fmt.Errorf("failed to import CronJob %s: %v", job.Metadata.Name, err)
+		}
+		if err := ts.register(name, TaskSchedule{TaskE: handler, Cron: job.Spec.Schedule, Options: opts}); err != nil {
+			return nil, err
+		}
+	}
+	return ts, nil
+}
+
+// taskName 导入后的任务名
+func (j cronJob) taskName() string {
+	if name := j.Metadata.Annotations[cronJobTaskAnnotation]; name != "" {
+		return name
+	}
+	return j.Metadata.Name
+}
+
+// options 把 CronJob 的配置转换为任务选项
+func (j cronJob) options() ([]TaskOption, error) {
+	opts := []TaskOption{WithCronFormat(CronFormatStandard)}
+
+	switch j.Spec.ConcurrencyPolicy {
+	case "", cronJobAllow, cronJobForbid:
+	case cronJobReplace:
+		return nil, errors.New("concurrencyPolicy Replace is not supported: tasks never overlap, change it to Forbid")
+	default:
+		return nil, fmt.Errorf("invalid concurrencyPolicy %q", j.Spec.ConcurrencyPolicy)
+	}
+	if j.Spec.TimeZone != "" {
+		loc, err := time.LoadLocation(j.Spec.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeZone %q: %v", j.Spec.TimeZone, err)
+		}
+		opts = append(opts, WithTimezone(loc))
+	}
+	if d := j.Spec.JobTemplate.Spec.ActiveDeadlineSeconds; d != nil && *d > 0 {
+		opts = append(opts, WithTimeout(time.Duration(*d)*time.Second))
+	}
+	if n := j.Spec.JobTemplate.Spec.BackoffLimit; n != nil && *n > 0 {
+		opts = append(opts, WithRetry(RetryPolicy{MaxRetries: *n}))
+	}
+	return opts, nil
+}
+
+// CronJobExportOptions 导出 Kubernetes CronJob 清单的配置
+type CronJobExportOptions struct {
+	Namespace string            // 清单的 metadata.namespace，可选
+	Image     string            // 执行任务的容器镜像，必填
+	Command   []string          // 执行单个任务的命令，任务名作为最后一个参数追加，如 []string{"/app", "run-task"}
+	Labels    map[string]string // 添加到每个清单的标签，可选
+}
+
+// ExportCronJobs 把当前注册的任务导出为 Kubernetes CronJob 清单（用 --- 分隔的 YAML），一次性任务不导出
+// 导出 schedule、timeZone、超时（activeDeadlineSeconds）和重试次数（backoffLimit），concurrencyPolicy 为 Forbid；
+// 6位表达式的秒字段必须为 0，@every 等 Kubernetes 不支持的表达式返回错误；执行条件、工作日历等其他选项不导出
+func (dtm *DistributedTaskManager) ExportCronJobs(opts CronJobExportOptions) ([]byte, error) {
+	if opts.Image == "" {
+		return nil, errors.New("failed to export CronJobs: image is required")
+	}
+
+	dtm.mu.RLock()
+	tasks := make([]*distributedTask, 0, len(dtm.tasks))
+	for _, t := range dtm.tasks {
+		if !t.isOnce() {
+			tasks = append(tasks, t)
+		}
+	}
+	dtm.mu.RUnlock()
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].name < tasks[j].name })
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	names := make(map[string]string, len(tasks))
+	for _, t := range tasks {
+		job, err := dtm.cronJob(t, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export task %s: %v", t.name, err)
+		}
+		if other, exists := names[job.Metadata.Name]; exists {
+			return nil, fmt.Errorf("failed to export task %s: CronJob name %s is already used by task %s", t.name, job.Metadata.Name, other)
+		}
+		names[job.Metadata.Name] = t.name
+		if err := enc.Encode(job); err != nil {
+			return nil, fmt.Errorf("failed to encode CronJob %s: %v", job.Metadata.Name, err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode CronJobs: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// cronJob 把任务转换为 CronJob 清单
+func (dtm *DistributedTaskManager) cronJob(t *distributedTask, opts CronJobExportOptions) (cronJob, error) {
+	schedule, err := dtm.kubernetesSchedule(t)
+	if err != nil {
+		return cronJob{}, err
+	}
+
+	var job cronJob
+	job.APIVersion = "batch/v1"
+	job.Kind = "CronJob"
+	job.Metadata.Name = cronJobName(t.name)
+	job.Metadata.Namespace = opts.Namespace
+	job.Metadata.Labels = opts.Labels
+	if job.Metadata.Name != t.name {
+		job.Metadata.Annotations = map[string]string{cronJobTaskAnnotation: t.name}
+	}
+	job.Spec.Schedule = schedule
+	job.Spec.ConcurrencyPolicy = cronJobForbid
+
+	loc := t.opts.location
+	if loc == nil {
+		loc = dtm.cfg.Location
+	}
+	if loc != nil && loc != time.Local && loc.String() != "Local" {
+		job.Spec.TimeZone = loc.String()
+	}
+
+	jobSpec := &job.Spec.JobTemplate.Spec
+	timeout := t.opts.timeout
+	if timeout == 0 {
+		timeout = dtm.cfg.TaskTimeout
+	}
+	if timeout > 0 {
+		seconds := int64((timeout + time.Second - 1) / time.Second)
+		jobSpec.ActiveDeadlineSeconds = &seconds
+	}
+	// Kubernetes 默认重试6次，显式写入任务的重试次数
+	retries := t.opts.retry.MaxRetries
+	jobSpec.BackoffLimit = &retries
+
+	pod := &jobSpec.Template.Spec
+	pod.RestartPolicy = "Never"
+	container := cronJobContainer{Name: "task", Image: opts.Image}
+	if len(opts.Command) > 0 {
+		container.Command = append([]string(nil), opts.Command...)
+		container.Args = []string{t.name}
+	}
+	pod.Containers = []cronJobContainer{container}
+	return job, nil
+}
+
+// kubernetesSchedule 把任务的表达式转换为 Kubernetes 支持的5位表达式
+func (dtm *DistributedTaskManager) kubernetesSchedule(t *distributedTask) (string, error) {
+	if t.opts.parser != nil {
+		return "", errors.New("tasks with a custom parser cannot be exported")
+	}
+	spec := strings.TrimSpace(t.spec)
+	if strings.HasPrefix(spec, "@every") {
+		return "", fmt.Errorf("schedule %q is not supported by Kubernetes", spec)
+	}
+	if strings.HasPrefix(spec, "@") {
+		return spec, nil
+	}
+
+	format := t.opts.cronFormat
+	if format == "" {
+		format = dtm.cfg.CronFormat
+	}
+	fields := strings.Fields(spec)
+	switch {
+	case format == CronFormatStandard, format == CronFormatOptionalSeconds && len(fields) == 5:
+		return spec, nil
+	case len(fields) == 6:
+		if fields[0] != "0" {
+			return "", fmt.Errorf("schedule %q fires at second %s, Kubernetes schedules have minute precision", spec, fields[0])
+		}
+		return strings.Join(fields[1:], " "), nil
+	default:
+		return "", fmt.Errorf("unsupported schedule %q", spec)
+	}
+}
+
+// cronJobName 把任务名转换为合法的 CronJob 名称：小写字母、数字和 "-"，不超过52个字符
+func cronJobName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteByte('-')
+		}
+	}
+	s := b.String()
+	if len(s) > cronJobNameMax {
+		s = s[:cronJobNameMax]
+	}
+	s = strings.Trim(s, "-")
+	if s == "" {
+		s = "task"
+	}
+	return s
+}
@@ -0,0 +1,357 @@
+package redCorn
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultShell 未在 crontab 中设置 SHELL 时执行命令使用的 shell
+const defaultShell = "/bin/sh"
+
+// shellOutputLimit 命令失败时错误信息中保留的输出长度
+const shellOutputLimit = 1024
+
+// CrontabOptions 导入 crontab 的配置
+type CrontabOptions struct {
+	// Commands 命令行到任务函数名称的映射，命令行去掉首尾空白后完整匹配，映射到的任务函数从 Handlers 中查找
+	Commands map[string]string
+	// Handlers 按名称引用的任务函数
+	Handlers map[string]func(ctx context.Context) error
+	// Exec 没有映射到任务函数的命令通过 shell 执行，为 false 时这些命令返回错误，避免意外在所有节点上开放执行任意命令
+	Exec bool
+	// System 按系统 crontab（/etc/crontab、/etc/cron.d）格式解析，命令前有一个用户字段，导入时忽略
+	System bool
+}
+
+// crontabEntry crontab 中的一条调度
+type crontabEntry struct {
+	line     int
+	name     string // "# task: <名称>" 注释指定的任务名，为空时自动生成
+	spec     string
+	command  string
+	stdin    string
+	env      []string
+	shell    string
+	location *time.Location
+}
+
+// LoadCrontab 读取 crontab 文件并创建任务调度器，便于把单机 cron 迁移到分布式调度
+//
+//	# task: nightly-backup
+//	0 2 * * * /usr/local/bin/backup.sh --full
+//	*/15 * * * * /usr/local/bin/sync-users
+//
+//	scheduler, err := redCorn.LoadCrontab("/etc/cron.d/app", redCorn.CrontabOptions{
+//		Commands: map[string]string{"/usr/local/bin/sync-users": "sync-users"},
+//		Handlers: map[string]func(ctx context.Context) error{"sync-users": syncUsers},
+//		Exec:     true, // 其余命令通过 shell 执行
+//		System:   true,
+//	})
+func LoadCrontab(path string, opts CrontabOptions) (*TaskScheduler, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read crontab: %v", err)
+	}
+	return ParseCrontab(data, opts)
+}
+
+// ParseCrontab 解析 crontab 内容并创建任务调度器，任务使用5位表达式（CronFormatStandard）
+// 支持 @daily 等描述符、环境变量赋值（SHELL、CRON_TZ 和传给命令的其他变量）和命令中表示标准输入的 %；不支持 @reboot
+// 任务名按以下顺序确定："# task: <名称>" 注释、映射到的任务函数名称、由命令生成的名称（如 cron-backup-sh-1a2b3c）
+func ParseCrontab(data []byte, opts CrontabOptions) (*TaskScheduler, error) {
+	entries, err := parseCrontab(data, opts.System)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := NewTaskScheduler()
+	for _, e := range entries {
+		task, handlerName, err := opts.task(e)
+		if err != nil {
+			return nil, fmt.Errorf("crontab line %d: %v", e.line, err)
+		}
+
+		name := e.name
+		if name == "" {
+			name = handlerName
+		}
+		if name == "" {
+			name = commandTaskName(e.command)
+		}
+		if e.name == "" {
+			// 自动生成的名称重复时追加序号
+			base := name
+			for i := 2; ts.exists(name); i++ {
+				name = fmt.Sprintf("%s-%d", base, i)
+			}
+		} else if ts.exists(name) {
+			return nil, fmt.Errorf("crontab line %d: task %s already exists", e.line, name)
+		}
+
+		options := []TaskOption{WithCronFormat(CronFormatStandard)}
+		if e.location != nil {
+			options = append(options, WithTimezone(e.location))
+		}
+		if err := ts.register(name, TaskSchedule{TaskE: task, Cron: e.spec, Options: options}); err != nil {
+			return nil, fmt.Errorf("crontab line %d: %v", e.line, err)
+		}
+	}
+	return ts, nil
+}
+
+// task 返回条目对应的任务函数，映射到任务函数时同时返回其名称
+func (opts CrontabOptions) task(e crontabEntry) (func(ctx context.Context) error, string, error) {
+	if handlerName, ok := opts.Commands[e.command]; ok {
+		handler, ok := opts.Handlers[handlerName]
+		if !ok {
+			return nil, "", fmt.Errorf("handler %s is not bound", handlerName)
+		}
+		return handler, handlerName, nil
+	}
+	if !opts.Exec {
+		return nil, "", fmt.Errorf("command %q is not mapped to a handler, map it in CrontabOptions.Commands or set CrontabOptions.Exec", e.command)
+	}
+	cmd := shellCommand{shell: e.shell, command: e.command, stdin: e.stdin, env: e.env}
+	return cmd.run, "", nil
+}
+
+// exists 调度器中是否已注册任务
+func (ts *TaskScheduler) exists(name string) bool {
+	_, exists := ts.tasks[name]
+	return exists
+}
+
+// parseCrontab 逐行解析 crontab，环境变量赋值对之后的条目生效
+func parseCrontab(data []byte, system bool) ([]crontabEntry, error) {
+	var (
+		entries  []crontabEntry
+		env      []string
+		shell    = defaultShell
+		location *time.Location
+		name     string
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			name = ""
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(line[1:]), "task:"); ok {
+				name = strings.TrimSpace(v)
+			}
+			continue
+		}
+
+		if key, value, ok := crontabAssignment(line); ok {
+			switch key {
+			case "SHELL":
+				shell = value
+			case "CRON_TZ":
+				loc, err := time.LoadLocation(value)
+				if err != nil {
+					return nil, fmt.Errorf("crontab line %d: invalid CRON_TZ %q: %v", lineNo, value, err)
+				}
+				location = loc
+			case "MAILTO", "MAILFROM":
+				// 执行结果通过执行历史和失败通知查看，不发送邮件
+			default:
+				env = append(env, key+"="+value)
+			}
+			continue
+		}
+
+		entry, err := parseCrontabLine(line, system)
+		if err != nil {
+			return nil, fmt.Errorf("crontab line %d: %v", lineNo, err)
+		}
+		entry.line = lineNo
+		entry.name = name
+		entry.env = append([]string(nil), env...)
+		entry.shell = shell
+		entry.location = location
+		entries = append(entries, entry)
+		name = ""
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read crontab: %v", err)
+	}
+	return entries, nil
+}
+
+// crontabAssignment 解析 "NAME=value" 形式的环境变量赋值，值两侧的引号被去掉
+func crontabAssignment(line string) (key, value string, ok bool) {
+	key, value, ok = strings.Cut(line, "=")
+	if !ok {
+		return "", "", false
+	}
+	key = strings.TrimSpace(key)
+	if key == "" || strings.ContainsAny(key, " \t*@") {
+		return "", "", false
+	}
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	return key, value, true
+}
+
+// parseCrontabLine 解析调度行：5个时间字段或一个描述符，系统 crontab 中的用户字段，然后是命令
+func parseCrontabLine(line string, system bool) (crontabEntry, error) {
+	specFields := 5
+	if strings.HasPrefix(line, "@") {
+		specFields = 1
+	}
+	n := specFields
+	if system {
+		n++
+	}
+	fields, command := splitFields(line, n)
+	if len(fields) < n || command == "" {
+		return crontabEntry{}, errors.New("expected a schedule followed by a command")
+	}
+
+	spec := strings.Join(fields[:specFields], " ")
+	if spec == "@reboot" {
+		return crontabEntry{}, errors.New("@reboot is not supported, use WithRunOnStart instead")
+	}
+
+	command, stdin := splitCrontabStdin(command)
+	return crontabEntry{spec: spec, command: command, stdin: stdin}, nil
+}
+
+// splitFields 按空白拆出前 n 个字段，返回字段和剩余部分
+func splitFields(line string, n int) ([]string, string) {
+	fields := make([]string, 0, n)
+	rest := line
+	for len(fields) < n {
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" {
+			break
+		}
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			end = len(rest)
+		}
+		fields = append(fields, rest[:end])
+		rest = rest[end:]
+	}
+	return fields, strings.TrimSpace(rest)
+}
+
+// splitCrontabStdin 按 crontab 的规则处理命令中的 %：第一个未转义的 % 之后的内容作为标准输入，其余的 % 表示换行，\% 表示 % 本身
+func splitCrontabStdin(command string) (string, string) {
+	var cmd, stdin strings.Builder
+	out := &cmd
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case c == '\\' && i+1 < len(command) && command[i+1] == '%':
+			out.WriteByte('%')
+			i++
+		case c == '%' && out == &cmd:
+			out = &stdin
+		case c == '%':
+			out.WriteByte('\n')
+		default:
+			out.WriteByte(c)
+		}
+	}
+	input := stdin.String()
+	if out == &stdin {
+		input += "\n"
+	}
+	return strings.TrimSpace(cmd.String()), input
+}
+
+// commandTaskName 由命令生成任务名：命令文件名加命令的哈希，同一命令在不同节点、文件中的位置变化时名称不变
+func commandTaskName(command string) string {
+	program, _, _ := strings.Cut(command, " ")
+	var b strings.Builder
+	for _, r := range strings.ToLower(filepath.Base(program)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteByte('-')
+		}
+	}
+	slug := strings.Trim(b.String(), "-")
+	if slug == "" {
+		slug = "command"
+	}
+	h := fnv.New32a()
+	h.Write([]byte(command))
+	return fmt.Sprintf("cron-%s-%06x", slug, h.Sum32()&0xffffff)
+}
+
+// ShellTask 返回通过 /bin/sh -c 执行命令的任务函数，命令以非零状态退出时返回包含输出末尾的错误
+// 任务上下文取消（超时或 Stop()）时命令被终止；命令在所有节点上都可能执行，各节点需要具备相同的执行环境
+func ShellTask(command string) func(ctx context.Context) error {
+	return shellCommand{shell: defaultShell, command: command}.run
+}
+
+// shellCommand 通过 shell 执行的命令
+type shellCommand struct {
+	shell   string
+	command string
+	stdin   string
+	env     []string // 追加到当前进程环境变量之后
+}
+
+// run 执行命令，失败时返回退出状态和合并后的标准输出、标准错误的末尾部分
+func (c shellCommand) run(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, c.shell, "-c", c.command)
+	cmd.Env = append(os.Environ(), c.env...)
+	if c.stdin != "" {
+		cmd.Stdin = strings.NewReader(c.stdin)
+	}
+	// 输出量不受控制，只保留末尾部分
+	output := &tailWriter{limit: shellOutputLimit}
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	if err := cmd.Run(); err != nil {
+		if out := strings.TrimSpace(output.String()); out != "" {
+			return fmt.Errorf("command %q failed: %v: %s", c.command, err, out)
+		}
+		return fmt.Errorf("command %q failed: %v", c.command, err)
+	}
+	return nil
+}
+
+// tailWriter 只保留最后 limit 个字节的 Writer，exec.Cmd 在同一个 Writer 上合并标准输出和标准错误时只从一个协程写入
+type tailWriter struct {
+	limit int
+	buf   []byte
+}
+
+// Write 追加 p，超过 limit 的部分从开头丢弃，始终返回写入成功
+func (w *tailWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if n >= w.limit {
+		w.buf = append(w.buf[:0], p[n-w.limit:]...)
+		return n, nil
+	}
+	if drop := len(w.buf) + n - w.limit; drop > 0 {
+		w.buf = append(w.buf[:0], w.buf[drop:]...)
+	}
+	w.buf = append(w.buf, p...)
+	return n, nil
+}
+
+// String 返回保留的输出
+func (w *tailWriter) String() string {
+	return string(w.buf)
+}
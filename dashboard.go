@@ -0,0 +1,133 @@
+package redCorn
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"html/template"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// dashboardFailureLimit 面板上展示的最近失败记录数
+const dashboardFailureLimit = 20
+
+//go:embed dashboard.html
+var dashboardHTML string
+
+// dashboardTmpl 面板页面模板
+var dashboardTmpl = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"fmtTime": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Local().Format("2006-01-02 15:04:05")
+	},
+}).Parse(dashboardHTML))
+
+// dashboardTask 面板上展示的任务信息
+type dashboardTask struct {
+	adminTask
+	Locked     bool
+	LockHolder string
+	LockTTL    time.Duration
+}
+
+// dashboardData 面板页面数据
+type dashboardData struct {
+	Node        string
+	GeneratedAt time.Time
+	Tasks       []dashboardTask
+	ActiveLocks []LockInfo // 集群中当前被持有的锁
+	Failures    []ExecutionRecord
+}
+
+// handleDashboard GET / 展示任务面板
+func (dtm *DistributedTaskManager) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := dtm.dashboardData(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := dashboardTmpl.Execute(w, data); err != nil {
+		dtm.log.Error("Failed to render dashboard: ", err)
+	}
+}
+
+// dashboardData 汇总当前节点注册的任务、集群运行状态、锁持有情况、集群中被持有的锁和最近失败记录
+func (dtm *DistributedTaskManager) dashboardData(ctx context.Context) (*dashboardData, error) {
+	dtm.mu.RLock()
+	tasks := make([]*distributedTask, 0, len(dtm.tasks))
+	for _, t := range dtm.tasks {
+		tasks = append(tasks, t)
+	}
+	dtm.mu.RUnlock()
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].name < tasks[j].name })
+
+	// 批量查询锁的持有者和剩余时间
+	locks := make([]LockInfo, len(tasks))
+	for i, t := range tasks {
+		locks[i] = LockInfo{Task: t.name, Key: dtm.lockKey(t)}
+	}
+	if err := dtm.inspectLocks(ctx, locks); err != nil {
+		return nil, err
+	}
+
+	data := &dashboardData{
+		Node:        dtm.nodeID,
+		GeneratedAt: dtm.clock.Now(),
+		Tasks:       make([]dashboardTask, 0, len(tasks)),
+	}
+	active, err := dtm.ListLocks()
+	if err != nil && !errors.Is(err, ErrLockInspectUnsupported) {
+		return nil, err
+	}
+	for i := range active {
+		if active[i].TTL > 0 {
+			active[i].TTL = active[i].TTL.Round(time.Second)
+		}
+	}
+	data.ActiveLocks = active
+	for i, t := range tasks {
+		item := dashboardTask{adminTask: dtm.adminTask(t)}
+		if status, err := dtm.GetTaskStatus(t.name); err == nil {
+			item.Status = &status
+		}
+		if locks[i].Locked {
+			item.Locked = true
+			item.LockHolder = locks[i].Holder
+			item.LockTTL = locks[i].TTL.Round(time.Second)
+		}
+		data.Tasks = append(data.Tasks, item)
+
+		records, err := dtm.GetHistory(t.name, dashboardFailureLimit)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			if rec.Status == ExecutionFailed {
+				data.Failures = append(data.Failures, rec)
+			}
+		}
+	}
+
+	sort.Slice(data.Failures, func(i, j int) bool {
+		return data.Failures[i].StartedAt.After(data.Failures[j].StartedAt)
+	})
+	if len(data.Failures) > dashboardFailureLimit {
+		data.Failures = data.Failures[:dashboardFailureLimit]
+	}
+	return data, nil
+}
@@ -0,0 +1,126 @@
+package redCorn
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultDeadmanTolerance = time.Minute    // 默认允许的执行延迟
+	missedAlertTTL          = 24 * time.Hour // 漏执行去重记录的保留时间
+)
+
+// DeadmanCfg 漏执行检测配置
+type DeadmanCfg struct {
+	CheckInterval time.Duration        // 检查间隔，0表示不启用
+	Tolerance     time.Duration        // 超过预期执行时间多久仍未执行视为漏执行，默认1分钟
+	OnMissed      func(miss MissedRun) // 检测到漏执行时回调，可选
+}
+
+// MissedRun 一次漏执行
+type MissedRun struct {
+	Task     string    `json:"task"`
+	Expected time.Time `json:"expected"` // 预期的执行时间
+	LastRun  time.Time `json:"last_run"` // 集群中最近一次执行的时间，从未执行过时为零值
+	Node     string    `json:"node"`     // 检测到漏执行的节点
+}
+
+// startDeadman 启动漏执行检测，定期根据 Redis 中的最近执行时间检查每个任务是否按时执行
+func (dtm *DistributedTaskManager) startDeadman() {
+	interval := dtm.cfg.Deadman.CheckInterval
+	if interval <= 0 {
+		return
+	}
+
+	since := dtm.clock.Now()
+	dtm.deadmanDone = make(chan struct{})
+	go func() {
+		defer close(dtm.deadmanDone)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-dtm.ctx.Done():
+				return
+			case <-ticker.C:
+				dtm.checkMissedRuns(since)
+			}
+		}
+	}()
+}
+
+// stopDeadman 等待漏执行检测协程退出
+func (dtm *DistributedTaskManager) stopDeadman() {
+	if dtm.deadmanDone != nil {
+		<-dtm.deadmanDone
+	}
+}
+
+// checkMissedRuns 检查所有未暂停的任务，since 之前的调度不计入检测
+func (dtm *DistributedTaskManager) checkMissedRuns(since time.Time) {
+	tolerance := dtm.cfg.Deadman.Tolerance
+	if tolerance <= 0 {
+		tolerance = defaultDeadmanTolerance
+	}
+
+	dtm.mu.RLock()
+	tasks := make([]*distributedTask, 0, len(dtm.tasks))
+	for _, t := range dtm.tasks {
+		if !t.paused.Load() {
+			tasks = append(tasks, t)
+		}
+	}
+	dtm.mu.RUnlock()
+
+	now := dtm.clock.Now()
+	for _, t := range tasks {
+		status, err := dtm.controller.Status(dtm.ctx, t.name)
+		if dtm.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			dtm.logRun(slog.LevelError, t.name, "", "Failed to load status for missed run check", slog.Any("error", err))
+			continue
+		}
+
+		from := status.LastRun
+		if from.Before(since) {
+			from = since
+		}
+		expected := t.schedule.Next(from)
+		if expected.IsZero() || now.Before(expected.Add(tolerance)) {
+			continue
+		}
+		dtm.reportMissedRun(t, MissedRun{Task: t.name, Expected: expected, LastRun: status.LastRun, Node: dtm.nodeID})
+	}
+}
+
+// reportMissedRun 上报漏执行，同一次漏执行在集群中只上报一次
+func (dtm *DistributedTaskManager) reportMissedRun(t *distributedTask, miss MissedRun) {
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+
+	key := dtm.key("missed", t.name, strconv.FormatInt(miss.Expected.Unix(), 10))
+	first, err := dtm.redisClient.SetNX(ctx, key, dtm.nodeID, missedAlertTTL).Result()
+	if err != nil {
+		dtm.logRun(slog.LevelError, t.name, "", "Failed to record missed run", slog.Any("error", err))
+		return
+	}
+	if !first {
+		return
+	}
+
+	msg := fmt.Sprintf("expected to run at %s", miss.Expected.Format(time.RFC3339))
+	if !miss.LastRun.IsZero() {
+		msg += ", last run at " + miss.LastRun.Format(time.RFC3339)
+	}
+	dtm.logRun(slog.LevelWarn, t.name, "", "Missed run, "+msg)
+	if dtm.cfg.Deadman.OnMissed != nil {
+		dtm.cfg.Deadman.OnMissed(miss)
+	}
+	dtm.sendNotification(t, Notification{Kind: NotifyMissed, Task: t.name, Node: dtm.nodeID, Time: dtm.clock.Now(), Error: msg})
+}
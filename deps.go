@@ -0,0 +1,228 @@
+package redCorn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	goredislib "github.com/go-redis/redis/v8"
+)
+
+// 依赖任务的跳过原因
+const (
+	SkipReasonDependencies    = "dependencies not met"
+	SkipReasonDependencyError = "failed to check dependencies"
+)
+
+// dependencyKeyGrace 调度窗口结束后依赖记录的保留时间
+const dependencyKeyGrace = time.Minute
+
+// recordDependencyScript 记录前置任务在本窗口内执行成功，返回窗口内是否所有前置任务都已成功
+// KEYS[1] 窗口的依赖集合；ARGV[1] 过期毫秒数，0 表示不过期；ARGV[2] 完成时是否清空集合；ARGV[3] 执行成功的前置任务；ARGV[4:] 所有前置任务
+var recordDependencyScript = goredislib.NewScript(`
+redis.call("SADD", KEYS[1], ARGV[3])
+if tonumber(ARGV[1]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+for i = 4, #ARGV do
+	if redis.call("SISMEMBER", KEYS[1], ARGV[i]) == 0 then
+		return 0
+	end
+end
+if ARGV[2] == "1" then
+	redis.call("DEL", KEYS[1])
+end
+return 1
+`)
+
+// WithDependsOn 声明任务依赖的前置任务，前置任务都执行成功后任务才会执行
+//   - 有 Cron 表达式的任务：每个调度时刻检查上一个调度时刻之后前置任务是否都已成功，否则以 SkipReasonDependencies 跳过
+//   - 表达式为 ManualSpec 的任务：前置任务在上一次触发之后都成功时立即在集群中触发一次
+//
+// 依赖关系在注册时检查，存在循环依赖时注册失败；前置任务和依赖它的任务需要注册在同一批节点上
+func WithDependsOn(prereqs ...string) TaskOption {
+	return func(o *taskOptions) {
+		o.dependsOn = append(o.dependsOn, prereqs...)
+	}
+}
+
+// validateDependsOn 检查前置任务，任务不能依赖自身
+func (o taskOptions) validateDependsOn(name string) error {
+	for _, p := range o.dependsOn {
+		if p == "" {
+			return errors.New("prerequisite task name is required")
+		}
+		if p == name {
+			return errors.New("task cannot depend on itself")
+		}
+	}
+	return nil
+}
+
+// dependencyCycle 在任务依赖关系中查找循环依赖，返回循环经过的任务，不存在时返回 nil
+// WithDependsOn 和 WithThen 都表示先后关系：B 依赖 A 与 A 执行后触发 B 相同
+func dependencyCycle(options map[string]taskOptions) []string {
+	edges := make(map[string][]string, len(options))
+	for name, o := range options {
+		edges[name] = append(edges[name], o.dependsOn...)
+		for _, next := range o.then {
+			edges[next] = append(edges[next], name)
+		}
+	}
+	names := make([]string, 0, len(edges))
+	for name := range edges {
+		names = append(names, name)
+		sort.Strings(edges[name])
+	}
+	sort.Strings(names)
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(edges))
+	var path []string
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visiting:
+			for i, n := range path {
+				if n == name {
+					return append(append([]string(nil), path[i:]...), name)
+				}
+			}
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range edges[name] {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+	for _, name := range names {
+		if cycle := visit(name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// checkDependencyCycle 检查 options 中的依赖关系，存在循环依赖时返回错误
+func checkDependencyCycle(options map[string]taskOptions) error {
+	if cycle := dependencyCycle(options); cycle != nil {
+		return fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+	return nil
+}
+
+// checkTaskCycle 检查已注册的任务加上 tasks 之后的依赖关系，调用方需持有 dtm.mu
+func (dtm *DistributedTaskManager) checkTaskCycle(tasks ...*distributedTask) error {
+	options := make(map[string]taskOptions, len(dtm.tasks)+len(tasks))
+	for name, t := range dtm.tasks {
+		options[name] = t.opts
+	}
+	for _, t := range tasks {
+		options[t.name] = t.opts
+	}
+	return checkDependencyCycle(options)
+}
+
+// dependencyKey 任务 name 在调度时刻 tick 之前的窗口内已成功的前置任务，tick 为零值表示按轮次记录
+func (dtm *DistributedTaskManager) dependencyKey(name string, tick time.Time) string {
+	if tick.IsZero() {
+		return dtm.key("deps", name, "round")
+	}
+	return dtm.key("deps", name, strconv.FormatInt(tick.Unix(), 10))
+}
+
+// notifyDependents 任务执行成功后为依赖它的任务记录本窗口内的成功，只由触发执行的任务在前置任务都成功后触发一次
+func (dtm *DistributedTaskManager) notifyDependents(t *distributedTask, runID string) {
+	dtm.mu.RLock()
+	var dependents []*distributedTask
+	for _, d := range dtm.tasks {
+		for _, p := range d.opts.dependsOn {
+			if p == t.name {
+				dependents = append(dependents, d)
+				break
+			}
+		}
+	}
+	dtm.mu.RUnlock()
+
+	now := dtm.clock.Now()
+	for _, d := range dependents {
+		// 记录到依赖任务的下一个调度时刻对应的窗口，不会再调度的任务按轮次记录
+		tick := d.schedule.Next(now)
+		var ttl time.Duration
+		if !tick.IsZero() {
+			ttl = tick.Sub(now) + dependencyKeyGrace
+		}
+
+		args := []interface{}{ttl.Milliseconds(), "0", t.name}
+		if tick.IsZero() {
+			args[1] = "1"
+		}
+		for _, p := range d.opts.dependsOn {
+			args = append(args, p)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+		done, err := recordDependencyScript.Run(ctx, dtm.redisClient, []string{dtm.dependencyKey(d.name, tick)}, args...).Int()
+		cancel()
+		if err != nil {
+			dtm.logRun(slog.LevelError, t.name, runID, "Failed to record dependency", slog.String("dependent", d.name), slog.Any("error", err))
+			continue
+		}
+		if done == 1 && tick.IsZero() {
+			dtm.logRun(slog.LevelInfo, t.name, runID, "All prerequisites succeeded, triggering dependent task", slog.String("dependent", d.name))
+			dtm.triggerTasks(t, runID, d.name)
+		}
+	}
+}
+
+// checkDependencies 检查调度时刻 tick 之前的窗口内前置任务是否都已成功，未满足或出错时在执行记录中写入跳过原因
+func (dtm *DistributedTaskManager) checkDependencies(ctx context.Context, t *distributedTask, tick time.Time, rec *ExecutionRecord) bool {
+	succeeded, err := dtm.redisClient.SMembers(ctx, dtm.dependencyKey(t.name, tick)).Result()
+	if err != nil {
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonDependencyError
+		rec.setError(err)
+		dtm.logRun(slog.LevelError, t.name, rec.RunID, "Failed to check dependencies, skipping execution", slog.Any("error", err))
+		return false
+	}
+
+	done := make(map[string]bool, len(succeeded))
+	for _, p := range succeeded {
+		done[p] = true
+	}
+	var pending []string
+	for _, p := range t.opts.dependsOn {
+		if !done[p] {
+			pending = append(pending, p)
+		}
+	}
+	if len(pending) > 0 {
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonDependencies
+		dtm.logRun(slog.LevelWarn, t.name, rec.RunID, "Dependencies not met, skipping execution", slog.Time("tick", tick), slog.Any("pending", pending))
+		return false
+	}
+	return true
+}
+
+// DependsOn 为已注册的任务 name 追加前置任务，见 WithDependsOn，存在循环依赖时返回错误
+func (ts *TaskScheduler) DependsOn(name string, prereqs ...string) error {
+	return ts.appendOption(name, WithDependsOn(prereqs...))
+}
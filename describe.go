@@ -0,0 +1,231 @@
+package redCorn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 描述符对应的6位表达式
+var descriptorSpecs = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+var (
+	monthNames   = []string{"", "January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	weekdayNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+)
+
+// DescribeSpec 将 Cron 表达式转换为易读的英文描述，例如 "0/10 * * * * ?" 描述为 "every 10 seconds"
+// 支持5位和6位表达式以及 @daily、@every 等描述符
+func DescribeSpec(spec string) (string, error) {
+	if strings.TrimSpace(spec) == ManualSpec {
+		return "only when triggered", nil
+	}
+	if _, err := optionalSecondsParser.Parse(spec); err != nil {
+		return "", fmt.Errorf("invalid cron spec %q: %v", spec, err)
+	}
+
+	spec = strings.TrimSpace(spec)
+	tz := ""
+	if hasSpecTimezone(spec) {
+		i := strings.IndexAny(spec, " \t")
+		tz = " (" + spec[strings.Index(spec, "=")+1:i] + ")"
+		spec = strings.TrimSpace(spec[i:])
+	}
+
+	if strings.HasPrefix(spec, "@every ") {
+		return "every " + strings.TrimSpace(strings.TrimPrefix(spec, "@every ")) + tz, nil
+	}
+	if s, ok := descriptorSpecs[spec]; ok {
+		spec = s
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) == 5 {
+		fields = append([]string{"0"}, fields...)
+	}
+	for i, f := range fields {
+		if f == "?" {
+			fields[i] = "*"
+		}
+	}
+	sec, min, hour, dom, month, dow := fields[0], fields[1], fields[2], fields[3], fields[4], fields[5]
+
+	parts := []string{describeTime(sec, min, hour)}
+	if dom != "*" {
+		parts = append(parts, describeField(dom, "day", "days", nil, "on day", "of the month"))
+	}
+	if month != "*" {
+		parts = append(parts, describeField(month, "month", "months", monthNames, "in", ""))
+	}
+	if dow != "*" {
+		parts = append(parts, describeField(dow, "day of the week", "days of the week", weekdayNames, "on", ""))
+	}
+	if dom == "*" && month == "*" && dow == "*" && isSingle(sec) && isSingle(min) && allSingle(hour) {
+		parts[0] = "every day " + parts[0]
+	}
+	return strings.Join(parts, " ") + tz, nil
+}
+
+// describeTime 描述秒、分、时三个字段
+func describeTime(sec, min, hour string) string {
+	switch {
+	case isSingle(sec) && isSingle(min) && isSingle(hour):
+		return "at " + clock(hour, min, sec)
+	case isSingle(sec) && isSingle(min) && allSingle(hour):
+		hours := strings.Split(hour, ",")
+		times := make([]string, len(hours))
+		for i, h := range hours {
+			times[i] = clock(h, min, sec)
+		}
+		return "at " + joinList(times)
+	}
+
+	var parts []string
+	switch {
+	case sec == "*":
+		parts = append(parts, "every second")
+	case sec == "0" && min == "*":
+		parts = append(parts, "every minute")
+	case sec != "0" && min == "*" && !isStep(sec):
+		parts = append(parts, describeField(sec, "second", "seconds", nil, "at second", "of every minute"))
+	case sec != "0":
+		parts = append(parts, describeField(sec, "second", "seconds", nil, "at second", ""))
+	}
+
+	switch {
+	case min == "*":
+	case min == "0" && sec == "0" && hour == "*":
+		parts = append(parts, "every hour")
+	case min == "0" && sec == "0" && (isStep(hour) || isRange(hour)):
+		// 由小时字段描述，例如 "every 2 hours"
+	case hour == "*" && !isStep(min):
+		parts = append(parts, describeField(min, "minute", "minutes", nil, "at minute", "of every hour"))
+	default:
+		parts = append(parts, describeField(min, "minute", "minutes", nil, "at minute", ""))
+	}
+
+	switch {
+	case hour == "*":
+	case isRange(hour) && min == "0" && sec == "0":
+		lo, hi, _ := strings.Cut(hour, "-")
+		parts = append(parts, "every hour from "+clock(lo, "0", "0")+" through "+clock(hi, "0", "0"))
+	case isStep(hour):
+		parts = append(parts, describeField(hour, "hour", "hours", nil, "at hour", ""))
+	default:
+		parts = append(parts, "during "+describeField(hour, "hour", "hours", nil, "hour", ""))
+	}
+	return strings.Join(parts, " ")
+}
+
+// describeField 描述单个字段，names 用于将数字转换为月份或星期名称
+func describeField(field, unit, units string, names []string, prefix, suffix string) string {
+	name := func(v string) string {
+		if names == nil {
+			return v
+		}
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 && n < len(names) {
+			return names[n]
+		}
+		// JAN、MON 等英文缩写
+		for _, n := range names {
+			if len(n) >= 3 && strings.EqualFold(n[:3], v) {
+				return n
+			}
+		}
+		return v
+	}
+
+	var desc string
+	switch {
+	case isStep(field):
+		start, step, _ := strings.Cut(field, "/")
+		if step == "1" {
+			desc = "every " + unit
+		} else {
+			desc = "every " + step + " " + units
+		}
+		switch {
+		case isRange(start):
+			lo, hi, _ := strings.Cut(start, "-")
+			desc += " from " + name(lo) + " through " + name(hi)
+		case start != "*" && start != "0":
+			desc += " starting at " + unit + " " + name(start)
+		}
+	case isRange(field):
+		lo, hi, _ := strings.Cut(field, "-")
+		if names != nil {
+			desc = prefix + " " + name(lo) + " through " + name(hi)
+		} else {
+			desc = prefix + "s " + lo + " through " + hi
+		}
+	case isList(field):
+		values := strings.Split(field, ",")
+		for i, v := range values {
+			values[i] = name(v)
+		}
+		if names != nil {
+			desc = prefix + " " + joinList(values)
+		} else {
+			desc = prefix + "s " + joinList(values)
+		}
+	default:
+		if names != nil {
+			desc = prefix + " " + name(field)
+		} else {
+			desc = prefix + " " + field
+		}
+	}
+	if suffix != "" {
+		desc += " " + suffix
+	}
+	return desc
+}
+
+// clock 将时、分、秒格式化为 HH:MM 或 HH:MM:SS
+func clock(hour, min, sec string) string {
+	h, _ := strconv.Atoi(hour)
+	m, _ := strconv.Atoi(min)
+	s, _ := strconv.Atoi(sec)
+	if s != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d", h, m)
+}
+
+// joinList 以 "a, b and c" 的形式连接
+func joinList(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return strings.Join(values[:len(values)-1], ", ") + " and " + values[len(values)-1]
+}
+
+func isStep(field string) bool { return strings.Contains(field, "/") }
+func isRange(field string) bool {
+	return !isStep(field) && !isList(field) && strings.Contains(field, "-")
+}
+func isList(field string) bool { return strings.Contains(field, ",") }
+
+// isSingle 字段是否为单个数值
+func isSingle(field string) bool {
+	_, err := strconv.Atoi(field)
+	return err == nil
+}
+
+// allSingle 字段是否为单个数值或由单个数值组成的列表
+func allSingle(field string) bool {
+	for _, v := range strings.Split(field, ",") {
+		if !isSingle(v) {
+			return false
+		}
+	}
+	return true
+}
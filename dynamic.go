@@ -0,0 +1,232 @@
+package redCorn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// defaultDynamicSyncInterval 默认的动态任务同步间隔
+const defaultDynamicSyncInterval = 30 * time.Second
+
+// ErrDynamicTaskNotFound 动态任务不存在
+var ErrDynamicTaskNotFound = errors.New("dynamic task not found")
+
+// DynamicTasksCfg 动态任务配置：任务定义保存在 Redis 的 <Namespace>:dynamic 哈希中，各节点监听变更并同步调度，无需重新部署即可调整
+type DynamicTasksCfg struct {
+	Enabled      bool          // 启用动态任务
+	SyncInterval time.Duration // 定期全量同步的间隔，覆盖直接修改哈希等未发出通知的变更，默认30秒
+}
+
+// DynamicTask 保存在 Redis 中的动态任务定义，处理函数需在各节点上通过 HandleJob 或 Handle 按名称注册
+type DynamicTask struct {
+	Name    string          `json:"name"`
+	Cron    string          `json:"cron"`
+	Handler string          `json:"handler"`           // 处理函数名称
+	Payload json.RawMessage `json:"payload,omitempty"` // 传给处理函数的参数
+	Group   string          `json:"group,omitempty"`
+}
+
+// validate 检查任务定义，任务管理器不可见时5位和6位表达式均可通过
+func (d DynamicTask) validate() error {
+	if d.Name == "" {
+		return errors.New("task name is required")
+	}
+	if d.Handler == "" {
+		return errors.New("handler is required")
+	}
+	if _, err := (manualParser{optionalSecondsParser}).Parse(d.Cron); err != nil {
+		return fmt.Errorf("invalid cron spec %q: %v", d.Cron, err)
+	}
+	if len(d.Payload) > 0 && !json.Valid(d.Payload) {
+		return errors.New("payload must be valid JSON")
+	}
+	return nil
+}
+
+// equal 两个任务定义是否相同
+func (d DynamicTask) equal(other DynamicTask) bool {
+	return d.Name == other.Name && d.Cron == other.Cron && d.Handler == other.Handler &&
+		d.Group == other.Group && bytes.Equal(d.Payload, other.Payload)
+}
+
+// dynamicKey 保存动态任务定义的哈希，字段为任务名
+func (c *Controller) dynamicKey() string {
+	return namespacedKey(c.namespace, "dynamic")
+}
+
+// PutDynamicTask 添加或更新动态任务，并通知启用了动态任务的节点立即同步
+func (c *Controller) PutDynamicTask(ctx context.Context, task DynamicTask) error {
+	if err := task.validate(); err != nil {
+		return fmt.Errorf("failed to put dynamic task %s: %v", task.Name, err)
+	}
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to encode dynamic task %s: %v", task.Name, err)
+	}
+	if err := c.client.HSet(ctx, c.dynamicKey(), task.Name, data).Err(); err != nil {
+		return fmt.Errorf("failed to put dynamic task %s: %v", task.Name, err)
+	}
+	return c.notifyDynamic(ctx)
+}
+
+// RemoveDynamicTask 删除动态任务，各节点同步后移除其调度，不存在时返回 ErrDynamicTaskNotFound
+func (c *Controller) RemoveDynamicTask(ctx context.Context, name string) error {
+	n, err := c.client.HDel(ctx, c.dynamicKey(), name).Result()
+	if err != nil {
+		return fmt.Errorf("failed to remove dynamic task %s: %v", name, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s", ErrDynamicTaskNotFound, name)
+	}
+	return c.notifyDynamic(ctx)
+}
+
+// DynamicTasks 列出所有动态任务，按名称排序
+func (c *Controller) DynamicTasks(ctx context.Context) ([]DynamicTask, error) {
+	items, err := c.client.HGetAll(ctx, c.dynamicKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dynamic tasks: %v", err)
+	}
+
+	tasks := make([]DynamicTask, 0, len(items))
+	for name, item := range items {
+		var task DynamicTask
+		if err := json.Unmarshal([]byte(item), &task); err != nil {
+			return nil, fmt.Errorf("failed to decode dynamic task %s: %v", name, err)
+		}
+		task.Name = name
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+	return tasks, nil
+}
+
+// notifyDynamic 通过控制频道通知各节点同步动态任务
+func (c *Controller) notifyDynamic(ctx context.Context) error {
+	if err := c.publishMessage(ctx, controlMessage{Action: ControlSyncDynamic}); err != nil {
+		return fmt.Errorf("failed to notify dynamic task change: %v", err)
+	}
+	return nil
+}
+
+// startDynamicTasks 启动动态任务同步：启动时同步一次，之后定期同步，收到变更通知时立即同步
+func (dtm *DistributedTaskManager) startDynamicTasks() {
+	cfg := dtm.cfg.DynamicTasks
+	if !cfg.Enabled {
+		return
+	}
+	interval := cfg.SyncInterval
+	if interval <= 0 {
+		interval = defaultDynamicSyncInterval
+	}
+
+	dtm.syncDynamicTasks()
+	dtm.dynamicDone = make(chan struct{})
+	go func() {
+		defer close(dtm.dynamicDone)
+
+		for {
+			timer := dtm.clock.NewTimer(interval)
+			select {
+			case <-dtm.ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.Chan():
+			case <-dtm.dynamicSync:
+				timer.Stop()
+			}
+			dtm.syncDynamicTasks()
+		}
+	}()
+}
+
+// stopDynamicTasks 等待动态任务同步协程退出
+func (dtm *DistributedTaskManager) stopDynamicTasks() {
+	if dtm.dynamicDone != nil {
+		<-dtm.dynamicDone
+	}
+}
+
+// requestDynamicSync 请求同步协程立即同步一次，已有待处理的请求时忽略
+func (dtm *DistributedTaskManager) requestDynamicSync() {
+	select {
+	case dtm.dynamicSync <- struct{}{}:
+	default:
+	}
+}
+
+// syncDynamicTasks 对比 Redis 中的任务定义和当前节点上的动态任务，添加、更新或移除调度
+// 只由同步协程调用；引用了未注册处理函数或与代码中添加的任务重名的定义被跳过，下次同步时重试
+func (dtm *DistributedTaskManager) syncDynamicTasks() {
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+	defs, err := dtm.controller.DynamicTasks(ctx)
+	if err != nil {
+		dtm.log.Error("Failed to load dynamic tasks: ", err)
+		return
+	}
+
+	seen := make(map[string]bool, len(defs))
+	for _, def := range defs {
+		seen[def.Name] = true
+		old, exists := dtm.dynamic[def.Name]
+		if exists && old.equal(def) && dtm.hasTask(def.Name) {
+			continue
+		}
+		if err := dtm.applyDynamicTask(def, exists && dtm.hasTask(def.Name)); err != nil {
+			dtm.logRun(slog.LevelError, def.Name, "", "Failed to apply dynamic task", slog.Any("error", err))
+			continue
+		}
+		dtm.dynamic[def.Name] = def
+	}
+
+	for name := range dtm.dynamic {
+		if seen[name] {
+			continue
+		}
+		if err := dtm.RemoveTask(name); err != nil && !errors.Is(err, ErrTaskNotFound) {
+			dtm.logRun(slog.LevelError, name, "", "Failed to remove dynamic task", slog.Any("error", err))
+			continue
+		}
+		delete(dtm.dynamic, name)
+	}
+}
+
+// applyDynamicTask 按任务定义添加或更新任务
+func (dtm *DistributedTaskManager) applyDynamicTask(def DynamicTask, update bool) error {
+	if err := def.validate(); err != nil {
+		return err
+	}
+	handler, ok := dtm.jobHandler(def.Handler)
+	if !ok {
+		return fmt.Errorf("handler %s is not registered", def.Handler)
+	}
+	// 与延迟任务、工作队列共用处理函数，Job 的 Name 为处理函数名称，Payload 为任务定义中的参数
+	name, payload := def.Handler, []byte(def.Payload)
+	task := func(ctx context.Context) error {
+		return handler(ctx, Job{Name: name, Payload: payload, DueAt: dtm.clock.Now()})
+	}
+	opts := []TaskOption{}
+	if def.Group != "" {
+		opts = append(opts, WithGroup(def.Group))
+	}
+
+	if update {
+		return dtm.updateDistributedTask(def.Name, def.Cron, task, opts)
+	}
+	return dtm.addDistributedTask(def.Name, def.Cron, task, opts...)
+}
+
+// hasTask 当前节点是否注册了任务
+func (dtm *DistributedTaskManager) hasTask(name string) bool {
+	dtm.mu.RLock()
+	defer dtm.mu.RUnlock()
+	_, exists := dtm.tasks[name]
+	return exists
+}
@@ -0,0 +1,138 @@
+package redCorn
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 环境变量配置使用的变量名
+const (
+	EnvRedisAddrs         = "REDCORN_REDIS_ADDRS"           // Redis 地址，多个地址以逗号分隔
+	EnvRedisUsername      = "REDCORN_REDIS_USERNAME"        // Redis ACL 用户名
+	EnvRedisPassword      = "REDCORN_REDIS_PASSWORD"        // Redis 密码
+	EnvRedisPasswordFile  = "REDCORN_REDIS_PASSWORD_FILE"   // 保存 Redis 密码的文件，如挂载的 Secret，与 REDCORN_REDIS_PASSWORD 不能同时配置
+	EnvRedisDB            = "REDCORN_REDIS_DB"              // Redis 数据库编号
+	EnvRedisMasterName    = "REDCORN_REDIS_MASTER_NAME"     // 哨兵模式的主节点名称
+	EnvRedisMode          = "REDCORN_REDIS_MODE"            // Redis 部署模式：single、sentinel、cluster
+	EnvRedisTLS           = "REDCORN_REDIS_TLS"             // 启用 TLS
+	EnvRedisTLSCAFile     = "REDCORN_REDIS_TLS_CA_FILE"     // CA 证书文件
+	EnvRedisTLSCertFile   = "REDCORN_REDIS_TLS_CERT_FILE"   // 客户端证书文件
+	EnvRedisTLSKeyFile    = "REDCORN_REDIS_TLS_KEY_FILE"    // 客户端私钥文件
+	EnvRedisTLSServerName = "REDCORN_REDIS_TLS_SERVER_NAME" // 校验服务端证书使用的名称
+	EnvRedisTLSInsecure   = "REDCORN_REDIS_TLS_INSECURE"    // 跳过服务端证书校验，仅用于测试
+	EnvLockPrefix         = "REDCORN_LOCK_PREFIX"           // 分布式锁键的前缀
+	EnvLockExpiry         = "REDCORN_LOCK_EXPIRY"           // 分布式锁的过期时间，如 "30s"
+	EnvLogLevel           = "REDCORN_LOG_LEVEL"             // 最低日志级别：debug、info、warn、error
+	EnvNodeID             = "REDCORN_NODE_ID"               // 完整的节点标识
+	EnvInstanceID         = "REDCORN_INSTANCE_ID"           // 实例标识，如 Pod 名称
+	EnvNamespace          = "REDCORN_NAMESPACE"             // Redis 键的命名空间
+)
+
+// CfgFromEnv 从 REDCORN_* 环境变量构造配置，未设置的变量保留零值（即默认值），返回的配置可以继续在代码中修改
+// 变量的值无法解析时返回指出变量名的错误；在 Kubernetes 中可以直接通过 ConfigMap 和 Secret 配置
+//
+//	cfg, err := redCorn.CfgFromEnv()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	cfg.Logger = myLogger
+//	dtm, err := redCorn.NewDistributedTaskManager(cfg)
+func CfgFromEnv() (Cfg, error) {
+	var cfg Cfg
+	if addrs, ok := lookupEnv(EnvRedisAddrs); ok {
+		for _, addr := range strings.Split(addrs, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				cfg.RedisCfg.Addrs = append(cfg.RedisCfg.Addrs, addr)
+			}
+		}
+	}
+	cfg.RedisCfg.Username, _ = lookupEnv(EnvRedisUsername)
+	cfg.RedisCfg.Password, _ = lookupEnv(EnvRedisPassword)
+	if path, ok := lookupEnv(EnvRedisPasswordFile); ok {
+		if cfg.RedisCfg.Password != "" {
+			return Cfg{}, fmt.Errorf("%s and %s must not both be set", EnvRedisPassword, EnvRedisPasswordFile)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Cfg{}, fmt.Errorf("invalid %s: %v", EnvRedisPasswordFile, err)
+		}
+		cfg.RedisCfg.Password = strings.TrimRight(string(data), "\r\n")
+	}
+	if v, ok := lookupEnv(EnvRedisDB); ok {
+		db, err := strconv.Atoi(v)
+		if err != nil || db < 0 {
+			return Cfg{}, fmt.Errorf("invalid %s %q: must be a non-negative integer", EnvRedisDB, v)
+		}
+		cfg.RedisCfg.DB = db
+	}
+	cfg.RedisCfg.MasterName, _ = lookupEnv(EnvRedisMasterName)
+	if v, ok := lookupEnv(EnvRedisMode); ok {
+		mode := RedisMode(strings.ToLower(v))
+		switch mode {
+		case RedisModeSingle, RedisModeSentinel, RedisModeCluster:
+		default:
+			return Cfg{}, fmt.Errorf("invalid %s %q: must be %s, %s or %s", EnvRedisMode, v, RedisModeSingle, RedisModeSentinel, RedisModeCluster)
+		}
+		cfg.RedisMode = mode
+	}
+
+	var err error
+	if cfg.RedisTLS.Enabled, err = envBool(EnvRedisTLS); err != nil {
+		return Cfg{}, err
+	}
+	cfg.RedisTLS.CAFile, _ = lookupEnv(EnvRedisTLSCAFile)
+	cfg.RedisTLS.CertFile, _ = lookupEnv(EnvRedisTLSCertFile)
+	cfg.RedisTLS.KeyFile, _ = lookupEnv(EnvRedisTLSKeyFile)
+	cfg.RedisTLS.ServerName, _ = lookupEnv(EnvRedisTLSServerName)
+	if cfg.RedisTLS.InsecureSkipVerify, err = envBool(EnvRedisTLSInsecure); err != nil {
+		return Cfg{}, err
+	}
+	// 配置了证书文件即视为启用 TLS，避免只配置证书却以明文连接
+	if cfg.RedisTLS.CAFile != "" || cfg.RedisTLS.CertFile != "" || cfg.RedisTLS.KeyFile != "" || cfg.RedisTLS.ServerName != "" {
+		cfg.RedisTLS.Enabled = true
+	}
+
+	cfg.LockCfg.Prefix, _ = lookupEnv(EnvLockPrefix)
+	if v, ok := lookupEnv(EnvLockExpiry); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return Cfg{}, fmt.Errorf("invalid %s %q: must be a positive duration such as \"30s\"", EnvLockExpiry, v)
+		}
+		cfg.LockCfg.Expiry = d
+	}
+
+	if v, ok := lookupEnv(EnvLogLevel); ok {
+		level := LogLevel(strings.ToLower(v))
+		if _, err := level.slogLevel(); err != nil {
+			return Cfg{}, fmt.Errorf("invalid %s: %v", EnvLogLevel, err)
+		}
+		cfg.LogLevel = level
+	}
+
+	cfg.NodeID, _ = lookupEnv(EnvNodeID)
+	cfg.InstanceID, _ = lookupEnv(EnvInstanceID)
+	cfg.Namespace, _ = lookupEnv(EnvNamespace)
+	return cfg, nil
+}
+
+// lookupEnv 读取环境变量，去掉首尾空白，空值视为未设置
+func lookupEnv(name string) (string, bool) {
+	v := strings.TrimSpace(os.Getenv(name))
+	return v, v != ""
+}
+
+// envBool 读取布尔型环境变量，未设置时为 false
+func envBool(name string) (bool, error) {
+	v, ok := lookupEnv(name)
+	if !ok {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: must be true or false", name, v)
+	}
+	return b, nil
+}
@@ -0,0 +1,111 @@
+package redCorn
+
+import "time"
+
+// EventType 任务事件类型
+type EventType string
+
+const (
+	EventStarted   EventType = "started"   // 获取到锁，开始执行
+	EventSucceeded EventType = "succeeded" // 执行成功
+	EventFailed    EventType = "failed"    // 执行失败
+	EventSkipped   EventType = "skipped"   // 跳过执行
+)
+
+// TaskEvent 任务生命周期事件
+type TaskEvent struct {
+	Type       EventType     `json:"type"`
+	RunID      string        `json:"run_id"` // 本次执行的唯一标识，同一次执行的各个事件相同
+	Task       string        `json:"task"`
+	Node       string        `json:"node"`
+	Time       time.Time     `json:"time"`
+	Duration   time.Duration `json:"duration,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	SkipReason string        `json:"skip_reason,omitempty"`
+
+	Err error `json:"-"` // 原始错误，仅在进程内的钩子和订阅者中可用
+}
+
+// Hooks 任务生命周期钩子，均为可选
+// 钩子在任务所在的协程中同步调用，应尽快返回，耗时操作请自行异步处理
+type Hooks struct {
+	OnStart   func(event TaskEvent) // 获取到锁，开始执行
+	OnSuccess func(event TaskEvent) // 执行成功
+	OnError   func(event TaskEvent) // 执行失败，event.Err 为任务返回的错误
+	OnSkip    func(event TaskEvent) // 跳过执行，event.SkipReason 为跳过原因
+}
+
+// call 按事件类型调用钩子
+func (h Hooks) call(event TaskEvent) {
+	var hook func(TaskEvent)
+	switch event.Type {
+	case EventStarted:
+		hook = h.OnStart
+	case EventSucceeded:
+		hook = h.OnSuccess
+	case EventFailed:
+		hook = h.OnError
+	case EventSkipped:
+		hook = h.OnSkip
+	}
+	if hook != nil {
+		hook(event)
+	}
+}
+
+// Subscribe 订阅当前节点的任务事件，buffer 为通道缓冲大小
+// 订阅者消费过慢时事件会被丢弃，不会阻塞任务执行；调用返回的函数取消订阅
+func (dtm *DistributedTaskManager) Subscribe(buffer int) (<-chan TaskEvent, func()) {
+	ch := make(chan TaskEvent, buffer)
+
+	dtm.subMu.Lock()
+	dtm.subscribers[ch] = struct{}{}
+	dtm.subMu.Unlock()
+
+	return ch, func() {
+		dtm.subMu.Lock()
+		defer dtm.subMu.Unlock()
+		if _, ok := dtm.subscribers[ch]; ok {
+			delete(dtm.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// emit 分发任务事件：调用生命周期钩子、推送给订阅者并发布到 Redis
+func (dtm *DistributedTaskManager) emit(event TaskEvent) {
+	dtm.cfg.Hooks.call(event)
+	defer dtm.publishEvent(event)
+
+	dtm.subMu.RLock()
+	defer dtm.subMu.RUnlock()
+	for ch := range dtm.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// executionEvent 根据执行记录生成结束事件
+func executionEvent(runID string, rec *ExecutionRecord, now time.Time) TaskEvent {
+	event := TaskEvent{
+		RunID:      runID,
+		Task:       rec.Task,
+		Node:       rec.Node,
+		Time:       now,
+		Duration:   rec.Duration,
+		Error:      rec.Error,
+		SkipReason: rec.SkipReason,
+		Err:        rec.err,
+	}
+	switch rec.Status {
+	case ExecutionSuccess:
+		event.Type = EventSucceeded
+	case ExecutionFailed:
+		event.Type = EventFailed
+	default:
+		event.Type = EventSkipped
+	}
+	return event
+}
@@ -2,9 +2,6 @@ package main
 
 import (
 	"log"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/kzdgt/redCorn"
@@ -63,15 +60,9 @@ func main() {
 		log.Fatalf("Failed to add task: %v", err)
 	}
 
-	// 启动任务管理器
-	dtm.Start()
-
-	// 等待中断信号
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	<-sigChan
-
-	log.Println("Shutting down...")
-	dtm.Stop()
+	// 启动任务管理器，收到中断信号后优雅停止
+	if err := dtm.Run(); err != nil {
+		log.Printf("Shutdown: %v", err)
+	}
 	log.Println("Enhanced RedCorn example stopped")
 }
@@ -0,0 +1,48 @@
+package redCorn
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SkipReasonFencingError 生成 fencing token 失败时的跳过原因
+const SkipReasonFencingError = "failed to generate fencing token"
+
+// fencingTokenKey 上下文中保存 fencing token 的键
+type fencingTokenKey struct{}
+
+// withFencingToken 将 fencing token 写入上下文
+func withFencingToken(ctx context.Context, token int64) context.Context {
+	return context.WithValue(ctx, fencingTokenKey{}, token)
+}
+
+// FencingTokenFromContext 从任务上下文中取出本次执行的 fencing token，任务未配置 WithFencingToken 时返回 false
+// 同一任务的 token 在集群中单调递增，下游存储记录已见过的最大 token 并拒绝更小的写入，
+// 即可挡住锁已过期、但仍在执行的旧执行
+func FencingTokenFromContext(ctx context.Context) (int64, bool) {
+	token, ok := ctx.Value(fencingTokenKey{}).(int64)
+	return token, ok
+}
+
+// WithFencingToken 获取锁后为本次执行生成 fencing token，通过 FencingTokenFromContext 在任务中读取
+// token 由 Redis 计数器 <Namespace>:fence:<任务名> 自增生成；领导者模式下不获取任务锁，不生成 token
+func WithFencingToken() TaskOption {
+	return func(o *taskOptions) {
+		o.fencing = true
+	}
+}
+
+// nextFencingToken 生成本次执行的 fencing token，失败时在执行记录中写入跳过原因
+// 任务依赖 token 保护下游写入，拿不到 token 时不执行
+func (dtm *DistributedTaskManager) nextFencingToken(ctx context.Context, t *distributedTask, rec *ExecutionRecord) bool {
+	token, err := dtm.redisClient.Incr(ctx, dtm.key("fence", t.name)).Result()
+	if err != nil {
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonFencingError
+		rec.setError(err)
+		dtm.logRun(slog.LevelError, t.name, rec.RunID, "Failed to generate fencing token, skipping execution", slog.Any("error", err))
+		return false
+	}
+	rec.FencingToken = token
+	return true
+}
@@ -0,0 +1,99 @@
+package redCorn
+
+import (
+	"context"
+	"sort"
+)
+
+// WithGroup 将任务归入分组，可通过 PauseGroup/ResumeGroup 按组暂停和恢复
+func WithGroup(group string) TaskOption {
+	return func(o *taskOptions) {
+		o.group = group
+	}
+}
+
+// TaskGroup 任务分组，分组内注册的任务共享分组选项
+type TaskGroup struct {
+	scheduler *TaskScheduler
+	name      string
+	opts      []TaskOption
+}
+
+// Group 返回名为 name 的任务分组，opts 作用于分组内注册的所有任务，任务自身的选项优先
+func (ts *TaskScheduler) Group(name string, opts ...TaskOption) *TaskGroup {
+	return &TaskGroup{scheduler: ts, name: name, opts: opts}
+}
+
+// options 合并分组选项和任务选项
+func (g *TaskGroup) options(opts []TaskOption) []TaskOption {
+	merged := make([]TaskOption, 0, len(g.opts)+len(opts)+1)
+	merged = append(merged, WithGroup(g.name))
+	merged = append(merged, g.opts...)
+	return append(merged, opts...)
+}
+
+// Register 在分组内注册任务
+func (g *TaskGroup) Register(name string, cron string, task func(), opts ...TaskOption) error {
+	return g.scheduler.Register(name, cron, task, g.options(opts)...)
+}
+
+// RegisterCtx 在分组内注册可感知上下文的任务
+func (g *TaskGroup) RegisterCtx(name string, cron string, task func(ctx context.Context), opts ...TaskOption) error {
+	return g.scheduler.RegisterCtx(name, cron, task, g.options(opts)...)
+}
+
+// RegisterE 在分组内注册返回错误的任务
+func (g *TaskGroup) RegisterE(name string, cron string, task func(ctx context.Context) error, opts ...TaskOption) error {
+	return g.scheduler.RegisterE(name, cron, task, g.options(opts)...)
+}
+
+// GroupTasks 返回分组内的任务名称，按名称排序
+func (dtm *DistributedTaskManager) GroupTasks(group string) []string {
+	dtm.mu.RLock()
+	defer dtm.mu.RUnlock()
+
+	var names []string
+	for _, t := range dtm.tasks {
+		if t.opts.group == group {
+			names = append(names, t.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PauseGroup 暂停分组内的所有任务，之后加入该分组的任务同样处于暂停状态
+func (dtm *DistributedTaskManager) PauseGroup(group string) {
+	dtm.setGroupPaused(group, true)
+}
+
+// ResumeGroup 恢复分组内的所有任务
+func (dtm *DistributedTaskManager) ResumeGroup(group string) {
+	dtm.setGroupPaused(group, false)
+}
+
+// setGroupPaused 设置分组内所有任务的暂停状态
+func (dtm *DistributedTaskManager) setGroupPaused(group string, paused bool) {
+	dtm.mu.Lock()
+	if paused {
+		dtm.pausedGroups[group] = true
+	} else {
+		delete(dtm.pausedGroups, group)
+	}
+	var tasks []*distributedTask
+	for _, t := range dtm.tasks {
+		if t.opts.group == group {
+			tasks = append(tasks, t)
+		}
+	}
+	dtm.mu.Unlock()
+
+	for _, t := range tasks {
+		t.paused.Store(paused)
+	}
+	if paused {
+		dtm.log.Info("Group ", group, ": paused ", len(tasks), " tasks")
+	} else {
+		dtm.log.Info("Group ", group, ": resumed ", len(tasks), " tasks")
+	}
+}
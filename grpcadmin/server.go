@@ -0,0 +1,221 @@
+// Package grpcadmin 提供 RedCorn 的 gRPC 管理接口，与内嵌 HTTP 管理接口对应：
+// 列出任务、查看执行历史、触发、暂停、恢复任务，以及以流的方式订阅任务事件。
+// 接口定义见 adminpb/admin.proto。
+package grpcadmin
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"github.com/kzdgt/redCorn"
+	"github.com/kzdgt/redCorn/grpcadmin/adminpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// eventBuffer 事件流的缓冲大小
+const eventBuffer = 64
+
+// Server AdminService 的实现
+type Server struct {
+	adminpb.UnimplementedAdminServiceServer
+	dtm *redCorn.DistributedTaskManager
+}
+
+// NewServer 创建 AdminService 的实现，可注册到已有的 grpc.Server 上
+func NewServer(dtm *redCorn.DistributedTaskManager) *Server {
+	return &Server{dtm: dtm}
+}
+
+// NewGRPCServer 创建注册了 AdminService 的 grpc.Server
+// authToken 非空时，请求需在 metadata 中携带 "authorization: Bearer <token>"
+func NewGRPCServer(dtm *redCorn.DistributedTaskManager, authToken string, opts ...grpc.ServerOption) *grpc.Server {
+	if authToken != "" {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(unaryAuth(authToken)),
+			grpc.ChainStreamInterceptor(streamAuth(authToken)),
+		)
+	}
+	s := grpc.NewServer(opts...)
+	adminpb.RegisterAdminServiceServer(s, NewServer(dtm))
+	return s
+}
+
+// ListTasks 列出集群中注册的任务
+func (s *Server) ListTasks(ctx context.Context, _ *adminpb.ListTasksRequest) (*adminpb.ListTasksResponse, error) {
+	defs, err := s.dtm.GetController().Tasks(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &adminpb.ListTasksResponse{Tasks: make([]*adminpb.Task, 0, len(defs))}
+	for _, def := range defs {
+		resp.Tasks = append(resp.Tasks, s.task(ctx, def.Name, def.Spec))
+	}
+	return resp, nil
+}
+
+// GetTask 查看任务详情及集群运行状态
+func (s *Server) GetTask(ctx context.Context, req *adminpb.TaskRequest) (*adminpb.Task, error) {
+	defs, err := s.dtm.GetController().Tasks(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	for _, def := range defs {
+		if def.Name == req.GetName() {
+			return s.task(ctx, def.Name, def.Spec), nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "task %s not found", req.GetName())
+}
+
+// GetHistory 查看任务的执行历史
+func (s *Server) GetHistory(ctx context.Context, req *adminpb.GetHistoryRequest) (*adminpb.GetHistoryResponse, error) {
+	records, err := s.dtm.GetController().History(ctx, req.GetName(), req.GetLimit())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &adminpb.GetHistoryResponse{Records: make([]*adminpb.ExecutionRecord, 0, len(records))}
+	for _, rec := range records {
+		resp.Records = append(resp.Records, &adminpb.ExecutionRecord{
+			Task:       rec.Task,
+			Node:       rec.Node,
+			StartedAt:  timestamppb.New(rec.StartedAt),
+			Duration:   durationpb.New(rec.Duration),
+			Status:     string(rec.Status),
+			Error:      rec.Error,
+			SkipReason: rec.SkipReason,
+			RunId:      rec.RunID,
+		})
+	}
+	return resp, nil
+}
+
+// TriggerTask 立即触发一次执行
+func (s *Server) TriggerTask(_ context.Context, req *adminpb.TaskRequest) (*adminpb.TaskResponse, error) {
+	if err := s.dtm.TriggerTask(req.GetName()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &adminpb.TaskResponse{Name: req.GetName()}, nil
+}
+
+// PauseTask 暂停任务，并通知集群中所有节点
+func (s *Server) PauseTask(_ context.Context, req *adminpb.TaskRequest) (*adminpb.TaskResponse, error) {
+	if err := s.dtm.BroadcastPause(req.GetName()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &adminpb.TaskResponse{Name: req.GetName()}, nil
+}
+
+// ResumeTask 恢复任务，并通知集群中所有节点
+func (s *Server) ResumeTask(_ context.Context, req *adminpb.TaskRequest) (*adminpb.TaskResponse, error) {
+	if err := s.dtm.BroadcastResume(req.GetName()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &adminpb.TaskResponse{Name: req.GetName()}, nil
+}
+
+// StreamEvents 订阅服务端节点的任务事件，直到客户端断开或管理器停止
+func (s *Server) StreamEvents(req *adminpb.StreamEventsRequest, stream adminpb.AdminService_StreamEventsServer) error {
+	filter := make(map[string]bool, len(req.GetTasks()))
+	for _, name := range req.GetTasks() {
+		filter[name] = true
+	}
+
+	events, cancel := s.dtm.Subscribe(eventBuffer)
+	defer cancel()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.dtm.GetContext().Done():
+			return status.Error(codes.Unavailable, "task manager stopped")
+		case event := <-events:
+			if len(filter) > 0 && !filter[event.Task] {
+				continue
+			}
+			err := stream.Send(&adminpb.TaskEvent{
+				Type:       string(event.Type),
+				Task:       event.Task,
+				Node:       event.Node,
+				Time:       timestamppb.New(event.Time),
+				Duration:   durationpb.New(event.Duration),
+				Error:      event.Error,
+				SkipReason: event.SkipReason,
+				RunId:      event.RunID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// task 汇总任务的集群运行状态和服务端节点上的暂停状态
+func (s *Server) task(ctx context.Context, name, spec string) *adminpb.Task {
+	task := &adminpb.Task{Name: name, Spec: spec}
+	task.Description, _ = redCorn.DescribeSpec(spec)
+	task.Paused, _ = s.dtm.IsPaused(name)
+
+	st, err := s.dtm.GetController().Status(ctx, name)
+	if err != nil {
+		return task
+	}
+	task.LastNode = st.LastNode
+	task.LastStatus = string(st.LastStatus)
+	task.LastDuration = durationpb.New(st.LastDuration)
+	if !st.LastRun.IsZero() {
+		task.LastRun = timestamppb.New(st.LastRun)
+	}
+	if !st.NextRun.IsZero() {
+		task.NextRun = timestamppb.New(st.NextRun)
+	}
+	return task
+}
+
+// toStatus 将错误转换为 gRPC 状态
+func toStatus(err error) error {
+	if errors.Is(err, redCorn.ErrTaskNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// authorize 校验 metadata 中的访问令牌
+func authorize(ctx context.Context, token string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		if subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(v, "Bearer ")), []byte(token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "unauthorized")
+}
+
+// unaryAuth 一元调用的鉴权拦截器
+func unaryAuth(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authorize(ctx, token); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// streamAuth 流式调用的鉴权拦截器
+func streamAuth(token string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), token); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,45 @@
+package redCorn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Handle 注册名为 name 的任务的类型化处理函数，任务参数以 JSON 保存，执行前解析为 T；参数为空时传入 T 的零值。其他规则同 HandleJob
+// Go 的方法不能带类型参数，因此以函数的形式提供，任务管理器作为第一个参数
+//
+//	redCorn.Handle(dtm, "send-email", func(ctx context.Context, job EmailJob) error { ... })
+func Handle[T any](dtm *DistributedTaskManager, name string, handler func(ctx context.Context, payload T) error) error {
+	if handler == nil {
+		return fmt.Errorf("failed to register job handler %s: handler is nil", name)
+	}
+	return dtm.HandleJob(name, func(ctx context.Context, job Job) error {
+		var payload T
+		if len(job.Payload) > 0 {
+			if err := json.Unmarshal(job.Payload, &payload); err != nil {
+				return fmt.Errorf("failed to decode payload of job %s: %v", job.Name, err)
+			}
+		}
+		return handler(ctx, payload)
+	})
+}
+
+// Enqueue 将 payload 编码为 JSON 加入工作队列，由集群中某一个注册了 name 处理函数的节点尽快执行，见 PushJob
+func (dtm *DistributedTaskManager) Enqueue(name string, payload interface{}) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode payload of job %s: %v", name, err)
+	}
+	return dtm.PushJob(name, data)
+}
+
+// EnqueueAt 将 payload 编码为 JSON 加入延迟任务队列，在 at 执行一次，见 EnqueueJob
+func (dtm *DistributedTaskManager) EnqueueAt(name string, at time.Time, payload interface{}) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode payload of job %s: %v", name, err)
+	}
+	return dtm.EnqueueJob(name, at, data)
+}
@@ -0,0 +1,100 @@
+package redCorn
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+)
+
+// redisOpTimeout 内部 Redis 读写操作的超时时间
+const redisOpTimeout = 5 * time.Second
+
+// defaultHistoryMaxLen 每个任务默认保留的执行记录数
+const defaultHistoryMaxLen = 100
+
+// HistoryCfg 执行历史配置
+type HistoryCfg struct {
+	MaxLen   int64 // 每个任务保留的最大记录数，默认100
+	Disabled bool  // 禁用执行历史记录
+}
+
+// ExecutionStatus 任务执行结果
+type ExecutionStatus string
+
+const (
+	ExecutionSuccess ExecutionStatus = "success" // 执行成功
+	ExecutionFailed  ExecutionStatus = "failed"  // 执行失败
+	ExecutionSkipped ExecutionStatus = "skipped" // 跳过执行
+)
+
+// 跳过原因
+const (
+	SkipReasonLockHeld    = "lock held by another execution"
+	SkipReasonLockError   = "failed to acquire lock"
+	SkipReasonNotLeader   = "node is not leader"
+	SkipReasonTickDone    = "tick already executed"
+	SkipReasonNotAssigned = "task assigned to another node"
+)
+
+// ExecutionRecord 一次任务执行的记录
+type ExecutionRecord struct {
+	RunID      string          `json:"run_id,omitempty"` // 本次执行的唯一标识
+	Task       string          `json:"task"`
+	Node       string          `json:"node"`
+	StartedAt  time.Time       `json:"started_at"`
+	Duration   time.Duration   `json:"duration"`
+	Status     ExecutionStatus `json:"status"`
+	Error      string          `json:"error,omitempty"`
+	SkipReason string          `json:"skip_reason,omitempty"`
+
+	FencingToken int64 `json:"fencing_token,omitempty"` // 配置了 WithFencingToken 时本次执行的 fencing token
+
+	err error // 原始错误，供钩子使用
+}
+
+// setError 记录执行错误
+func (rec *ExecutionRecord) setError(err error) {
+	rec.err = err
+	rec.Error = err.Error()
+}
+
+// historyKey 任务执行历史在 Redis 中的键
+func (dtm *DistributedTaskManager) historyKey(taskName string) string {
+	return dtm.key("history", taskName)
+}
+
+// recordHistory 将执行记录写入 Redis 中按任务划分的定长列表，最新的记录在最前
+func (dtm *DistributedTaskManager) recordHistory(rec *ExecutionRecord) {
+	if dtm.cfg.History.Disabled {
+		return
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		dtm.logRun(slog.LevelError, rec.Task, rec.RunID, "Failed to encode execution record", slog.Any("error", err))
+		return
+	}
+
+	maxLen := dtm.cfg.History.MaxLen
+	if maxLen <= 0 {
+		maxLen = defaultHistoryMaxLen
+	}
+
+	// 管理器停止时仍需写入最后的记录，因此不使用 dtm.ctx
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	key := dtm.historyKey(rec.Task)
+	pipe := dtm.redisClient.TxPipeline()
+	pipe.LPush(ctx, key, data)
+	pipe.LTrim(ctx, key, 0, maxLen-1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		dtm.logRun(slog.LevelError, rec.Task, rec.RunID, "Failed to record execution history", slog.Any("error", err))
+	}
+}
+
+// GetHistory 获取任务在整个集群中的执行历史，最新的记录在最前，limit<=0 时返回全部保留的记录
+func (dtm *DistributedTaskManager) GetHistory(taskName string, limit int64) ([]ExecutionRecord, error) {
+	return dtm.controller.History(dtm.ctx, taskName, limit)
+}
@@ -0,0 +1,66 @@
+package redCorn
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+)
+
+// SkipReasonStopped 等待期间管理器已停止时的跳过原因
+const SkipReasonStopped = "manager stopped"
+
+// WithJitter 获取锁后随机等待 [0, maxDelay) 再执行，避免所有任务在同一时刻集中访问下游系统
+// 等待期间仍持有锁，等待结束后会续期锁，maxDelay 必须小于锁的过期时间，否则注册任务时返回错误；手动触发不等待
+func WithJitter(maxDelay time.Duration) TaskOption {
+	return func(o *taskOptions) {
+		o.jitter = maxDelay
+	}
+}
+
+// validateJitter 检查随机延迟是否小于锁的过期时间，等待期间不续期锁，延迟达到过期时间时锁可能在执行前失效
+func (dtm *DistributedTaskManager) validateJitter(o taskOptions) error {
+	if o.jitter <= 0 {
+		return nil
+	}
+	expiry := dtm.cfg.LockCfg.Expiry
+	if o.lockExpiry > 0 {
+		expiry = o.lockExpiry
+	}
+	if o.jitter >= expiry {
+		return fmt.Errorf("invalid jitter %s: must be less than lock expiry %s", o.jitter, expiry)
+	}
+	return nil
+}
+
+// waitJitter 按任务的抖动配置随机等待，返回 false 表示本次执行应跳过
+func (dtm *DistributedTaskManager) waitJitter(t *distributedTask, mutex *taskMutex, rec *ExecutionRecord) bool {
+	delay := time.Duration(rand.Int63n(int64(t.opts.jitter)))
+	dtm.logRun(slog.LevelDebug, t.name, rec.RunID, "Delaying execution", slog.Duration("delay", delay))
+
+	timer := dtm.clock.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-dtm.stopping:
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonStopped
+		return false
+	case <-timer.Chan():
+	}
+
+	if mutex == nil {
+		return true
+	}
+	if ok, err := mutex.extend(dtm.ctx); !ok || err != nil {
+		if err == nil {
+			err = errors.New("lock no longer held")
+		}
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonLockError
+		rec.setError(fmt.Errorf("failed to extend lock after jitter delay: %v", err))
+		dtm.logRun(slog.LevelError, t.name, rec.RunID, "Failed to extend lock after jitter delay, skipping execution", slog.Any("error", err))
+		return false
+	}
+	return true
+}
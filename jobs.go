@@ -0,0 +1,287 @@
+package redCorn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	goredislib "github.com/go-redis/redis/v8"
+)
+
+const (
+	defaultJobPollInterval = time.Second // 默认的延迟任务轮询间隔
+	defaultJobBatchSize    = 100         // 默认每次轮询每种任务最多取出的数量
+)
+
+// ErrJobNotFound 延迟任务不存在（已执行、已取消或从未加入队列）
+var ErrJobNotFound = errors.New("job not found")
+
+// Job 延迟任务队列中的一个任务，按 Name 交给注册了同名处理函数的节点执行
+type Job struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Payload    []byte    `json:"payload,omitempty"` // 任务参数，由处理函数解析
+	DueAt      time.Time `json:"due_at"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	EnqueuedBy string    `json:"enqueued_by,omitempty"` // 加入队列的节点或用户
+}
+
+// JobHandler 延迟任务的处理函数
+type JobHandler func(ctx context.Context, job Job) error
+
+// JobQueueCfg 延迟任务队列配置
+type JobQueueCfg struct {
+	PollInterval time.Duration // 轮询到期任务的间隔，默认1秒
+	BatchSize    int           // 每次轮询每种任务最多取出的数量，默认100
+}
+
+// claimJobsScript 取出并删除已到期的任务，删除是原子的，同一个任务只会被一个节点取出
+var claimJobsScript = goredislib.NewScript(`
+local jobs = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[2])
+if #jobs > 0 then
+	redis.call("ZREM", KEYS[1], unpack(jobs))
+end
+return jobs
+`)
+
+// jobsKey 保存名为 name 的延迟任务的有序集合，分数为到期时间的毫秒时间戳
+func (c *Controller) jobsKey(name string) string {
+	return namespacedKey(c.namespace, "delayed", name)
+}
+
+// EnqueueJob 将任务加入延迟任务队列，到期后由注册了 job.Name 处理函数的某一个节点执行一次
+// job.ID 为空时自动生成，DueAt 为零值表示立即到期；返回加入队列的任务
+func (c *Controller) EnqueueJob(ctx context.Context, job Job) (Job, error) {
+	if job.Name == "" {
+		return Job{}, errors.New("failed to enqueue job: name is required")
+	}
+	if job.ID == "" {
+		job.ID = newRunID()
+	}
+	job.EnqueuedAt = c.clock.Now()
+	if job.DueAt.IsZero() {
+		job.DueAt = job.EnqueuedAt
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return Job{}, fmt.Errorf("failed to encode job: %v", err)
+	}
+	member := &goredislib.Z{Score: float64(job.DueAt.UnixMilli()), Member: data}
+	if err := c.client.ZAdd(ctx, c.jobsKey(job.Name), member).Err(); err != nil {
+		return Job{}, fmt.Errorf("failed to enqueue job %s: %v", job.Name, err)
+	}
+	return job, nil
+}
+
+// DelayedJobs 列出队列中尚未执行的名为 name 的延迟任务，按到期时间排序
+func (c *Controller) DelayedJobs(ctx context.Context, name string) ([]Job, error) {
+	items, err := c.client.ZRange(ctx, c.jobsKey(name), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs %s: %v", name, err)
+	}
+
+	jobs := make([]Job, 0, len(items))
+	for _, item := range items {
+		var job Job
+		if err := json.Unmarshal([]byte(item), &job); err != nil {
+			return nil, fmt.Errorf("failed to decode job: %v", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// CancelJob 取消尚未执行的延迟任务，任务已被取出执行或不存在时返回 ErrJobNotFound
+// 需要遍历同名的所有任务，适用于队列长度适中的场景
+func (c *Controller) CancelJob(ctx context.Context, name, id string) error {
+	key := c.jobsKey(name)
+	items, err := c.client.ZRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return fmt.Errorf("failed to list jobs %s: %v", name, err)
+	}
+
+	for _, item := range items {
+		var job Job
+		if err := json.Unmarshal([]byte(item), &job); err != nil || job.ID != id {
+			continue
+		}
+		n, err := c.client.ZRem(ctx, key, item).Result()
+		if err != nil {
+			return fmt.Errorf("failed to cancel job %s: %v", id, err)
+		}
+		if n == 0 {
+			break
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrJobNotFound, id)
+}
+
+// claimJobs 取出名为 name 的已到期任务，最多 limit 个
+func (c *Controller) claimJobs(ctx context.Context, name string, now time.Time, limit int) ([]Job, error) {
+	items, err := claimJobsScript.Run(ctx, c.client, []string{c.jobsKey(name)}, now.UnixMilli(), limit).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim jobs %s: %v", name, err)
+	}
+
+	jobs := make([]Job, 0, len(items))
+	for _, item := range items {
+		var job Job
+		if err := json.Unmarshal([]byte(item), &job); err != nil {
+			return jobs, fmt.Errorf("failed to decode job: %v", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// HandleJob 注册名为 name 的任务的处理函数，启动后当前节点轮询并执行该名称的到期延迟任务，同时消费同名的工作队列
+// 同一个名称在一个节点上只能注册一次，集群中注册了同名处理函数的节点共同消费队列
+func (dtm *DistributedTaskManager) HandleJob(name string, handler JobHandler) error {
+	dtm.jobsMu.Lock()
+	defer dtm.jobsMu.Unlock()
+	if _, exists := dtm.jobHandlers[name]; exists {
+		return fmt.Errorf("job handler %s already exists", name)
+	}
+	dtm.jobHandlers[name] = handler
+	if dtm.consuming {
+		dtm.startConsumer(name, handler)
+	}
+	dtm.log.Info("Registered job handler: ", name)
+	return nil
+}
+
+// jobHandler 按名称查找处理函数
+func (dtm *DistributedTaskManager) jobHandler(name string) (JobHandler, bool) {
+	dtm.jobsMu.RLock()
+	defer dtm.jobsMu.RUnlock()
+	h, ok := dtm.jobHandlers[name]
+	return h, ok
+}
+
+// EnqueueJob 将任务加入延迟任务队列，在 dueAt 由集群中某一个注册了 name 处理函数的节点执行一次，返回任务标识
+func (dtm *DistributedTaskManager) EnqueueJob(name string, dueAt time.Time, payload []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+	job, err := dtm.controller.EnqueueJob(ctx, Job{Name: name, Payload: payload, DueAt: dueAt, EnqueuedBy: dtm.nodeID})
+	if err != nil {
+		return "", err
+	}
+	dtm.logRun(slog.LevelDebug, name, job.ID, "Job enqueued", slog.Time("due_at", job.DueAt))
+	return job.ID, nil
+}
+
+// EnqueueJobAfter 将任务加入延迟任务队列，在 d 之后执行，见 EnqueueJob
+func (dtm *DistributedTaskManager) EnqueueJobAfter(name string, d time.Duration, payload []byte) (string, error) {
+	return dtm.EnqueueJob(name, dtm.clock.Now().Add(d), payload)
+}
+
+// startJobPoller 启动延迟任务轮询，定期取出当前节点注册了处理函数的到期任务并执行
+func (dtm *DistributedTaskManager) startJobPoller() {
+	interval := dtm.cfg.JobQueue.PollInterval
+	if interval <= 0 {
+		interval = defaultJobPollInterval
+	}
+
+	dtm.jobPollerDone = make(chan struct{})
+	go func() {
+		defer close(dtm.jobPollerDone)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-dtm.stopping:
+				return
+			case <-ticker.C:
+				dtm.pollJobs()
+			}
+		}
+	}()
+}
+
+// stopJobPoller 等待延迟任务轮询协程退出
+func (dtm *DistributedTaskManager) stopJobPoller() {
+	if dtm.jobPollerDone != nil {
+		<-dtm.jobPollerDone
+	}
+}
+
+// pollJobs 取出并执行一轮到期任务；取出前先登记执行，停止过程中不会再取出任务
+func (dtm *DistributedTaskManager) pollJobs() {
+	dtm.jobsMu.RLock()
+	handlers := make(map[string]JobHandler, len(dtm.jobHandlers))
+	for name, handler := range dtm.jobHandlers {
+		handlers[name] = handler
+	}
+	dtm.jobsMu.RUnlock()
+	if len(handlers) == 0 {
+		return
+	}
+
+	if !dtm.beginExecution() {
+		return
+	}
+	defer dtm.endExecution()
+
+	limit := dtm.cfg.JobQueue.BatchSize
+	if limit <= 0 {
+		limit = defaultJobBatchSize
+	}
+	for name, handler := range handlers {
+		ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+		jobs, err := dtm.controller.claimJobs(ctx, name, dtm.clock.Now(), limit)
+		cancel()
+		if err != nil {
+			dtm.logRun(slog.LevelError, name, "", "Failed to claim jobs", slog.Any("error", err))
+		}
+		for _, job := range jobs {
+			dtm.joinExecution()
+			go func(job Job, handler JobHandler) {
+				defer dtm.endExecution()
+				_ = dtm.runJob(job, handler)
+			}(job, handler)
+		}
+	}
+}
+
+// runJob 执行一个已取出的任务，任务标识作为执行标识写入上下文，返回处理函数的错误
+func (dtm *DistributedTaskManager) runJob(job Job, handler JobHandler) error {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if dtm.cfg.TaskTimeout > 0 {
+		ctx, cancel = context.WithTimeout(dtm.ctx, dtm.cfg.TaskTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(dtm.ctx)
+	}
+	defer cancel()
+	ctx = withRunID(ctx, job.ID)
+
+	dtm.logRun(slog.LevelInfo, job.Name, job.ID, "Job claimed, starting execution", slog.Duration("delay", dtm.clock.Since(job.DueAt)))
+	startTime := dtm.clock.Now()
+	err := dtm.callJob(ctx, job, handler)
+	duration := dtm.clock.Since(startTime)
+	if err != nil {
+		dtm.logRun(slog.LevelError, job.Name, job.ID, "Job failed", slog.Duration("duration", duration), slog.Any("error", err))
+		return err
+	}
+	dtm.logRun(slog.LevelInfo, job.Name, job.ID, "Job completed", slog.Duration("duration", duration))
+	return nil
+}
+
+// callJob 调用处理函数，将 panic 转换为 *PanicError
+func (dtm *DistributedTaskManager) callJob(ctx context.Context, job Job, handler JobHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			pe := &PanicError{Value: r, Stack: debug.Stack()}
+			dtm.logRun(slog.LevelError, job.Name, job.ID, "Job panicked", slog.Any("error", pe), slog.String("stack", string(pe.Stack)))
+			err = pe
+		}
+	}()
+	return handler(ctx, job)
+}
@@ -0,0 +1,126 @@
+package redCorn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	goredislib "github.com/go-redis/redis/v8"
+)
+
+// 集群暂停的跳过原因
+const (
+	SkipReasonClusterPaused     = "cluster paused"
+	SkipReasonClusterPauseError = "failed to check cluster pause"
+)
+
+// 集群暂停的审计操作
+const (
+	AuditPauseCluster  = "pause_cluster"
+	AuditResumeCluster = "resume_cluster"
+)
+
+// ClusterPause 集群暂停标记，保存在 <Namespace>:paused 中，存在期间所有节点跳过任务执行
+type ClusterPause struct {
+	Reason   string    `json:"reason,omitempty"`
+	PausedBy string    `json:"paused_by"` // 设置标记的节点或用户
+	PausedAt time.Time `json:"paused_at"`
+}
+
+// clusterPauseKey 集群暂停标记的键
+func (c *Controller) clusterPauseKey() string {
+	return namespacedKey(c.namespace, "paused")
+}
+
+// PauseCluster 设置集群暂停标记，所有节点立即跳过之后的任务执行（包括手动触发），直到调用 ResumeCluster
+// 正在执行的任务不受影响；operator 写入标记和审计记录，集群已暂停时更新原因
+func (c *Controller) PauseCluster(ctx context.Context, reason, operator string) error {
+	data, err := json.Marshal(ClusterPause{Reason: reason, PausedBy: operator, PausedAt: c.clock.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to encode cluster pause: %v", err)
+	}
+	if err := c.client.Set(ctx, c.clusterPauseKey(), data, 0).Err(); err != nil {
+		return fmt.Errorf("failed to pause cluster: %v", err)
+	}
+	return c.recordAudit(ctx, AuditEntry{Time: c.clock.Now(), Action: AuditPauseCluster, Reason: reason, Operator: operator})
+}
+
+// ResumeCluster 清除集群暂停标记，集群未暂停时不做任何操作
+func (c *Controller) ResumeCluster(ctx context.Context, operator string) error {
+	n, err := c.client.Del(ctx, c.clusterPauseKey()).Result()
+	if err != nil {
+		return fmt.Errorf("failed to resume cluster: %v", err)
+	}
+	if n == 0 {
+		return nil
+	}
+	return c.recordAudit(ctx, AuditEntry{Time: c.clock.Now(), Action: AuditResumeCluster, Operator: operator})
+}
+
+// ClusterPaused 查询集群暂停标记，未暂停时返回 nil
+func (c *Controller) ClusterPaused(ctx context.Context) (*ClusterPause, error) {
+	data, err := c.client.Get(ctx, c.clusterPauseKey()).Bytes()
+	if errors.Is(err, goredislib.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check cluster pause: %v", err)
+	}
+	var pause ClusterPause
+	if err := json.Unmarshal(data, &pause); err != nil {
+		return nil, fmt.Errorf("failed to decode cluster pause: %v", err)
+	}
+	return &pause, nil
+}
+
+// PauseCluster 设置集群暂停标记，见 Controller.PauseCluster
+func (dtm *DistributedTaskManager) PauseCluster(reason string) error {
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+	if err := dtm.controller.PauseCluster(ctx, reason, dtm.nodeID); err != nil {
+		return err
+	}
+	dtm.log.Warn("Cluster paused: ", reason)
+	return nil
+}
+
+// ResumeCluster 清除集群暂停标记，见 Controller.ResumeCluster
+func (dtm *DistributedTaskManager) ResumeCluster() error {
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+	if err := dtm.controller.ResumeCluster(ctx, dtm.nodeID); err != nil {
+		return err
+	}
+	dtm.log.Info("Cluster resumed")
+	return nil
+}
+
+// ClusterPaused 查询集群暂停标记，未暂停时返回 nil
+func (dtm *DistributedTaskManager) ClusterPaused() (*ClusterPause, error) {
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+	return dtm.controller.ClusterPaused(ctx)
+}
+
+// checkClusterPause 检查集群是否已暂停，已暂停或检查出错时在执行记录中写入跳过原因
+func (dtm *DistributedTaskManager) checkClusterPause(ctx context.Context, t *distributedTask, rec *ExecutionRecord) bool {
+	pause, err := dtm.controller.ClusterPaused(ctx)
+	if err != nil {
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonClusterPauseError
+		rec.setError(err)
+		dtm.logRun(slog.LevelError, t.name, rec.RunID, "Failed to check cluster pause, skipping execution", slog.Any("error", err))
+		return false
+	}
+	if pause != nil {
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonClusterPaused
+		dtm.logRun(slog.LevelWarn, t.name, rec.RunID, "Cluster paused, skipping execution",
+			slog.String("reason", pause.Reason), slog.String("paused_by", pause.PausedBy))
+		return false
+	}
+	return true
+}
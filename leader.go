@@ -0,0 +1,167 @@
+package redCorn
+
+import (
+	"context"
+	"time"
+
+	goredislib "github.com/go-redis/redis/v8"
+)
+
+// SchedulingMode 调度模式
+type SchedulingMode string
+
+const (
+	// SchedulingLock 所有节点都运行定时调度，每次执行时争抢分布式锁（默认）
+	SchedulingLock SchedulingMode = "lock"
+	// SchedulingLeader 节点通过可续约的 Redis 租约选举领导者，只有领导者运行定时调度，执行时不再争抢锁
+	SchedulingLeader SchedulingMode = "leader"
+	// SchedulingHash 所有节点都运行定时调度，每个任务按一致性哈希分配给一个在线节点，只有该节点获取锁并执行
+	SchedulingHash SchedulingMode = "hash"
+)
+
+// defaultLeaseTTL 默认的领导者租约时长
+const defaultLeaseTTL = 15 * time.Second
+
+// LeaderCfg 领导者选举配置，仅在 SchedulingLeader 模式下生效
+type LeaderCfg struct {
+	LeaseTTL      time.Duration // 领导者租约时长，领导者故障后最长经过该时长由其他节点接管，默认15秒
+	RenewInterval time.Duration // 续约及竞选间隔，默认 LeaseTTL/3
+}
+
+// renewLeaseScript 租约仍属于当前节点时续约
+var renewLeaseScript = goredislib.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseLeaseScript 租约仍属于当前节点时释放
+var releaseLeaseScript = goredislib.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// leaderMode 是否为领导者选举模式
+func (dtm *DistributedTaskManager) leaderMode() bool {
+	return dtm.cfg.SchedulingMode == SchedulingLeader
+}
+
+// IsLeader 当前节点是否为领导者，仅在 SchedulingLeader 模式下有意义
+func (dtm *DistributedTaskManager) IsLeader() bool {
+	return dtm.leader.Load()
+}
+
+// leaseTTL 领导者租约时长
+func (dtm *DistributedTaskManager) leaseTTL() time.Duration {
+	if dtm.cfg.Leader.LeaseTTL > 0 {
+		return dtm.cfg.Leader.LeaseTTL
+	}
+	return defaultLeaseTTL
+}
+
+// startLeaderElection 启动领导者选举，当选后启动定时调度，失去领导权后停止定时调度
+func (dtm *DistributedTaskManager) startLeaderElection() {
+	interval := dtm.cfg.Leader.RenewInterval
+	if interval <= 0 {
+		interval = dtm.leaseTTL() / 3
+	}
+
+	dtm.leaderDone = make(chan struct{})
+	go func() {
+		defer close(dtm.leaderDone)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var renewedAt time.Time
+		for {
+			dtm.campaign(&renewedAt, interval)
+
+			select {
+			case <-dtm.ctx.Done():
+				dtm.resign()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// stopLeaderElection 等待选举协程退出并释放租约
+func (dtm *DistributedTaskManager) stopLeaderElection() {
+	if dtm.leaderDone != nil {
+		<-dtm.leaderDone
+	}
+}
+
+// campaign 领导者续约，非领导者尝试获取租约
+// renewedAt 为最近一次成功续约的请求发出时刻，租约不早于此时刻加租约时长过期；
+// 续约因 Redis 错误失败时，在租约可能过期的一个续约间隔之前主动退位，避免与新领导者同时调度
+func (dtm *DistributedTaskManager) campaign(renewedAt *time.Time, interval time.Duration) {
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+
+	key := dtm.key("leader")
+	ttl := dtm.leaseTTL()
+	// 在发出请求之前记录时刻，请求耗时不计入租约
+	start := dtm.clock.Now()
+
+	if dtm.leader.Load() {
+		n, err := renewLeaseScript.Run(ctx, dtm.redisClient, []string{key}, dtm.nodeID, ttl.Milliseconds()).Int64()
+		switch {
+		case err == nil && n == 1:
+			*renewedAt = start
+		case err == nil:
+			dtm.stepDown("lease taken by another node")
+		case dtm.clock.Since(*renewedAt) > ttl-interval:
+			dtm.stepDown("failed to renew lease: " + err.Error())
+		default:
+			dtm.log.Warn("Failed to renew leader lease: ", err)
+		}
+		return
+	}
+
+	// 停止期间继续为正在执行的任务续约，但不再竞选
+	if dtm.isStopping() {
+		return
+	}
+	ok, err := dtm.redisClient.SetNX(ctx, key, dtm.nodeID, ttl).Result()
+	if err != nil {
+		dtm.log.Error("Failed to campaign for leader: ", err)
+		return
+	}
+	if ok {
+		*renewedAt = start
+		dtm.leader.Store(true)
+		dtm.cron.Start()
+		dtm.log.Info("Node ", dtm.nodeID, " became leader, scheduling started")
+		dtm.catchUpMisfires()
+		dtm.runOnStartTasks()
+	}
+}
+
+// stepDown 失去领导权，停止定时调度
+func (dtm *DistributedTaskManager) stepDown(reason string) {
+	dtm.leader.Store(false)
+	dtm.cron.Stop()
+	dtm.log.Warn("Node ", dtm.nodeID, " lost leadership (", reason, "), scheduling stopped")
+}
+
+// resign 主动释放租约，使其他节点尽快接管
+func (dtm *DistributedTaskManager) resign() {
+	if !dtm.leader.Swap(false) {
+		return
+	}
+
+	// 管理器上下文已取消，使用独立的上下文释放租约
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	if err := releaseLeaseScript.Run(ctx, dtm.redisClient, []string{dtm.key("leader")}, dtm.nodeID).Err(); err != nil {
+		dtm.log.Error("Failed to release leader lease: ", err)
+		return
+	}
+	dtm.log.Info("Node ", dtm.nodeID, " resigned leadership")
+}
@@ -0,0 +1,229 @@
+package redCorn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	goredislib "github.com/go-redis/redis/v8"
+	"github.com/robfig/cron/v3"
+)
+
+// 最大执行次数的跳过原因
+const (
+	SkipReasonMaxRuns      = "max runs reached"
+	SkipReasonMaxRunsError = "failed to check run count"
+)
+
+// WithStartAt 任务注册后立即加入调度，但 start 之前的调度时刻不触发，适合与数据迁移等变更协调调度的生效时间
+// 手动触发不受限制
+func WithStartAt(start time.Time) TaskOption {
+	return func(o *taskOptions) {
+		o.startTime = start
+		o.startAfter = 0
+	}
+}
+
+// WithStartAfter 任务在 d 之后才开始调度，见 WithStartAt；开始时间按注册（添加或替换）任务时任务管理器时钟的时间计算
+func WithStartAfter(d time.Duration) TaskOption {
+	return func(o *taskOptions) {
+		o.startTime = time.Time{}
+		o.startAfter = d
+	}
+}
+
+// resolveStartAfter 按任务管理器的时钟把 WithStartAfter 的时长换算为开始时间，之后沿用选项（如 UpdateTask）时不再重新计算
+func (dtm *DistributedTaskManager) resolveStartAfter(o taskOptions) taskOptions {
+	if o.startAfter > 0 {
+		o.startTime = dtm.clock.Now().Add(o.startAfter)
+		o.startAfter = 0
+	}
+	return o
+}
+
+// WithEndTime 任务在 end 及之后不再调度，到达 end 时自动从管理器中移除，适合只运行一段时间的临时任务
+// 添加时 end 已经过去的任务在下一秒被移除；手动触发不受限制
+func WithEndTime(end time.Time) TaskOption {
+	return func(o *taskOptions) {
+		o.endTime = end
+	}
+}
+
+// runCountRetention 达到最大执行次数后执行次数计数器的最短保留时间
+const runCountRetention = 24 * time.Hour
+
+// WithMaxRuns 任务在整个集群中最多执行 n 次，达到次数后自动从管理器中移除
+// 次数保存在 Redis 中，获取锁后每次实际开始的定时执行计入；手动触发不计入也不受限制
+func WithMaxRuns(n int) TaskOption {
+	return func(o *taskOptions) {
+		o.maxRuns = int64(n)
+	}
+}
+
+// validateLifetime 检查开始时间、结束时间和最大执行次数
+func (o taskOptions) validateLifetime() error {
+	if !o.startTime.IsZero() && !o.endTime.IsZero() && !o.startTime.Before(o.endTime) {
+		return fmt.Errorf("start time %s must be before end time %s", o.startTime.Format(time.RFC3339), o.endTime.Format(time.RFC3339))
+	}
+	if o.maxRuns < 0 {
+		return fmt.Errorf("invalid max runs %d: must not be negative", o.maxRuns)
+	}
+	return nil
+}
+
+// startSchedule start 之前不触发的调度
+type startSchedule struct {
+	cron.Schedule
+	start time.Time
+}
+
+// Next 返回 t 之后且不早于 start 的第一个调度时刻
+func (s startSchedule) Next(t time.Time) time.Time {
+	if t.Before(s.start) {
+		t = s.start.Add(-time.Nanosecond)
+	}
+	return s.Schedule.Next(t)
+}
+
+// endSchedule end 及之后不再触发的调度
+type endSchedule struct {
+	cron.Schedule
+	end time.Time
+}
+
+// Next 返回 t 之后早于 end 的调度时刻，没有时返回零值
+func (s endSchedule) Next(t time.Time) time.Time {
+	next := s.Schedule.Next(t)
+	if !next.Before(s.end) {
+		return time.Time{}
+	}
+	return next
+}
+
+// endJob 交给 cron 的有结束时间的调度和任务：结束前按任务的调度执行，到达结束时间时触发一次并移除任务
+type endJob struct {
+	dtm   *DistributedTaskManager
+	task  *distributedTask
+	end   time.Time
+	ended atomic.Bool
+}
+
+// Next 结束前返回任务的下一次调度时刻，之后没有调度时在 end 触发，已经过了 end 时在下一秒触发
+func (j *endJob) Next(t time.Time) time.Time {
+	if j.ended.Load() {
+		return time.Time{}
+	}
+	if next := j.task.schedule.Next(t); !next.IsZero() {
+		return next
+	}
+	if t.Before(j.end) {
+		return j.end
+	}
+	return t.Truncate(time.Second).Add(time.Second)
+}
+
+// Run 结束前按调度时刻执行任务，到达结束时间后移除任务
+func (j *endJob) Run() {
+	now := j.dtm.clock.Now()
+	if now.Before(j.end) {
+		j.dtm.dispatch(j.task, false, now.Truncate(time.Second))
+		return
+	}
+	if j.ended.CompareAndSwap(false, true) {
+		j.dtm.expireTask(j.task, "end time reached")
+	}
+}
+
+// expireTask 任务到达结束时间或最大执行次数后从管理器中移除，任务已被移除或替换时忽略
+// 执行次数计数器暂时保留，其他节点据此发现次数已满并移除任务，之后计数器过期，同名任务可以重新注册
+func (dtm *DistributedTaskManager) expireTask(t *distributedTask, reason string) {
+	dtm.mu.Lock()
+	defer dtm.mu.Unlock()
+	if dtm.tasks[t.name] == t {
+		dtm.detachTask(t)
+		dtm.retainRunCount(t)
+		dtm.log.Info("Removed distributed task: ", t.name, ", ", reason)
+	}
+}
+
+// runCountKey 任务已执行次数的计数器
+func (dtm *DistributedTaskManager) runCountKey(t *distributedTask) string {
+	return dtm.key("runs", t.name)
+}
+
+// deleteRunCount 任务被移除时删除执行次数计数器，之后以同名重新注册的任务重新计数
+func (dtm *DistributedTaskManager) deleteRunCount(t *distributedTask) {
+	if t.opts.maxRuns <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	if err := dtm.redisClient.Del(ctx, dtm.runCountKey(t)).Err(); err != nil {
+		dtm.logRun(slog.LevelError, t.name, "", "Failed to delete run count", slog.Any("error", err))
+	}
+}
+
+// retainRunCount 任务到期后为执行次数计数器设置过期时间：保留 runCountRetention，
+// 且不短于两个调度间隔，确保其他节点在下一次调度时都能读到已满的次数
+func (dtm *DistributedTaskManager) retainRunCount(t *distributedTask) {
+	if t.opts.maxRuns <= 0 {
+		return
+	}
+	ttl := runCountRetention
+	now := dtm.clock.Now()
+	if next := t.schedule.Next(now); !next.IsZero() {
+		if d := 2 * next.Sub(now); d > ttl {
+			ttl = d
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	if err := dtm.redisClient.Expire(ctx, dtm.runCountKey(t), ttl).Err(); err != nil {
+		dtm.logRun(slog.LevelError, t.name, "", "Failed to set run count expiry", slog.Any("error", err))
+	}
+}
+
+// checkRunCount 获取锁之前检查任务是否已达到最大执行次数，已达到时移除任务，
+// 使没有抢到锁的节点同样在下一次调度时移除任务；出错时在执行记录中写入跳过原因
+func (dtm *DistributedTaskManager) checkRunCount(ctx context.Context, t *distributedTask, rec *ExecutionRecord) bool {
+	count, err := dtm.redisClient.Get(ctx, dtm.runCountKey(t)).Int64()
+	if err != nil && !errors.Is(err, goredislib.Nil) {
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonMaxRunsError
+		rec.setError(err)
+		dtm.logRun(slog.LevelError, t.name, rec.RunID, "Failed to check run count, skipping execution", slog.Any("error", err))
+		return false
+	}
+	if count >= t.opts.maxRuns {
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonMaxRuns
+		dtm.logRun(slog.LevelInfo, t.name, rec.RunID, "Max runs reached, skipping execution", slog.Int64("max_runs", t.opts.maxRuns))
+		dtm.expireTask(t, "max runs reached")
+		return false
+	}
+	return true
+}
+
+// countRun 在计数器 <Namespace>:runs:<任务名> 上计入本次执行，last 表示本次是最后一次执行
+// 超出最大执行次数或出错时在执行记录中写入跳过原因
+func (dtm *DistributedTaskManager) countRun(ctx context.Context, t *distributedTask, rec *ExecutionRecord) (ok, last bool) {
+	count, err := dtm.redisClient.Incr(ctx, dtm.runCountKey(t)).Result()
+	if err != nil {
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonMaxRunsError
+		rec.setError(err)
+		dtm.logRun(slog.LevelError, t.name, rec.RunID, "Failed to count run, skipping execution", slog.Any("error", err))
+		return false, false
+	}
+	if count > t.opts.maxRuns {
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonMaxRuns
+		dtm.logRun(slog.LevelInfo, t.name, rec.RunID, "Max runs reached, skipping execution", slog.Int64("max_runs", t.opts.maxRuns))
+		dtm.expireTask(t, "max runs reached")
+		return false, false
+	}
+	return true, count == t.opts.maxRuns
+}
@@ -0,0 +1,93 @@
+package redCorn
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+const (
+	defaultLoadThreshold = 0.8         // 默认的负载阈值
+	defaultLoadMaxDelay  = time.Second // 默认的最长延迟
+)
+
+// LoadProbe 返回当前节点的负载，通常在 [0, 1] 之间，1 表示满载
+type LoadProbe func() float64
+
+// LoadCfg 按节点负载延迟获取锁，让负载较低的节点优先获取锁并执行任务
+type LoadCfg struct {
+	Probe LoadProbe // 负载探针，为 nil 时不启用
+	// Threshold 负载达到该值时延迟获取锁，默认 0.8
+	Threshold float64
+	// MaxDelay 负载为1（及以上）时的延迟，延迟按负载等比例缩短，默认1秒；
+	// 应明显大于节点获取锁的耗时，同时小于调度间隔
+	MaxDelay time.Duration
+}
+
+// validate 检查负载配置
+func (c LoadCfg) validate() error {
+	if c.Threshold < 0 {
+		return fmt.Errorf("invalid load threshold %v: must not be negative", c.Threshold)
+	}
+	if c.MaxDelay < 0 {
+		return fmt.Errorf("invalid load max delay %s: must not be negative", c.MaxDelay)
+	}
+	return nil
+}
+
+// GoroutineLoad 以当前协程数与 limit 之比作为负载的探针
+func GoroutineLoad(limit int) LoadProbe {
+	return func() float64 {
+		return float64(runtime.NumGoroutine()) / float64(limit)
+	}
+}
+
+// loadDelay 按探针的负载计算获取锁前的延迟，未达到阈值时为0
+func (c LoadCfg) loadDelay() (float64, time.Duration) {
+	if c.Probe == nil {
+		return 0, 0
+	}
+	threshold := c.Threshold
+	if threshold <= 0 {
+		threshold = defaultLoadThreshold
+	}
+	maxDelay := c.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultLoadMaxDelay
+	}
+
+	load := c.Probe()
+	if load < threshold {
+		return load, 0
+	}
+	if load > 1 {
+		return load, maxDelay
+	}
+	return load, time.Duration(float64(maxDelay) * load)
+}
+
+// waitLoad 节点负载较高时延迟获取锁，返回 false 表示等待期间管理器已停止，本次执行应跳过
+func (dtm *DistributedTaskManager) waitLoad(t *distributedTask, rec *ExecutionRecord) bool {
+	load, delay := dtm.cfg.Load.loadDelay()
+	if delay <= 0 {
+		return true
+	}
+	dtm.logRun(slog.LevelDebug, t.name, rec.RunID, "Node under load, delaying lock acquisition",
+		slog.Float64("load", load), slog.Duration("delay", delay))
+	return dtm.waitDelay(delay, rec)
+}
+
+// waitDelay 获取锁之前等待 delay，返回 false 表示等待期间管理器已停止，本次执行应跳过
+func (dtm *DistributedTaskManager) waitDelay(delay time.Duration, rec *ExecutionRecord) bool {
+	timer := dtm.clock.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-dtm.stopping:
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonStopped
+		return false
+	case <-timer.Chan():
+	}
+	return true
+}
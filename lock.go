@@ -0,0 +1,392 @@
+package redCorn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// taskMutex 任务执行期间持有的分布式锁，串行化看门狗与重试对锁的续期操作
+type taskMutex struct {
+	mu       sync.Mutex
+	mutex    Mutex
+	clock    Clock
+	expiry   time.Duration
+	deadline time.Time // 按本地时钟估算的锁过期时间，获取和续期成功时更新
+	holdTo   time.Time // 配置了 WithHoldLockUntilNextFire 时锁需要保持到的时间，否则为零值
+}
+
+// holdLockMargin 保持锁时在下一次调度时刻之前提前让锁过期的时间，确保下一次调度能获取到锁
+const holdLockMargin = 500 * time.Millisecond
+
+// validate 检查锁的调优参数
+func (c LockCfg) validate() error {
+	if c.Tries < 0 {
+		return fmt.Errorf("invalid lock tries %d: must not be negative", c.Tries)
+	}
+	if c.RetryDelay < 0 {
+		return fmt.Errorf("invalid lock retry delay %s: must not be negative", c.RetryDelay)
+	}
+	if c.DriftFactor < 0 || c.DriftFactor >= 1 {
+		return fmt.Errorf("invalid lock drift factor %v: must be in [0, 1)", c.DriftFactor)
+	}
+	return nil
+}
+
+// lockKey 任务分布式锁的键，任务级前缀优先于全局 LockCfg.Prefix
+func (dtm *DistributedTaskManager) lockKey(t *distributedTask) string {
+	prefix := dtm.cfg.LockCfg.Prefix
+	if t.opts.lockPrefix != nil {
+		prefix = *t.opts.lockPrefix
+	}
+	return prefix + t.name
+}
+
+// newTaskMutex 按任务的锁配置创建分布式锁，任务级配置优先于全局 LockCfg
+// 锁的值默认为 "<节点>/<执行标识>"，可据此找到持有锁的那次执行
+// 定时调度的任务配置了 WithHoldLockUntilNextFire 时，过期时间延长到下一次调度时刻之前
+func (dtm *DistributedTaskManager) newTaskMutex(t *distributedTask, runID string, tick time.Time) (*taskMutex, error) {
+	lockCfg := dtm.cfg.LockCfg
+	expiry := lockCfg.Expiry
+	if t.opts.lockExpiry > 0 {
+		expiry = t.opts.lockExpiry
+	}
+	tries := lockCfg.Tries
+	if t.opts.lockRetries > 0 || tries < 1 {
+		tries = t.opts.lockRetries + 1
+	}
+
+	value := dtm.nodeID + "/" + runID
+	if lockCfg.GenValue != nil {
+		v, err := lockCfg.GenValue(dtm.nodeID, runID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate lock value: %v", err)
+		}
+		value = v
+	}
+
+	var holdTo time.Time
+	if t.opts.holdLock && !tick.IsZero() {
+		if next := t.schedule.Next(tick); !next.IsZero() {
+			holdTo = next.Add(-holdLockMargin)
+			if d := dtm.clock.Until(holdTo); d > expiry {
+				expiry = d
+			}
+		}
+	}
+
+	return &taskMutex{
+		mutex: dtm.locker.NewMutex(dtm.lockKey(t), LockOptions{
+			Expiry:      expiry,
+			Tries:       tries,
+			RetryDelay:  lockCfg.RetryDelay,
+			DriftFactor: lockCfg.DriftFactor,
+			Value:       value,
+		}),
+		clock:  dtm.clock,
+		expiry: expiry,
+		holdTo: holdTo,
+	}, nil
+}
+
+// acquireTaskLock 获取任务的分布式锁，获取失败时在执行记录中写入跳过原因
+// 获取成功时返回的 release 用于释放锁；定时调度时节点负载较高或分片未分配给当前节点会先延迟再获取
+func (dtm *DistributedTaskManager) acquireTaskLock(ctx context.Context, span trace.Span, t *distributedTask, rec *ExecutionRecord, tick time.Time) (*taskMutex, func(), bool) {
+	if !tick.IsZero() && (!dtm.waitLoad(t, rec) || !dtm.waitShard(t, tick, rec)) {
+		return nil, nil, false
+	}
+	mutex, err := dtm.newTaskMutex(t, rec.RunID, tick)
+
+	// 尝试获取分布式锁
+	if err == nil {
+		err = mutex.lock(ctx)
+	}
+	if err != nil {
+		span.SetAttributes(attrLockAcquired.Bool(false))
+		rec.Status = ExecutionSkipped
+		if errors.Is(err, ErrLockHeld) {
+			rec.SkipReason = SkipReasonLockHeld
+			dtm.logRun(slog.LevelInfo, t.name, rec.RunID, "Lock held by another execution, skipping execution", dtm.holderAttrs(ctx, t)...)
+		} else {
+			rec.SkipReason = SkipReasonLockError
+			rec.setError(err)
+			recordSpanError(span, err)
+			dtm.logRun(slog.LevelError, t.name, rec.RunID, "Failed to acquire lock, skipping execution", slog.Any("error", err))
+		}
+		return nil, nil, false
+	}
+	span.SetAttributes(attrLockAcquired.Bool(true))
+
+	release := func() {
+		if mutex.holding() {
+			dtm.heldLocks.Store(t.name, mutex)
+			dtm.logRun(slog.LevelInfo, t.name, rec.RunID, "Holding lock until next fire", slog.Time("until", mutex.holdTo))
+			return
+		}
+		if ok, err := mutex.unlock(); !ok || err != nil {
+			if errors.Is(err, ErrLockExpired) {
+				dtm.logRun(slog.LevelWarn, t.name, rec.RunID, "Lock already expired, skipping release")
+			} else {
+				dtm.logRun(slog.LevelError, t.name, rec.RunID, "Failed to release lock", slog.Any("error", err))
+			}
+		} else {
+			dtm.logRun(slog.LevelInfo, t.name, rec.RunID, "Lock released")
+		}
+	}
+	return mutex, release, true
+}
+
+// lock 获取分布式锁
+func (m *taskMutex) lock(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	start := m.clock.Now()
+	if err := m.mutex.Lock(ctx); err != nil {
+		return err
+	}
+	m.deadline = start.Add(m.expiry)
+	return nil
+}
+
+// extend 续期分布式锁
+func (m *taskMutex) extend(ctx context.Context) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	start := m.clock.Now()
+	ok, err := m.mutex.Extend(ctx)
+	if ok && err == nil {
+		m.deadline = start.Add(m.expiry)
+	}
+	return ok, err
+}
+
+// holding 执行结束后是否保持锁直到其自然过期
+// 看门狗或抖动等待续期后锁的过期时间会晚于下一次调度时刻，此时正常释放锁
+func (m *taskMutex) holding() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return !m.holdTo.IsZero() && !m.deadline.After(m.holdTo.Add(holdLockMargin))
+}
+
+// unlock 释放分布式锁，任务上下文可能已被取消，使用独立的上下文
+func (m *taskMutex) unlock() (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mutex.Unlock(context.Background())
+}
+
+// releaseTaskLocks 任务被移除时清理其锁状态：释放当前节点保持到下一次调度时刻的锁，删除各调度时刻的认领记录
+// 锁的值包含执行标识，保持的锁已过期或已被其他执行获取时不会误删；调用方需持有 dtm.mu
+func (dtm *DistributedTaskManager) releaseTaskLocks(t *distributedTask) {
+	if v, ok := dtm.heldLocks.LoadAndDelete(t.name); ok {
+		if ok, err := v.(*taskMutex).unlock(); ok && err == nil {
+			dtm.logRun(slog.LevelInfo, t.name, "", "Released held lock of removed task")
+		} else if err != nil && !errors.Is(err, ErrLockExpired) {
+			dtm.logRun(slog.LevelError, t.name, "", "Failed to release held lock of removed task", slog.Any("error", err))
+		}
+	}
+
+	if dtm.cfg.LockCfg.DisableTickScope {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	prefix := dtm.key("tick", t.name) + ":"
+	keys, err := scanKeys(ctx, dtm.redisClient, prefix)
+	if err == nil {
+		// 集群模式下各键可能位于不同的槽，逐个删除；跳过名称以 "<任务名>:" 开头的其他任务的认领记录
+		pipe := dtm.redisClient.Pipeline()
+		for _, key := range keys {
+			if _, err := strconv.ParseInt(strings.TrimPrefix(key, prefix), 10, 64); err == nil {
+				pipe.Del(ctx, key)
+			}
+		}
+		if pipe.Len() > 0 {
+			_, err = pipe.Exec(ctx)
+		}
+	}
+	if err != nil {
+		dtm.logRun(slog.LevelError, t.name, "", "Failed to delete tick claims of removed task", slog.Any("error", err))
+	}
+}
+
+// WithLockExpiry 覆盖任务的锁过期时间
+func WithLockExpiry(expiry time.Duration) TaskOption {
+	return func(o *taskOptions) {
+		o.lockExpiry = expiry
+	}
+}
+
+// WithLockPrefix 覆盖任务的锁前缀
+func WithLockPrefix(prefix string) TaskOption {
+	return func(o *taskOptions) {
+		o.lockPrefix = &prefix
+	}
+}
+
+// WithLockRetries 获取锁失败时的重试次数，默认不重试（每个调度周期只尝试一次）
+func WithLockRetries(retries int) TaskOption {
+	return func(o *taskOptions) {
+		o.lockRetries = retries
+	}
+}
+
+// WithHoldLockUntilNextFire 执行结束后不释放锁，保持到下一次调度时刻之前再过期，
+// 即使节点间时钟有偏差，每个调度时刻也只会执行一次；手动触发和补执行时不生效
+// 锁会在下一次调度时刻前 500 毫秒过期，执行时间超过调度间隔时按正常方式释放锁；
+// 要求锁后端支持过期时间，pglock、mysqllock 等随连接持有的锁不能使用，注册任务时返回错误
+func WithHoldLockUntilNextFire() TaskOption {
+	return func(o *taskOptions) {
+		o.holdLock = true
+	}
+}
+
+// validateHoldLock 检查锁后端是否支持过期时间，不会过期的锁在执行结束后保持会一直被占用
+func (dtm *DistributedTaskManager) validateHoldLock(o taskOptions) error {
+	if !o.holdLock {
+		return nil
+	}
+	if expirer, ok := dtm.locker.(LockExpirer); ok && !expirer.ExpiresLocks() {
+		return fmt.Errorf("invalid hold lock until next fire: lock backend %T does not expire locks", dtm.locker)
+	}
+	return nil
+}
+
+// holderAttrs 查询持有任务锁的节点和执行标识，用于跳过执行时的日志，查询失败时返回空
+func (dtm *DistributedTaskManager) holderAttrs(ctx context.Context, t *distributedTask) []slog.Attr {
+	locks := []LockInfo{{Task: t.name, Key: dtm.lockKey(t)}}
+	if err := dtm.inspectLocks(ctx, locks); err != nil || !locks[0].Locked {
+		return nil
+	}
+	if locks[0].Node == "" {
+		return []slog.Attr{slog.String("holder", locks[0].Holder)}
+	}
+	return []slog.Attr{slog.String("holder_node", locks[0].Node), slog.String("holder_run_id", locks[0].RunID)}
+}
+
+// LockHolder 查询任务锁当前的持有者（节点、执行标识和剩余时间），用于排查一直未释放的锁
+// 锁未被持有时 Locked 为 false；锁后端未实现 LockInspector 时返回 ErrLockInspectUnsupported
+func (dtm *DistributedTaskManager) LockHolder(taskName string) (LockInfo, error) {
+	dtm.mu.RLock()
+	t, exists := dtm.tasks[taskName]
+	dtm.mu.RUnlock()
+	if !exists {
+		return LockInfo{}, fmt.Errorf("%w: %s", ErrTaskNotFound, taskName)
+	}
+	if _, ok := dtm.locker.(LockInspector); !ok {
+		return LockInfo{}, ErrLockInspectUnsupported
+	}
+
+	locks := []LockInfo{{Task: t.name, Key: dtm.lockKey(t)}}
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+	if err := dtm.inspectLocks(ctx, locks); err != nil {
+		return LockInfo{}, err
+	}
+	return locks[0], nil
+}
+
+// ListLocks 列出当前被持有的任务锁（键、持有者和剩余时间），即集群中正在执行的任务，按键排序
+// 锁后端实现了 LockLister 时按全局和任务级的锁前缀扫描，包括其他节点注册的任务；否则只查询当前节点注册的任务
+func (dtm *DistributedTaskManager) ListLocks() ([]LockInfo, error) {
+	if _, ok := dtm.locker.(LockInspector); !ok {
+		return nil, ErrLockInspectUnsupported
+	}
+
+	dtm.mu.RLock()
+	registered := make([]LockInfo, 0, len(dtm.tasks))
+	prefixes := map[string]bool{dtm.cfg.LockCfg.Prefix: true}
+	for _, t := range dtm.tasks {
+		registered = append(registered, LockInfo{Task: t.name, Key: dtm.lockKey(t)})
+		if t.opts.lockPrefix != nil {
+			prefixes[*t.opts.lockPrefix] = true
+		}
+	}
+	dtm.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+	if err := dtm.inspectLocks(ctx, registered); err != nil {
+		return nil, err
+	}
+
+	tasks := make(map[string]string, len(registered))
+	locks := make(map[string]LockInfo)
+	for _, lock := range registered {
+		tasks[lock.Key] = lock.Task
+		if lock.Locked {
+			locks[lock.Key] = lock
+		}
+	}
+
+	// 前缀为空时无法区分锁和其他键，只查询当前节点注册的任务
+	if lister, ok := dtm.locker.(LockLister); ok {
+		for prefix := range prefixes {
+			if prefix == "" {
+				continue
+			}
+			scanned, err := lister.ListLocks(ctx, prefix)
+			if errors.Is(err, ErrLockInspectUnsupported) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			for _, lock := range scanned {
+				if _, ok := locks[lock.Key]; ok {
+					continue
+				}
+				lock.Task = tasks[lock.Key]
+				if lock.Task == "" {
+					lock.Task = strings.TrimPrefix(lock.Key, prefix)
+				}
+				locks[lock.Key] = lock
+			}
+		}
+	}
+
+	result := make([]LockInfo, 0, len(locks))
+	for _, lock := range locks {
+		result = append(result, lock)
+	}
+	return heldLocks(result), nil
+}
+
+// minTickClaimTTL 调度时刻认领记录的最短保留时间，覆盖常见的节点间时钟偏差
+const minTickClaimTTL = time.Minute
+
+// claimTick 在集群中认领本次调度时刻，返回 false 表示该时刻已由其他执行认领，本次执行应跳过
+// 任务锁在执行结束后即释放，时钟偏慢的节点之后按自己的时钟触发同一时刻时仍能获取到锁，认领记录阻止其重复执行
+// 认领记录保留到下一次调度时刻（至少1分钟，一次性任务保留24小时）；认领出错时不阻止执行
+func (dtm *DistributedTaskManager) claimTick(ctx context.Context, t *distributedTask, tick time.Time, rec *ExecutionRecord) bool {
+	if dtm.cfg.LockCfg.DisableTickScope {
+		return true
+	}
+
+	ttl := t.schedule.Next(tick).Sub(tick)
+	if t.isOnce() {
+		ttl = onceClaimTTL
+	} else if ttl < minTickClaimTTL {
+		ttl = minTickClaimTTL
+	}
+	key := dtm.key("tick", t.name, strconv.FormatInt(tick.Unix(), 10))
+	claimed, err := dtm.redisClient.SetNX(ctx, key, rec.Node+"/"+rec.RunID, ttl).Result()
+	if err != nil {
+		dtm.logRun(slog.LevelError, t.name, rec.RunID, "Failed to claim tick, executing anyway", slog.Any("error", err))
+		return true
+	}
+	if !claimed {
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonTickDone
+		dtm.logRun(slog.LevelInfo, t.name, rec.RunID, "Tick already executed by another execution, skipping execution", slog.Time("tick", tick))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,193 @@
+// Package consullock 基于 Consul 会话实现 redCorn.Locker
+//
+// 只替换任务的分布式锁，任务管理器的其余数据（调度认领、执行历史、状态、心跳、控制指令）仍保存在 Redis 中，
+// 因此仍需配置 redCorn.Cfg.RedisCfg 或 RedisClient
+package consullock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/kzdgt/redCorn"
+)
+
+const (
+	// retryDelay 配置了重试次数且未配置 RetryDelay 时两次尝试之间的间隔
+	retryDelay = 100 * time.Millisecond
+	// minSessionTTL Consul 会话允许的最短 TTL
+	minSessionTTL = 10 * time.Second
+)
+
+// Locker 基于 Consul 的分布式锁，每次加锁创建一个 TTL 为锁过期时间的会话，
+// 通过 KV acquire 争抢锁，续期即续约会话
+type Locker struct {
+	client *api.Client
+	prefix string
+}
+
+var (
+	_ redCorn.Locker        = (*Locker)(nil)
+	_ redCorn.LockInspector = (*Locker)(nil)
+)
+
+// New 基于 Consul 客户端创建分布式锁，prefix 会加在 redCorn 生成的锁键之前，可为空
+func New(client *api.Client, prefix string) *Locker {
+	return &Locker{client: client, prefix: prefix}
+}
+
+// NewMutex 创建锁
+func (l *Locker) NewMutex(key string, opts redCorn.LockOptions) redCorn.Mutex {
+	return &mutex{
+		client: l.client,
+		key:    l.prefix + key,
+		opts:   opts,
+	}
+}
+
+// InspectLocks 读取锁键的值和持有会话的 TTL，Consul 不提供会话的剩余时间，TTL 为会话的完整 TTL
+func (l *Locker) InspectLocks(ctx context.Context, locks []redCorn.LockInfo) error {
+	q := (&api.QueryOptions{}).WithContext(ctx)
+	for i := range locks {
+		pair, _, err := l.client.KV().Get(l.prefix+locks[i].Key, q)
+		if err != nil {
+			return fmt.Errorf("failed to inspect locks: %v", err)
+		}
+		if pair == nil || pair.Session == "" {
+			continue
+		}
+
+		locks[i].Locked = true
+		locks[i].Holder = string(pair.Value)
+		locks[i].TTL = -1
+		session, _, err := l.client.Session().Info(pair.Session, q)
+		if err != nil {
+			return fmt.Errorf("failed to inspect locks: %v", err)
+		}
+		if session != nil {
+			if ttl, err := time.ParseDuration(session.TTL); err == nil {
+				locks[i].TTL = ttl
+			}
+		}
+	}
+	return nil
+}
+
+// mutex 一次执行持有的锁
+type mutex struct {
+	client  *api.Client
+	key     string
+	opts    redCorn.LockOptions
+	session string
+}
+
+// Lock 获取锁，按 Tries 重试
+func (m *mutex) Lock(ctx context.Context) error {
+	tries := m.opts.Tries
+	if tries < 1 {
+		tries = 1
+	}
+	delay := m.opts.RetryDelay
+	if delay <= 0 {
+		delay = retryDelay
+	}
+
+	for i := 0; i < tries; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		err := m.acquire(ctx)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, redCorn.ErrLockHeld) {
+			return err
+		}
+	}
+	return redCorn.ErrLockHeld
+}
+
+// acquire 尝试一次获取锁
+func (m *mutex) acquire(ctx context.Context) error {
+	w := (&api.WriteOptions{}).WithContext(ctx)
+	session, _, err := m.client.Session().Create(&api.SessionEntry{
+		Name:     "redcorn:" + m.key,
+		TTL:      sessionTTL(m.opts.Expiry).String(),
+		Behavior: api.SessionBehaviorRelease,
+	}, w)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %v", err)
+	}
+
+	acquired, _, err := m.client.KV().Acquire(&api.KVPair{
+		Key:     m.key,
+		Value:   []byte(m.opts.Value),
+		Session: session,
+	}, w)
+	if err != nil || !acquired {
+		m.destroy(session)
+		if err != nil {
+			return fmt.Errorf("failed to acquire lock: %v", err)
+		}
+		return redCorn.ErrLockHeld
+	}
+
+	m.session = session
+	return nil
+}
+
+// Extend 续约会话，会话已失效或锁已不由本次执行持有时返回 false
+func (m *mutex) Extend(ctx context.Context) (bool, error) {
+	entry, _, err := m.client.Session().Renew(m.session, (&api.WriteOptions{}).WithContext(ctx))
+	if err != nil {
+		return false, fmt.Errorf("failed to extend lock: %v", err)
+	}
+	if entry == nil {
+		return false, nil
+	}
+
+	pair, _, err := m.client.KV().Get(m.key, (&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return false, fmt.Errorf("failed to extend lock: %v", err)
+	}
+	return pair != nil && pair.Session == m.session, nil
+}
+
+// Unlock 释放锁并销毁会话
+// 先 release 再销毁会话，避免会话失效触发 Consul 的 lock-delay 使锁在一段时间内无法再被获取
+func (m *mutex) Unlock(ctx context.Context) (bool, error) {
+	released, _, err := m.client.KV().Release(&api.KVPair{
+		Key:     m.key,
+		Session: m.session,
+	}, (&api.WriteOptions{}).WithContext(ctx))
+	m.destroy(m.session)
+	if err != nil {
+		return false, fmt.Errorf("failed to release lock: %v", err)
+	}
+	if !released {
+		return false, redCorn.ErrLockExpired
+	}
+	return true, nil
+}
+
+// destroy 销毁会话，任务上下文可能已被取消，使用独立的上下文
+func (m *mutex) destroy(session string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, _ = m.client.Session().Destroy(session, (&api.WriteOptions{}).WithContext(ctx))
+}
+
+// sessionTTL 锁过期时间换算为会话 TTL，向上取整到秒且不低于 Consul 允许的最小值
+func sessionTTL(expiry time.Duration) time.Duration {
+	if expiry < minSessionTTL {
+		return minSessionTTL
+	}
+	return (expiry + time.Second - 1) / time.Second * time.Second
+}
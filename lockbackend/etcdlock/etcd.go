@@ -0,0 +1,192 @@
+// Package etcdlock 基于 etcd 租约实现 redCorn.Locker
+package etcdlock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kzdgt/redCorn"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// retryDelay 配置了重试次数且未配置 RetryDelay 时两次尝试之间的间隔
+const retryDelay = 100 * time.Millisecond
+
+// Locker 基于 etcd 的分布式锁，锁键绑定一个 TTL 为锁过期时间的租约，
+// 通过事务在键不存在时写入来争抢锁，续期即刷新租约
+type Locker struct {
+	client *clientv3.Client
+	prefix string
+}
+
+var (
+	_ redCorn.Locker        = (*Locker)(nil)
+	_ redCorn.LockInspector = (*Locker)(nil)
+)
+
+// New 基于 etcd 客户端创建分布式锁，prefix 会加在 redCorn 生成的锁键之前，可为空
+func New(client *clientv3.Client, prefix string) *Locker {
+	return &Locker{client: client, prefix: prefix}
+}
+
+// NewMutex 创建锁
+func (l *Locker) NewMutex(key string, opts redCorn.LockOptions) redCorn.Mutex {
+	return &mutex{
+		client: l.client,
+		key:    l.prefix + key,
+		opts:   opts,
+	}
+}
+
+// InspectLocks 读取锁键的值和租约剩余时间
+func (l *Locker) InspectLocks(ctx context.Context, locks []redCorn.LockInfo) error {
+	for i := range locks {
+		resp, err := l.client.Get(ctx, l.prefix+locks[i].Key)
+		if err != nil {
+			return fmt.Errorf("failed to inspect locks: %v", err)
+		}
+		if len(resp.Kvs) == 0 {
+			continue
+		}
+
+		kv := resp.Kvs[0]
+		locks[i].Locked = true
+		locks[i].Holder = string(kv.Value)
+		locks[i].TTL = -1
+		if kv.Lease != 0 {
+			ttl, err := l.client.TimeToLive(ctx, clientv3.LeaseID(kv.Lease))
+			if err != nil {
+				return fmt.Errorf("failed to inspect locks: %v", err)
+			}
+			locks[i].TTL = time.Duration(ttl.TTL) * time.Second
+		}
+	}
+	return nil
+}
+
+// mutex 一次执行持有的锁
+type mutex struct {
+	client *clientv3.Client
+	key    string
+	opts   redCorn.LockOptions
+	lease  clientv3.LeaseID
+}
+
+// Lock 获取锁，按 Tries 重试
+func (m *mutex) Lock(ctx context.Context) error {
+	tries := m.opts.Tries
+	if tries < 1 {
+		tries = 1
+	}
+	delay := m.opts.RetryDelay
+	if delay <= 0 {
+		delay = retryDelay
+	}
+
+	for i := 0; i < tries; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		err := m.acquire(ctx)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, redCorn.ErrLockHeld) {
+			return err
+		}
+	}
+	return redCorn.ErrLockHeld
+}
+
+// acquire 尝试一次获取锁
+func (m *mutex) acquire(ctx context.Context) error {
+	lease, err := m.client.Grant(ctx, leaseTTL(m.opts.Expiry))
+	if err != nil {
+		return fmt.Errorf("failed to grant lease: %v", err)
+	}
+
+	resp, err := m.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(m.key), "=", 0)).
+		Then(clientv3.OpPut(m.key, m.opts.Value, clientv3.WithLease(lease.ID))).
+		Commit()
+	if err != nil || !resp.Succeeded {
+		m.revoke(lease.ID)
+		if err != nil {
+			return fmt.Errorf("failed to acquire lock: %v", err)
+		}
+		return redCorn.ErrLockHeld
+	}
+
+	m.lease = lease.ID
+	return nil
+}
+
+// Extend 刷新租约，锁已被删除或租约已过期时返回 false
+func (m *mutex) Extend(ctx context.Context) (bool, error) {
+	held, err := m.held(ctx)
+	if err != nil || !held {
+		return false, err
+	}
+	if _, err := m.client.KeepAliveOnce(ctx, m.lease); err != nil {
+		if errors.Is(err, rpctypes.ErrLeaseNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to extend lock: %v", err)
+	}
+	return true, nil
+}
+
+// Unlock 仅当锁仍由本次执行持有时删除锁键并撤销租约
+func (m *mutex) Unlock(ctx context.Context) (bool, error) {
+	resp, err := m.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.Value(m.key), "=", m.opts.Value),
+			clientv3.Compare(clientv3.LeaseValue(m.key), "=", m.lease)).
+		Then(clientv3.OpDelete(m.key)).
+		Commit()
+	if err != nil {
+		return false, fmt.Errorf("failed to release lock: %v", err)
+	}
+	// 锁键已不在本次执行的租约上时租约已过期，无需撤销
+	if !resp.Succeeded {
+		return false, redCorn.ErrLockExpired
+	}
+	m.revoke(m.lease)
+	return true, nil
+}
+
+// held 锁键是否仍绑定在本次执行的租约上
+func (m *mutex) held(ctx context.Context) (bool, error) {
+	resp, err := m.client.Get(ctx, m.key)
+	if err != nil {
+		return false, fmt.Errorf("failed to extend lock: %v", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return false, nil
+	}
+	kv := resp.Kvs[0]
+	return string(kv.Value) == m.opts.Value && clientv3.LeaseID(kv.Lease) == m.lease, nil
+}
+
+// revoke 撤销租约，任务上下文可能已被取消，使用独立的上下文
+func (m *mutex) revoke(lease clientv3.LeaseID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, _ = m.client.Revoke(ctx, lease)
+}
+
+// leaseTTL 锁过期时间换算为租约 TTL，向上取整到秒
+func leaseTTL(expiry time.Duration) int64 {
+	ttl := int64((expiry + time.Second - 1) / time.Second)
+	if ttl < 1 {
+		ttl = 1
+	}
+	return ttl
+}
@@ -0,0 +1,274 @@
+// Package k8slock 基于 Kubernetes Lease（coordination.k8s.io/v1）实现 redCorn.Locker
+//
+// Lease 只承载任务锁，集群内部署时仍需为任务管理器提供 Redis，用于调度认领、执行历史和节点心跳等
+package k8slock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/kzdgt/redCorn"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	coordinationclient "k8s.io/client-go/kubernetes/typed/coordination/v1"
+)
+
+const (
+	// retryDelay 配置了重试次数且未配置 RetryDelay 时两次尝试之间的间隔
+	retryDelay = 100 * time.Millisecond
+	// keyAnnotation 记录 Lease 对应的 redCorn 锁键
+	keyAnnotation = "redcorn.kzdgt.github.com/lock-key"
+	// maxNameLength Lease 名称的最大长度
+	maxNameLength = 253
+)
+
+// Locker 基于 Kubernetes Lease 的分布式锁，每个锁键对应命名空间中的一个 Lease，
+// holderIdentity 为锁的值，renewTime 加 leaseDurationSeconds 之前视为被持有
+type Locker struct {
+	leases coordinationclient.LeaseInterface
+	prefix string
+}
+
+var (
+	_ redCorn.Locker        = (*Locker)(nil)
+	_ redCorn.LockInspector = (*Locker)(nil)
+)
+
+// New 基于 Kubernetes 客户端创建分布式锁，Lease 创建在 namespace 中，prefix 会加在 Lease 名称之前，可为空
+// 需要对该命名空间的 leases 资源拥有 get、create、update、delete 权限
+func New(client kubernetes.Interface, namespace, prefix string) *Locker {
+	return &Locker{
+		leases: client.CoordinationV1().Leases(namespace),
+		prefix: prefix,
+	}
+}
+
+// NewMutex 创建锁
+func (l *Locker) NewMutex(key string, opts redCorn.LockOptions) redCorn.Mutex {
+	return &mutex{
+		leases: l.leases,
+		name:   leaseName(l.prefix, key),
+		key:    key,
+		opts:   opts,
+	}
+}
+
+// InspectLocks 读取 Lease 的持有者和剩余时间
+func (l *Locker) InspectLocks(ctx context.Context, locks []redCorn.LockInfo) error {
+	now := time.Now()
+	for i := range locks {
+		lease, err := l.leases.Get(ctx, leaseName(l.prefix, locks[i].Key), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to inspect locks: %v", err)
+		}
+
+		if ttl := remaining(lease, now); ttl > 0 {
+			locks[i].Locked = true
+			locks[i].Holder = *lease.Spec.HolderIdentity
+			locks[i].TTL = ttl
+		}
+	}
+	return nil
+}
+
+// mutex 一次执行持有的锁
+type mutex struct {
+	leases coordinationclient.LeaseInterface
+	name   string
+	key    string
+	opts   redCorn.LockOptions
+}
+
+// Lock 获取锁，按 Tries 重试
+func (m *mutex) Lock(ctx context.Context) error {
+	tries := m.opts.Tries
+	if tries < 1 {
+		tries = 1
+	}
+	delay := m.opts.RetryDelay
+	if delay <= 0 {
+		delay = retryDelay
+	}
+
+	for i := 0; i < tries; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		err := m.acquire(ctx)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, redCorn.ErrLockHeld) {
+			return err
+		}
+	}
+	return redCorn.ErrLockHeld
+}
+
+// acquire 尝试一次获取锁，Lease 不存在时创建，已过期时接管，并发修改由 resourceVersion 保证只有一方成功
+func (m *mutex) acquire(ctx context.Context) error {
+	now := metav1.NewMicroTime(time.Now())
+	lease, err := m.leases.Get(ctx, m.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        m.name,
+				Annotations: map[string]string{keyAnnotation: m.key},
+			},
+		}
+		m.hold(lease, now)
+		_, err = m.leases.Create(ctx, lease, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			return redCorn.ErrLockHeld
+		}
+		if err != nil {
+			return fmt.Errorf("failed to acquire lock: %v", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to acquire lock: %v", err)
+	}
+
+	if remaining(lease, now.Time) > 0 {
+		return redCorn.ErrLockHeld
+	}
+	m.hold(lease, now)
+	_, err = m.leases.Update(ctx, lease, metav1.UpdateOptions{})
+	if apierrors.IsConflict(err) {
+		return redCorn.ErrLockHeld
+	}
+	if err != nil {
+		return fmt.Errorf("failed to acquire lock: %v", err)
+	}
+	return nil
+}
+
+// hold 将 Lease 的持有者设为本次执行
+func (m *mutex) hold(lease *coordinationv1.Lease, now metav1.MicroTime) {
+	holder := m.opts.Value
+	duration := leaseSeconds(m.opts.Expiry)
+	lease.Spec.HolderIdentity = &holder
+	lease.Spec.LeaseDurationSeconds = &duration
+	lease.Spec.AcquireTime = &now
+	lease.Spec.RenewTime = &now
+}
+
+// Extend 更新 renewTime，Lease 已过期或已被其他执行接管时返回 false
+func (m *mutex) Extend(ctx context.Context) (bool, error) {
+	now := metav1.NewMicroTime(time.Now())
+	lease, err := m.leases.Get(ctx, m.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to extend lock: %v", err)
+	}
+	if !m.heldBy(lease, now.Time) {
+		return false, nil
+	}
+
+	lease.Spec.RenewTime = &now
+	if _, err := m.leases.Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		if apierrors.IsConflict(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to extend lock: %v", err)
+	}
+	return true, nil
+}
+
+// Unlock 仅当 Lease 仍由本次执行持有时删除 Lease
+func (m *mutex) Unlock(ctx context.Context) (bool, error) {
+	lease, err := m.leases.Get(ctx, m.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, redCorn.ErrLockExpired
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to release lock: %v", err)
+	}
+	if !m.heldBy(lease, time.Now()) {
+		return false, redCorn.ErrLockExpired
+	}
+
+	err = m.leases.Delete(ctx, m.name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{ResourceVersion: &lease.ResourceVersion},
+	})
+	if apierrors.IsConflict(err) || apierrors.IsNotFound(err) {
+		return false, redCorn.ErrLockExpired
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to release lock: %v", err)
+	}
+	return true, nil
+}
+
+// heldBy Lease 是否由本次执行持有且未过期
+func (m *mutex) heldBy(lease *coordinationv1.Lease, now time.Time) bool {
+	return remaining(lease, now) > 0 && *lease.Spec.HolderIdentity == m.opts.Value
+}
+
+// remaining Lease 的剩余时间，无持有者或已过期时返回 0
+func remaining(lease *coordinationv1.Lease, now time.Time) time.Duration {
+	spec := lease.Spec
+	if spec.HolderIdentity == nil || *spec.HolderIdentity == "" || spec.RenewTime == nil || spec.LeaseDurationSeconds == nil {
+		return 0
+	}
+	ttl := spec.RenewTime.Add(time.Duration(*spec.LeaseDurationSeconds) * time.Second).Sub(now)
+	if ttl < 0 {
+		return 0
+	}
+	return ttl
+}
+
+// leaseSeconds 锁过期时间换算为 leaseDurationSeconds，向上取整到秒
+func leaseSeconds(expiry time.Duration) int32 {
+	seconds := int32((expiry + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+// leaseName 锁键转换为合法的 Lease 名称：小写字母、数字和 '-'，首尾为字母或数字，
+// 键中含有其他字符时追加键的哈希，避免不同的键映射到同一个 Lease
+func leaseName(prefix, key string) string {
+	raw := prefix + key
+	name := strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return '-'
+	}, raw), "-")
+	if name == raw && len(name) <= maxNameLength {
+		return name
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(raw))
+	suffix := fmt.Sprintf("%08x", h.Sum32())
+	if len(name)+len(suffix)+1 > maxNameLength {
+		name = strings.TrimRight(name[:maxNameLength-len(suffix)-1], "-")
+	}
+	if name == "" {
+		return suffix
+	}
+	return name + "-" + suffix
+}
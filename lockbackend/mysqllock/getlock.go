@@ -0,0 +1,184 @@
+// Package mysqllock 基于 MySQL GET_LOCK/RELEASE_LOCK 实现 redCorn.Locker
+//
+// 命名锁只替换 Redis 上的任务锁，使用本包时任务管理器仍需连接 Redis
+package mysqllock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/kzdgt/redCorn"
+)
+
+const (
+	// retryDelay 配置了重试次数且未配置 RetryDelay 时两次尝试之间的间隔
+	retryDelay = 100 * time.Millisecond
+	// maxNameLength MySQL 5.7 起锁名称的最大长度
+	maxNameLength = 64
+)
+
+// Locker 基于 MySQL 命名锁的分布式锁，通过 GET_LOCK(name, 0) 争抢
+// 命名锁属于数据库会话，每次持有锁期间独占连接池中的一个连接，连接断开时锁由数据库自动释放，
+// 因此锁没有过期时间，LockOptions.Expiry 不生效
+type Locker struct {
+	db     *sql.DB
+	prefix string
+}
+
+var (
+	_ redCorn.Locker        = (*Locker)(nil)
+	_ redCorn.LockInspector = (*Locker)(nil)
+	_ redCorn.LockExpirer   = (*Locker)(nil)
+)
+
+// New 基于 database/sql 连接池创建分布式锁，prefix 会加在锁名称之前，可为空
+// 命名锁在整个 MySQL 实例内共享，多个应用共用实例时应设置 prefix；同时执行的任务数不能超过连接池的最大连接数
+func New(db *sql.DB, prefix string) *Locker {
+	return &Locker{db: db, prefix: prefix}
+}
+
+// ExpiresLocks 锁随数据库会话持有，没有过期时间，不能配合 redCorn.WithHoldLockUntilNextFire 使用
+func (l *Locker) ExpiresLocks() bool {
+	return false
+}
+
+// NewMutex 创建锁
+func (l *Locker) NewMutex(key string, opts redCorn.LockOptions) redCorn.Mutex {
+	return &mutex{
+		db:   l.db,
+		name: lockName(l.prefix, key),
+		opts: opts,
+	}
+}
+
+// InspectLocks 查询持有锁的连接，持有者为 MySQL 连接 ID，锁没有过期时间，TTL 为 -1
+func (l *Locker) InspectLocks(ctx context.Context, locks []redCorn.LockInfo) error {
+	for i := range locks {
+		var connID sql.NullInt64
+		if err := l.db.QueryRowContext(ctx, `SELECT IS_USED_LOCK(?)`, lockName(l.prefix, locks[i].Key)).Scan(&connID); err != nil {
+			return fmt.Errorf("failed to inspect locks: %v", err)
+		}
+		if !connID.Valid {
+			continue
+		}
+
+		locks[i].Locked = true
+		locks[i].Holder = "connection " + strconv.FormatInt(connID.Int64, 10)
+		locks[i].TTL = -1
+	}
+	return nil
+}
+
+// mutex 一次执行持有的锁
+type mutex struct {
+	db   *sql.DB
+	name string
+	opts redCorn.LockOptions
+	conn *sql.Conn
+}
+
+// Lock 获取锁，按 Tries 重试
+func (m *mutex) Lock(ctx context.Context) error {
+	tries := m.opts.Tries
+	if tries < 1 {
+		tries = 1
+	}
+	delay := m.opts.RetryDelay
+	if delay <= 0 {
+		delay = retryDelay
+	}
+
+	for i := 0; i < tries; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		err := m.acquire(ctx)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, redCorn.ErrLockHeld) {
+			return err
+		}
+	}
+	return redCorn.ErrLockHeld
+}
+
+// acquire 尝试一次获取锁，成功时保留连接直到释放锁
+func (m *mutex) acquire(ctx context.Context) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire lock: %v", err)
+	}
+
+	// GET_LOCK 成功返回 1，锁被其他会话持有返回 0，出错返回 NULL
+	var result sql.NullInt64
+	if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, 0)`, m.name).Scan(&result); err != nil {
+		discard(conn)
+		return fmt.Errorf("failed to acquire lock: %v", err)
+	}
+	if !result.Valid {
+		conn.Close()
+		return errors.New("failed to acquire lock: GET_LOCK returned NULL")
+	}
+	if result.Int64 != 1 {
+		conn.Close()
+		return redCorn.ErrLockHeld
+	}
+
+	m.conn = conn
+	return nil
+}
+
+// Extend 确认持有锁的连接仍然存在，命名锁没有过期时间，无需续期
+func (m *mutex) Extend(ctx context.Context) (bool, error) {
+	var held sql.NullBool
+	if err := m.conn.QueryRowContext(ctx, `SELECT IS_USED_LOCK(?) = CONNECTION_ID()`, m.name).Scan(&held); err != nil {
+		return false, fmt.Errorf("failed to extend lock: %v", err)
+	}
+	return held.Valid && held.Bool, nil
+}
+
+// Unlock 释放锁并将连接归还连接池
+func (m *mutex) Unlock(ctx context.Context) (bool, error) {
+	// RELEASE_LOCK 释放成功返回 1，锁不由当前会话持有返回 0，锁不存在返回 NULL
+	var result sql.NullInt64
+	if err := m.conn.QueryRowContext(ctx, `SELECT RELEASE_LOCK(?)`, m.name).Scan(&result); err != nil {
+		discard(m.conn)
+		return false, fmt.Errorf("failed to release lock: %v", err)
+	}
+	m.conn.Close()
+	if !result.Valid || result.Int64 != 1 {
+		return false, redCorn.ErrLockExpired
+	}
+	return true, nil
+}
+
+// discard 关闭连接且不归还连接池，会话可能仍持有锁，连接断开后数据库会释放锁
+func discard(conn *sql.Conn) {
+	_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	conn.Close()
+}
+
+// lockName 生成锁名称，超过 MySQL 的长度限制时截断并追加键的哈希
+func lockName(prefix, key string) string {
+	name := prefix + key
+	if len(name) <= maxNameLength {
+		return name
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	suffix := fmt.Sprintf("-%016x", h.Sum64())
+	return name[:maxNameLength-len(suffix)] + suffix
+}
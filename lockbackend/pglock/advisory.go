@@ -0,0 +1,180 @@
+// Package pglock 基于 PostgreSQL 会话级 advisory lock 实现 redCorn.Locker
+//
+// 数据库只用于加锁，任务状态、执行历史和调度认领等仍由任务管理器写入 Redis
+package pglock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/kzdgt/redCorn"
+)
+
+// retryDelay 配置了重试次数且未配置 RetryDelay 时两次尝试之间的间隔
+const retryDelay = 100 * time.Millisecond
+
+// Locker 基于 PostgreSQL advisory lock 的分布式锁，锁键哈希为 bigint 后通过 pg_try_advisory_lock 争抢
+// advisory lock 属于数据库会话，每次持有锁期间独占连接池中的一个连接，连接断开时锁由数据库自动释放，
+// 因此锁没有过期时间，LockOptions.Expiry 不生效
+type Locker struct {
+	db *sql.DB
+}
+
+var (
+	_ redCorn.Locker        = (*Locker)(nil)
+	_ redCorn.LockInspector = (*Locker)(nil)
+	_ redCorn.LockExpirer   = (*Locker)(nil)
+)
+
+// New 基于 database/sql 连接池创建分布式锁，驱动需支持 $1 形式的占位符（如 pgx、lib/pq）
+// 同时执行的任务数不能超过连接池的最大连接数
+func New(db *sql.DB) *Locker {
+	return &Locker{db: db}
+}
+
+// ExpiresLocks 锁随数据库会话持有，没有过期时间，不能配合 redCorn.WithHoldLockUntilNextFire 使用
+func (l *Locker) ExpiresLocks() bool {
+	return false
+}
+
+// NewMutex 创建锁
+func (l *Locker) NewMutex(key string, opts redCorn.LockOptions) redCorn.Mutex {
+	return &mutex{
+		db:   l.db,
+		id:   lockID(key),
+		opts: opts,
+	}
+}
+
+// InspectLocks 查询 pg_locks 中持有锁的会话，持有者为数据库会话的 pid，锁没有过期时间，TTL 为 -1
+func (l *Locker) InspectLocks(ctx context.Context, locks []redCorn.LockInfo) error {
+	for i := range locks {
+		classID, objID := splitID(lockID(locks[i].Key))
+		var pid int64
+		err := l.db.QueryRowContext(ctx,
+			`SELECT pid FROM pg_locks WHERE locktype = 'advisory' AND classid = $1 AND objid = $2 AND objsubid = 1 AND granted LIMIT 1`,
+			classID, objID).Scan(&pid)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to inspect locks: %v", err)
+		}
+
+		locks[i].Locked = true
+		locks[i].Holder = "pid " + strconv.FormatInt(pid, 10)
+		locks[i].TTL = -1
+	}
+	return nil
+}
+
+// mutex 一次执行持有的锁
+type mutex struct {
+	db   *sql.DB
+	id   int64
+	opts redCorn.LockOptions
+	conn *sql.Conn
+}
+
+// Lock 获取锁，按 Tries 重试
+func (m *mutex) Lock(ctx context.Context) error {
+	tries := m.opts.Tries
+	if tries < 1 {
+		tries = 1
+	}
+	delay := m.opts.RetryDelay
+	if delay <= 0 {
+		delay = retryDelay
+	}
+
+	for i := 0; i < tries; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		err := m.acquire(ctx)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, redCorn.ErrLockHeld) {
+			return err
+		}
+	}
+	return redCorn.ErrLockHeld
+}
+
+// acquire 尝试一次获取锁，成功时保留连接直到释放锁
+func (m *mutex) acquire(ctx context.Context) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire lock: %v", err)
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, m.id).Scan(&acquired); err != nil {
+		discard(conn)
+		return fmt.Errorf("failed to acquire lock: %v", err)
+	}
+	if !acquired {
+		conn.Close()
+		return redCorn.ErrLockHeld
+	}
+
+	m.conn = conn
+	return nil
+}
+
+// Extend 确认持有锁的会话仍然存在，advisory lock 没有过期时间，无需续期
+func (m *mutex) Extend(ctx context.Context) (bool, error) {
+	classID, objID := splitID(m.id)
+	var held bool
+	err := m.conn.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM pg_locks WHERE locktype = 'advisory' AND pid = pg_backend_pid() AND classid = $1 AND objid = $2 AND objsubid = 1 AND granted)`,
+		classID, objID).Scan(&held)
+	if err != nil {
+		return false, fmt.Errorf("failed to extend lock: %v", err)
+	}
+	return held, nil
+}
+
+// Unlock 释放锁并将连接归还连接池
+func (m *mutex) Unlock(ctx context.Context) (bool, error) {
+	var released bool
+	if err := m.conn.QueryRowContext(ctx, `SELECT pg_advisory_unlock($1)`, m.id).Scan(&released); err != nil {
+		discard(m.conn)
+		return false, fmt.Errorf("failed to release lock: %v", err)
+	}
+	m.conn.Close()
+	if !released {
+		return false, redCorn.ErrLockExpired
+	}
+	return true, nil
+}
+
+// discard 关闭连接且不归还连接池，会话可能仍持有锁，连接断开后数据库会释放锁
+func discard(conn *sql.Conn) {
+	_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	conn.Close()
+}
+
+// lockID 锁键哈希为 advisory lock 使用的 bigint
+func lockID(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64())
+}
+
+// splitID bigint 键在 pg_locks 中拆分为 classid（高 32 位）和 objid（低 32 位）
+func splitID(id int64) (int64, int64) {
+	return int64(uint64(id) >> 32), int64(uint32(id))
+}
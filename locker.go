@@ -0,0 +1,329 @@
+package redCorn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	goredislib "github.com/go-redis/redis/v8"
+	"github.com/go-redsync/redsync/v4"
+	"github.com/go-redsync/redsync/v4/redis"
+	"github.com/go-redsync/redsync/v4/redis/goredis/v8"
+)
+
+// ErrLockHeld 锁已被其他执行持有
+var ErrLockHeld = errors.New("lock held by another execution")
+
+// ErrLockExpired 释放锁时锁已过期
+var ErrLockExpired = errors.New("lock already expired")
+
+// ErrLockInspectUnsupported 锁后端不支持查询锁的持有情况
+var ErrLockInspectUnsupported = errors.New("lock backend does not support inspection")
+
+// LockOptions 创建分布式锁的参数
+type LockOptions struct {
+	Expiry      time.Duration // 锁的过期时间
+	Tries       int           // 获取锁的尝试次数，1 表示只尝试一次
+	RetryDelay  time.Duration // 两次尝试之间的间隔，为0时使用后端的默认值
+	DriftFactor float64       // 时钟漂移比例，为0时使用后端的默认值，不计算漂移的后端可以忽略
+	Value       string        // 锁的值，默认格式为 "<节点>/<执行标识>"，用于标识持有者
+}
+
+// Locker 分布式锁的后端，未配置 Cfg.Locker 时使用基于 redsync 的 Redis 实现
+type Locker interface {
+	// NewMutex 为键创建一个锁，每次执行创建一个新的锁
+	NewMutex(key string, opts LockOptions) Mutex
+}
+
+// Mutex 一次执行持有的分布式锁
+type Mutex interface {
+	// Lock 获取锁，锁被其他执行持有时返回 ErrLockHeld
+	Lock(ctx context.Context) error
+	// Extend 将锁的过期时间重置为 Expiry，锁已不再持有时返回 false
+	Extend(ctx context.Context) (bool, error)
+	// Unlock 释放锁，锁已过期时返回 ErrLockExpired
+	Unlock(ctx context.Context) (bool, error)
+}
+
+// LockInspector 可选接口，Locker 实现后 ListTasks 可以展示锁的持有情况
+type LockInspector interface {
+	// InspectLocks 填充 locks 中各键的持有者和剩余时间
+	InspectLocks(ctx context.Context, locks []LockInfo) error
+}
+
+// LockLister 可选接口，Locker 实现后 ListLocks 可以按锁前缀列出所有被持有的锁，包括其他节点注册的任务
+type LockLister interface {
+	// ListLocks 列出键以 prefix 开头、当前被持有的锁，需填充 Key、Locked、Holder 和 TTL
+	ListLocks(ctx context.Context, prefix string) ([]LockInfo, error)
+}
+
+// LockExpirer 可选接口，锁没有过期时间的 Locker 实现并返回 false，
+// 这类锁不能保持到下一次调度时刻，注册配置了 WithHoldLockUntilNextFire 的任务时返回错误；未实现时视为支持过期时间
+type LockExpirer interface {
+	// ExpiresLocks 锁是否会在 Expiry 后自动过期
+	ExpiresLocks() bool
+}
+
+// inspectLocks 查询锁的持有情况，锁后端不支持查询时只保留锁的键
+func (dtm *DistributedTaskManager) inspectLocks(ctx context.Context, locks []LockInfo) error {
+	inspector, ok := dtm.locker.(LockInspector)
+	if !ok {
+		return nil
+	}
+	if err := inspector.InspectLocks(ctx, locks); err != nil {
+		return err
+	}
+	parseHolders(locks)
+	return nil
+}
+
+// connectQuorum 连接 Redlock 使用的 Redis 实例，少数实例不可用时只记录警告，可用实例不足多数时返回错误
+func connectQuorum(ctx context.Context, cfgs []goredislib.UniversalOptions, log Logger) ([]goredislib.UniversalClient, error) {
+	clients := make([]goredislib.UniversalClient, len(cfgs))
+	available := 0
+	for i := range cfgs {
+		clients[i] = goredislib.NewUniversalClient(&cfgs[i])
+		if err := clients[i].Ping(ctx).Err(); err != nil {
+			log.Warn("Redlock instance ", strings.Join(cfgs[i].Addrs, ","), " is unavailable: ", err)
+			continue
+		}
+		available++
+	}
+
+	if quorum := len(cfgs)/2 + 1; available < quorum {
+		for _, client := range clients {
+			client.Close()
+		}
+		return nil, fmt.Errorf("failed to connect to Redlock instances: %d of %d available, need %d", available, len(cfgs), quorum)
+	}
+	return clients, nil
+}
+
+// redisLocker 基于 redsync 的 Redis 分布式锁
+type redisLocker struct {
+	redsync    *redsync.Redsync
+	inspectors []func(ctx context.Context, locks []LockInfo) error          // 逐个实例查询锁的持有情况
+	scanners   []func(ctx context.Context, prefix string) ([]string, error) // 逐个实例扫描锁的键，redsync 连接池不支持扫描时为空
+}
+
+// NewRedisLocker 创建基于 redsync 的 Redis 分布式锁
+// 传入多个相互独立的 Redis 实例时使用 Redlock 算法，在多数实例上加锁成功才算获取到锁
+func NewRedisLocker(clients ...goredislib.UniversalClient) Locker {
+	pools := make([]redis.Pool, len(clients))
+	inspectors := make([]func(ctx context.Context, locks []LockInfo) error, len(clients))
+	scanners := make([]func(ctx context.Context, prefix string) ([]string, error), len(clients))
+	for i, client := range clients {
+		client := client
+		pools[i] = goredis.NewPool(client)
+		inspectors[i] = func(ctx context.Context, locks []LockInfo) error {
+			return inspectLocks(ctx, client, locks)
+		}
+		scanners[i] = func(ctx context.Context, prefix string) ([]string, error) {
+			return scanKeys(ctx, client, prefix)
+		}
+	}
+	return &redisLocker{
+		redsync:    redsync.New(pools...),
+		inspectors: inspectors,
+		scanners:   scanners,
+	}
+}
+
+// NewRedsyncLocker 基于任意 redsync 连接池创建分布式锁，可使用 go-redis 以外的客户端（如 rueidis、redigo）
+// 传入多个连接池时使用 Redlock 算法
+func NewRedsyncLocker(pools ...redis.Pool) Locker {
+	inspectors := make([]func(ctx context.Context, locks []LockInfo) error, len(pools))
+	for i, pool := range pools {
+		pool := pool
+		inspectors[i] = func(ctx context.Context, locks []LockInfo) error {
+			return inspectPool(ctx, pool, locks)
+		}
+	}
+	return &redisLocker{
+		redsync:    redsync.New(pools...),
+		inspectors: inspectors,
+	}
+}
+
+// inspectPool 通过 redsync 连接池读取锁键的值和剩余时间
+func inspectPool(ctx context.Context, pool redis.Pool, locks []LockInfo) error {
+	conn, err := pool.Get(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to inspect locks: %v", err)
+	}
+	defer conn.Close()
+
+	for i := range locks {
+		holder, err := conn.Get(locks[i].Key)
+		if err != nil {
+			return fmt.Errorf("failed to inspect locks: %v", err)
+		}
+		if holder == "" {
+			continue
+		}
+		ttl, err := conn.PTTL(locks[i].Key)
+		if err != nil {
+			return fmt.Errorf("failed to inspect locks: %v", err)
+		}
+		locks[i].Locked = true
+		locks[i].Holder = holder
+		locks[i].TTL = ttl
+	}
+	return nil
+}
+
+// NewMutex 创建 redsync 锁
+func (l *redisLocker) NewMutex(key string, opts LockOptions) Mutex {
+	value := opts.Value
+	options := []redsync.Option{
+		redsync.WithExpiry(opts.Expiry),
+		redsync.WithTries(opts.Tries),
+		redsync.WithGenValueFunc(func() (string, error) { return value, nil }),
+	}
+	if opts.RetryDelay > 0 {
+		options = append(options, redsync.WithRetryDelay(opts.RetryDelay))
+	}
+	if opts.DriftFactor > 0 {
+		options = append(options, redsync.WithDriftFactor(opts.DriftFactor))
+	}
+	return &redisMutex{
+		mutex: l.redsync.NewMutex(key, options...),
+		tries: opts.Tries,
+	}
+}
+
+// InspectLocks 读取锁键的值和剩余时间
+// 多个实例时以多数实例上相同的值为持有者，剩余时间取这些实例中的最小值
+func (l *redisLocker) InspectLocks(ctx context.Context, locks []LockInfo) error {
+	if len(l.inspectors) == 1 {
+		return l.inspectors[0](ctx, locks)
+	}
+
+	quorum := len(l.inspectors)/2 + 1
+	results := make([][]LockInfo, 0, len(l.inspectors))
+	var lastErr error
+	for _, inspect := range l.inspectors {
+		result := make([]LockInfo, len(locks))
+		copy(result, locks)
+		if err := inspect(ctx, result); err != nil {
+			lastErr = err
+			continue
+		}
+		results = append(results, result)
+	}
+	if len(results) < quorum {
+		return lastErr
+	}
+
+	for i := range locks {
+		votes := make(map[string][]time.Duration)
+		for _, result := range results {
+			if result[i].Locked {
+				votes[result[i].Holder] = append(votes[result[i].Holder], result[i].TTL)
+			}
+		}
+		for holder, ttls := range votes {
+			if len(ttls) < quorum {
+				continue
+			}
+			locks[i].Locked = true
+			locks[i].Holder = holder
+			locks[i].TTL = ttls[0]
+			for _, ttl := range ttls[1:] {
+				if ttl < locks[i].TTL {
+					locks[i].TTL = ttl
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ListLocks 在各实例上扫描以 prefix 开头的键，再按 InspectLocks 的规则确定持有者
+func (l *redisLocker) ListLocks(ctx context.Context, prefix string) ([]LockInfo, error) {
+	if len(l.scanners) == 0 {
+		return nil, ErrLockInspectUnsupported
+	}
+
+	quorum := len(l.scanners)/2 + 1
+	seen := make(map[string]bool)
+	var locks []LockInfo
+	var lastErr error
+	available := 0
+	for _, scan := range l.scanners {
+		keys, err := scan(ctx, prefix)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		available++
+		for _, key := range keys {
+			if !seen[key] {
+				seen[key] = true
+				locks = append(locks, LockInfo{Key: key})
+			}
+		}
+	}
+	if available < quorum {
+		return nil, lastErr
+	}
+
+	if err := l.InspectLocks(ctx, locks); err != nil {
+		return nil, err
+	}
+	held := locks[:0]
+	for _, lock := range locks {
+		if lock.Locked {
+			held = append(held, lock)
+		}
+	}
+	return held, nil
+}
+
+// BreakLock 锁的值仍为 holder 时删除锁，多个实例时在多数实例上删除成功才算成功
+func (l *redisLocker) BreakLock(ctx context.Context, key, holder string) (bool, error) {
+	ok, err := l.redsync.NewMutex(key, redsync.WithValue(holder)).UnlockContext(ctx)
+	if errors.Is(err, redsync.ErrLockAlreadyExpired) {
+		return false, nil
+	}
+	return ok, err
+}
+
+// redisMutex redsync 锁
+type redisMutex struct {
+	mutex *redsync.Mutex
+	tries int
+}
+
+// Lock 获取锁，未配置重试时只尝试一次
+// 配置了重试次数时，redsync 在最后一次尝试失败后返回 ErrTaken 而不是 ErrFailed
+func (m *redisMutex) Lock(ctx context.Context) error {
+	var err error
+	if m.tries > 1 {
+		err = m.mutex.LockContext(ctx)
+	} else {
+		err = m.mutex.TryLockContext(ctx)
+	}
+
+	var taken *redsync.ErrTaken
+	if errors.Is(err, redsync.ErrFailed) || errors.As(err, &taken) {
+		return ErrLockHeld
+	}
+	return err
+}
+
+// Extend 续期锁
+func (m *redisMutex) Extend(ctx context.Context) (bool, error) {
+	return m.mutex.ExtendContext(ctx)
+}
+
+// Unlock 释放锁
+func (m *redisMutex) Unlock(ctx context.Context) (bool, error) {
+	ok, err := m.mutex.UnlockContext(ctx)
+	if errors.Is(err, redsync.ErrLockAlreadyExpired) {
+		return false, ErrLockExpired
+	}
+	return ok, err
+}
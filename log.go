@@ -2,8 +2,8 @@ package redCorn
 
 import (
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
+	"strings"
 )
 
 // Logger 日志接口
@@ -25,40 +25,120 @@ type Logger interface {
 	Fatal(args ...interface{})
 }
 
-// DefaultLogger 默认日志实现
-type defaultLogger struct {
-	logger *log.Logger
+// StructuredLogger 支持键值对字段的日志器
+// Cfg.Logger 实现该接口时，任务执行相关的日志以 task、node、run_id、duration、error 等字段输出，而不是拼接为字符串
+type StructuredLogger interface {
+	Logger
+
+	// LogAttrs logs a message at the given level with key/value attributes.
+	LogAttrs(level slog.Level, msg string, attrs ...slog.Attr)
+}
+
+// logRun 输出一次执行相关的日志，runID 为空表示尚未开始执行
+// 结构化日志器以字段输出，其他日志器输出 "Task <任务> [<执行标识>]: <msg> key=value ..."
+func (dtm *DistributedTaskManager) logRun(level slog.Level, task, runID, msg string, attrs ...slog.Attr) {
+	if sl, ok := dtm.log.(StructuredLogger); ok {
+		fields := make([]slog.Attr, 0, len(attrs)+3)
+		fields = append(fields, slog.String("task", task), slog.String("node", dtm.nodeID))
+		if runID != "" {
+			fields = append(fields, slog.String("run_id", runID))
+		}
+		sl.LogAttrs(level, msg, append(fields, attrs...)...)
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("Task ")
+	b.WriteString(runLabel(task, runID))
+	b.WriteString(": ")
+	b.WriteString(msg)
+	for _, attr := range attrs {
+		b.WriteByte(' ')
+		b.WriteString(attr.String())
+	}
+	switch {
+	case level >= slog.LevelError:
+		dtm.log.Error(b.String())
+	case level >= slog.LevelWarn:
+		dtm.log.Warn(b.String())
+	case level >= slog.LevelInfo:
+		dtm.log.Info(b.String())
+	default:
+		dtm.log.Debug(b.String())
+	}
+}
+
+// LogLevel 日志级别
+type LogLevel string
+
+const (
+	LogLevelDebug LogLevel = "debug"
+	LogLevelInfo  LogLevel = "info"
+	LogLevelWarn  LogLevel = "warn"
+	LogLevelError LogLevel = "error"
+)
+
+// slogLevel 转换为 slog 级别
+func (l LogLevel) slogLevel() (slog.Level, error) {
+	switch l {
+	case LogLevelDebug:
+		return slog.LevelDebug, nil
+	case LogLevelInfo:
+		return slog.LevelInfo, nil
+	case LogLevelWarn:
+		return slog.LevelWarn, nil
+	case LogLevelError:
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("invalid log level %q", string(l))
+}
+
+// levelLogger 丢弃低于最低级别的日志
+type levelLogger struct {
+	Logger
+	min slog.Level
 }
 
-// NewDefaultLogger 创建默认日志器
-func newDefaultLogger() *defaultLogger {
-	return &defaultLogger{
-		logger: log.New(os.Stdout, "[RedCorn] ", log.LstdFlags|log.Lshortfile),
+func (l *levelLogger) Debug(args ...interface{}) {
+	if l.min <= slog.LevelDebug {
+		l.Logger.Debug(args...)
 	}
 }
 
-// Debug 调试日志
-func (d *defaultLogger) Debug(args ...interface{}) {
-	d.logger.Println("[DEBUG]", fmt.Sprint(args...))
+func (l *levelLogger) Info(args ...interface{}) {
+	if l.min <= slog.LevelInfo {
+		l.Logger.Info(args...)
+	}
 }
 
-// Info 信息日志
-func (d *defaultLogger) Info(args ...interface{}) {
-	d.logger.Println("[INFO]", fmt.Sprint(args...))
+func (l *levelLogger) Warn(args ...interface{}) {
+	if l.min <= slog.LevelWarn {
+		l.Logger.Warn(args...)
+	}
 }
 
-// Warn 警告日志
-func (d *defaultLogger) Warn(args ...interface{}) {
-	d.logger.Println("[WARN]", fmt.Sprint(args...))
+func (l *levelLogger) Error(args ...interface{}) {
+	if l.min <= slog.LevelError {
+		l.Logger.Error(args...)
+	}
 }
 
-// Error 错误日志
-func (d *defaultLogger) Error(args ...interface{}) {
-	d.logger.Println("[ERROR]", fmt.Sprint(args...))
+// structuredLevelLogger 丢弃低于最低级别的日志，保留结构化输出能力
+type structuredLevelLogger struct {
+	levelLogger
+	structured StructuredLogger
 }
 
-// Fatal 致命错误日志
-func (d *defaultLogger) Fatal(args ...interface{}) {
-	d.logger.Println("[FATAL]", fmt.Sprint(args...))
-	os.Exit(1)
+func (l *structuredLevelLogger) LogAttrs(level slog.Level, msg string, attrs ...slog.Attr) {
+	if level >= l.min {
+		l.structured.LogAttrs(level, msg, attrs...)
+	}
+}
+
+// filterLevel 为日志器加上最低级别过滤
+func filterLevel(logger Logger, min slog.Level) Logger {
+	if sl, ok := logger.(StructuredLogger); ok {
+		return &structuredLevelLogger{levelLogger: levelLogger{Logger: logger, min: min}, structured: sl}
+	}
+	return &levelLogger{Logger: logger, min: min}
 }
@@ -0,0 +1,55 @@
+// Package logrusadapter 将 logrus 日志器适配为 redCorn.Logger
+package logrusadapter
+
+import (
+	"log/slog"
+
+	"github.com/kzdgt/redCorn"
+	"github.com/sirupsen/logrus"
+)
+
+// Logger 基于 logrus 的日志器，实现 redCorn.StructuredLogger，任务执行日志以 logrus 字段输出
+type Logger struct {
+	logger logrus.FieldLogger
+}
+
+var _ redCorn.StructuredLogger = (*Logger)(nil)
+
+// New 基于 logrus.Logger 或 logrus.Entry 创建日志器
+func New(logger logrus.FieldLogger) *Logger {
+	return &Logger{logger: logger}
+}
+
+// Debug 调试日志
+func (l *Logger) Debug(args ...interface{}) { l.logger.Debug(args...) }
+
+// Info 信息日志
+func (l *Logger) Info(args ...interface{}) { l.logger.Info(args...) }
+
+// Warn 警告日志
+func (l *Logger) Warn(args ...interface{}) { l.logger.Warn(args...) }
+
+// Error 错误日志
+func (l *Logger) Error(args ...interface{}) { l.logger.Error(args...) }
+
+// Fatal 致命错误日志
+func (l *Logger) Fatal(args ...interface{}) { l.logger.Fatal(args...) }
+
+// LogAttrs 输出带键值对字段的日志
+func (l *Logger) LogAttrs(level slog.Level, msg string, attrs ...slog.Attr) {
+	fields := make(logrus.Fields, len(attrs))
+	for _, attr := range attrs {
+		fields[attr.Key] = attr.Value.Resolve().Any()
+	}
+	entry := l.logger.WithFields(fields)
+	switch {
+	case level >= slog.LevelError:
+		entry.Error(msg)
+	case level >= slog.LevelWarn:
+		entry.Warn(msg)
+	case level >= slog.LevelInfo:
+		entry.Info(msg)
+	default:
+		entry.Debug(msg)
+	}
+}
@@ -0,0 +1,64 @@
+// Package zapadapter 将 zap.Logger 适配为 redCorn.Logger
+package zapadapter
+
+import (
+	"log/slog"
+
+	"github.com/kzdgt/redCorn"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Logger 基于 zap 的日志器，实现 redCorn.StructuredLogger，任务执行日志以 zap 字段输出
+type Logger struct {
+	logger *zap.Logger
+	sugar  *zap.SugaredLogger
+}
+
+var _ redCorn.StructuredLogger = (*Logger)(nil)
+
+// New 基于 zap.Logger 创建日志器
+func New(logger *zap.Logger) *Logger {
+	// 跳过适配层自身的调用栈，使 caller 指向 redCorn 中的调用位置，结构化日志还需跳过 redCorn 内部的封装
+	return &Logger{
+		logger: logger.WithOptions(zap.AddCallerSkip(2)),
+		sugar:  logger.WithOptions(zap.AddCallerSkip(1)).Sugar(),
+	}
+}
+
+// Debug 调试日志
+func (l *Logger) Debug(args ...interface{}) { l.sugar.Debug(args...) }
+
+// Info 信息日志
+func (l *Logger) Info(args ...interface{}) { l.sugar.Info(args...) }
+
+// Warn 警告日志
+func (l *Logger) Warn(args ...interface{}) { l.sugar.Warn(args...) }
+
+// Error 错误日志
+func (l *Logger) Error(args ...interface{}) { l.sugar.Error(args...) }
+
+// Fatal 致命错误日志
+func (l *Logger) Fatal(args ...interface{}) { l.sugar.Fatal(args...) }
+
+// LogAttrs 输出带键值对字段的日志
+func (l *Logger) LogAttrs(level slog.Level, msg string, attrs ...slog.Attr) {
+	fields := make([]zap.Field, 0, len(attrs))
+	for _, attr := range attrs {
+		fields = append(fields, zap.Any(attr.Key, attr.Value.Resolve().Any()))
+	}
+	l.logger.Log(zapLevel(level), msg, fields...)
+}
+
+// zapLevel 将 slog 级别转换为 zap 级别
+func zapLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level >= slog.LevelError:
+		return zapcore.ErrorLevel
+	case level >= slog.LevelWarn:
+		return zapcore.WarnLevel
+	case level >= slog.LevelInfo:
+		return zapcore.InfoLevel
+	}
+	return zapcore.DebugLevel
+}
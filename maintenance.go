@@ -0,0 +1,178 @@
+package redCorn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// 维护窗口的跳过原因
+const (
+	SkipReasonMaintenance      = "maintenance window"
+	SkipReasonMaintenanceError = "failed to check maintenance windows"
+)
+
+// ErrMaintenanceWindowNotFound 维护窗口不存在
+var ErrMaintenanceWindowNotFound = errors.New("maintenance window not found")
+
+// MaintenanceWindow 维护窗口，窗口内定时调度的执行被跳过，保存在 Redis 中对整个集群生效
+// 一次性窗口指定 Start 和 End；周期性窗口指定 Spec 和 Duration，每次 Spec 触发后的 Duration 内生效
+// Task 和 Group 都为空时对所有任务生效
+type MaintenanceWindow struct {
+	ID        string        `json:"id"`
+	Task      string        `json:"task,omitempty"`  // 只对该任务生效
+	Group     string        `json:"group,omitempty"` // 只对该分组内的任务生效
+	Start     time.Time     `json:"start"`
+	End       time.Time     `json:"end"`
+	Spec      string        `json:"spec,omitempty"`     // 周期性窗口的开始时刻，5位或6位 Cron 表达式，支持 CRON_TZ=
+	Duration  time.Duration `json:"duration,omitempty"` // 周期性窗口的时长
+	Reason    string        `json:"reason,omitempty"`
+	CreatedBy string        `json:"created_by,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+
+	schedule cron.Schedule
+}
+
+// validate 检查并解析维护窗口
+func (w *MaintenanceWindow) validate() error {
+	if w.Spec != "" {
+		if w.Duration <= 0 {
+			return errors.New("duration must be positive for recurring window")
+		}
+		schedule, err := optionalSecondsParser.Parse(w.Spec)
+		if err != nil {
+			return fmt.Errorf("invalid cron spec %q: %v", w.Spec, err)
+		}
+		w.schedule = schedule
+		return nil
+	}
+	if w.Start.IsZero() || !w.End.After(w.Start) {
+		return errors.New("window requires spec and duration, or end after start")
+	}
+	return nil
+}
+
+// Active 窗口在 now 是否生效
+func (w *MaintenanceWindow) Active(now time.Time) bool {
+	if w.Spec == "" {
+		return !now.Before(w.Start) && now.Before(w.End)
+	}
+	if w.schedule == nil && w.validate() != nil {
+		return false
+	}
+	// 在 (now-Duration, now] 内开始的窗口仍在生效
+	start := w.schedule.Next(now.Add(-w.Duration))
+	return !start.IsZero() && !start.After(now)
+}
+
+// expired 一次性窗口是否已结束
+func (w *MaintenanceWindow) expired(now time.Time) bool {
+	return w.Spec == "" && !now.Before(w.End)
+}
+
+// appliesTo 窗口是否对任务生效
+func (w *MaintenanceWindow) appliesTo(name, group string) bool {
+	if w.Task == "" && w.Group == "" {
+		return true
+	}
+	return (w.Task != "" && w.Task == name) || (w.Group != "" && w.Group == group)
+}
+
+// maintenanceKey 保存维护窗口的哈希，字段为窗口标识
+func (c *Controller) maintenanceKey() string {
+	return namespacedKey(c.namespace, "maintenance")
+}
+
+// AddMaintenanceWindow 添加维护窗口，立即对集群中所有节点生效；w.ID 为空时自动生成，返回添加的窗口
+// 同时清理已经结束的一次性窗口
+func (c *Controller) AddMaintenanceWindow(ctx context.Context, w MaintenanceWindow) (MaintenanceWindow, error) {
+	if err := w.validate(); err != nil {
+		return MaintenanceWindow{}, fmt.Errorf("failed to add maintenance window: %v", err)
+	}
+	if w.ID == "" {
+		w.ID = newRunID()
+	}
+	w.CreatedAt = c.clock.Now()
+
+	data, err := json.Marshal(w)
+	if err != nil {
+		return MaintenanceWindow{}, fmt.Errorf("failed to encode maintenance window: %v", err)
+	}
+	if err := c.client.HSet(ctx, c.maintenanceKey(), w.ID, data).Err(); err != nil {
+		return MaintenanceWindow{}, fmt.Errorf("failed to add maintenance window: %v", err)
+	}
+
+	windows, err := c.MaintenanceWindows(ctx)
+	if err == nil {
+		for _, old := range windows {
+			if old.expired(w.CreatedAt) {
+				c.client.HDel(ctx, c.maintenanceKey(), old.ID)
+			}
+		}
+	}
+	return w, nil
+}
+
+// RemoveMaintenanceWindow 删除维护窗口，不存在时返回 ErrMaintenanceWindowNotFound
+func (c *Controller) RemoveMaintenanceWindow(ctx context.Context, id string) error {
+	n, err := c.client.HDel(ctx, c.maintenanceKey(), id).Result()
+	if err != nil {
+		return fmt.Errorf("failed to remove maintenance window %s: %v", id, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s", ErrMaintenanceWindowNotFound, id)
+	}
+	return nil
+}
+
+// MaintenanceWindows 列出所有维护窗口，按创建时间排序
+func (c *Controller) MaintenanceWindows(ctx context.Context) ([]MaintenanceWindow, error) {
+	items, err := c.client.HGetAll(ctx, c.maintenanceKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance windows: %v", err)
+	}
+
+	windows := make([]MaintenanceWindow, 0, len(items))
+	for _, item := range items {
+		var w MaintenanceWindow
+		if err := json.Unmarshal([]byte(item), &w); err != nil {
+			return nil, fmt.Errorf("failed to decode maintenance window: %v", err)
+		}
+		if err := w.validate(); err != nil {
+			return nil, fmt.Errorf("invalid maintenance window %s: %v", w.ID, err)
+		}
+		windows = append(windows, w)
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].CreatedAt.Before(windows[j].CreatedAt) })
+	return windows, nil
+}
+
+// checkMaintenance 检查任务当前是否处于维护窗口内，处于窗口内或检查出错时在执行记录中写入跳过原因
+func (dtm *DistributedTaskManager) checkMaintenance(ctx context.Context, t *distributedTask, rec *ExecutionRecord) bool {
+	windows, err := dtm.controller.MaintenanceWindows(ctx)
+	if err != nil {
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonMaintenanceError
+		rec.setError(err)
+		dtm.logRun(slog.LevelError, t.name, rec.RunID, "Failed to check maintenance windows, skipping execution", slog.Any("error", err))
+		return false
+	}
+
+	now := dtm.clock.Now()
+	for _, w := range windows {
+		if w.appliesTo(t.name, t.opts.group) && w.Active(now) {
+			rec.Status = ExecutionSkipped
+			rec.SkipReason = SkipReasonMaintenance
+			dtm.logRun(slog.LevelInfo, t.name, rec.RunID, "In maintenance window, skipping execution",
+				slog.String("window", w.ID), slog.String("reason", w.Reason))
+			return false
+		}
+	}
+	return true
+}
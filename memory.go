@@ -0,0 +1,226 @@
+package redCorn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LockBackend 内置的锁后端
+type LockBackend string
+
+const (
+	// LockBackendRedis 使用 RedisCfg 连接的 Redis（默认）
+	LockBackendRedis LockBackend = "redis"
+	// LockBackendMemory 进程内的锁，任务状态、执行历史等也保存在进程内，不需要 Redis 服务，
+	// 需要导入 github.com/kzdgt/redCorn/memorybackend 提供进程内的 Redis；仅适用于单元测试和本地开发，多个进程之间不互斥
+	LockBackendMemory LockBackend = "memory"
+)
+
+// errMemoryBackendNotRegistered 使用 LockBackendMemory 但没有导入 memorybackend 包
+var errMemoryBackendNotRegistered = errors.New("LockBackendMemory requires importing github.com/kzdgt/redCorn/memorybackend")
+
+// memoryLocker 进程内的锁
+type memoryLocker struct {
+	clock Clock // 计算锁的过期时间
+	mu    sync.Mutex
+	locks map[string]*memoryLock
+}
+
+// memoryLock 被持有的锁
+type memoryLock struct {
+	value   string
+	expires time.Time
+}
+
+// NewMemoryLocker 创建进程内的锁，同一进程中的多个任务管理器共享时互斥，可在单元测试中代替 Redis
+func NewMemoryLocker() Locker {
+	return newMemoryLocker(realClock{})
+}
+
+// newMemoryLocker 创建按 clock 计算过期时间的进程内锁，LockBackendMemory 使用任务管理器的时钟
+func newMemoryLocker(clock Clock) *memoryLocker {
+	return &memoryLocker{clock: clock, locks: make(map[string]*memoryLock)}
+}
+
+// NewMutex 创建锁
+func (l *memoryLocker) NewMutex(key string, opts LockOptions) Mutex {
+	return &memoryMutex{locker: l, key: key, opts: opts}
+}
+
+// InspectLocks 读取锁的持有者和剩余时间
+func (l *memoryLocker) InspectLocks(ctx context.Context, locks []LockInfo) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	for i := range locks {
+		if lock := l.held(locks[i].Key, now); lock != nil {
+			locks[i].Locked = true
+			locks[i].Holder = lock.value
+			locks[i].TTL = lock.expires.Sub(now)
+		}
+	}
+	return nil
+}
+
+// ListLocks 列出键以 prefix 开头的锁
+func (l *memoryLocker) ListLocks(ctx context.Context, prefix string) ([]LockInfo, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	var locks []LockInfo
+	for key := range l.locks {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if lock := l.held(key, now); lock != nil {
+			locks = append(locks, LockInfo{Key: key, Locked: true, Holder: lock.value, TTL: lock.expires.Sub(now)})
+		}
+	}
+	return locks, nil
+}
+
+// BreakLock 锁的值仍为 holder 时删除锁
+func (l *memoryLocker) BreakLock(ctx context.Context, key, holder string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lock := l.held(key, l.clock.Now())
+	if lock == nil || lock.value != holder {
+		return false, nil
+	}
+	delete(l.locks, key)
+	return true, nil
+}
+
+// held 未过期的锁，调用方需持有 l.mu
+func (l *memoryLocker) held(key string, now time.Time) *memoryLock {
+	lock, ok := l.locks[key]
+	if !ok {
+		return nil
+	}
+	if !now.Before(lock.expires) {
+		delete(l.locks, key)
+		return nil
+	}
+	return lock
+}
+
+// memoryMutex 一次执行持有的进程内锁
+type memoryMutex struct {
+	locker *memoryLocker
+	key    string
+	opts   LockOptions
+}
+
+// Lock 获取锁，按 Tries 重试
+func (m *memoryMutex) Lock(ctx context.Context) error {
+	for i := 0; ; i++ {
+		if m.tryLock() {
+			return nil
+		}
+		if i+1 >= m.opts.Tries {
+			return ErrLockHeld
+		}
+		delay := m.opts.RetryDelay
+		if delay <= 0 {
+			delay = 50 * time.Millisecond
+		}
+		timer := m.locker.clock.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.Chan():
+		}
+	}
+}
+
+// tryLock 尝试一次获取锁
+func (m *memoryMutex) tryLock() bool {
+	l := m.locker
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	if l.held(m.key, now) != nil {
+		return false
+	}
+	l.locks[m.key] = &memoryLock{value: m.opts.Value, expires: now.Add(m.opts.Expiry)}
+	return true
+}
+
+// Extend 将过期时间重置为 Expiry
+func (m *memoryMutex) Extend(ctx context.Context) (bool, error) {
+	l := m.locker
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	lock := l.held(m.key, now)
+	if lock == nil || lock.value != m.opts.Value {
+		return false, nil
+	}
+	lock.expires = now.Add(m.opts.Expiry)
+	return true, nil
+}
+
+// Unlock 释放锁
+func (m *memoryMutex) Unlock(ctx context.Context) (bool, error) {
+	l := m.locker
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lock := l.held(m.key, l.clock.Now())
+	if lock == nil || lock.value != m.opts.Value {
+		return false, ErrLockExpired
+	}
+	delete(l.locks, m.key)
+	return true, nil
+}
+
+// MemoryServer 进程内的 Redis 服务，返回监听地址和停止服务的函数
+type MemoryServer func() (addr string, stop func(), err error)
+
+var (
+	memoryServerMu sync.RWMutex
+	memoryServer   MemoryServer
+)
+
+// RegisterMemoryServer 注册 LockBackendMemory 使用的进程内 Redis 服务，由 memorybackend 包在导入时调用，
+// 进程内 Redis 只用于测试，因此不作为核心模块的依赖
+func RegisterMemoryServer(server MemoryServer) {
+	memoryServerMu.Lock()
+	defer memoryServerMu.Unlock()
+	memoryServer = server
+}
+
+// registeredMemoryServer 已注册的进程内 Redis 服务，未注册时为 nil
+func registeredMemoryServer() MemoryServer {
+	memoryServerMu.RLock()
+	defer memoryServerMu.RUnlock()
+	return memoryServer
+}
+
+// memoryRedis 进程内的 Redis，memory 后端用它保存任务状态、执行历史并传递控制指令
+type memoryRedis struct {
+	addr string
+	stop func()
+}
+
+// startMemoryRedis 启动已注册的进程内 Redis
+func startMemoryRedis() (*memoryRedis, error) {
+	server := registeredMemoryServer()
+	if server == nil {
+		return nil, errMemoryBackendNotRegistered
+	}
+	addr, stop, err := server()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start in-memory Redis: %v", err)
+	}
+	return &memoryRedis{addr: addr, stop: stop}, nil
+}
+
+// close 停止进程内的 Redis
+func (r *memoryRedis) close() {
+	r.stop()
+}
@@ -0,0 +1,56 @@
+// Package memorybackend 为 redCorn.LockBackendMemory 提供进程内的 Redis（miniredis），导入后即可使用进程内后端：
+//
+//	import _ "github.com/kzdgt/redCorn/memorybackend"
+//
+//	dtm, err := redCorn.NewDistributedTaskManager(redCorn.Cfg{
+//		LockBackend: redCorn.LockBackendMemory,
+//		LockCfg:     redCorn.LockCfg{Prefix: "test:lock:", Expiry: 10 * time.Second},
+//	})
+//
+// 仅适用于单元测试和本地开发，单独成为一个模块，核心模块不依赖 miniredis
+package memorybackend
+
+import (
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/kzdgt/redCorn"
+)
+
+// tick 推进键过期时间的间隔
+const tick = time.Second
+
+func init() {
+	redCorn.RegisterMemoryServer(Start)
+}
+
+// Start 启动进程内的 Redis，返回监听地址和停止服务的函数
+// miniredis 不会自动让键过期，由后台协程按实际流逝的时间推进
+func Start() (string, func(), error) {
+	server, err := miniredis.Run()
+	if err != nil {
+		return "", nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(tick)
+		defer ticker.Stop()
+		last := time.Now()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				server.FastForward(now.Sub(last))
+				last = now
+			}
+		}
+	}()
+
+	stop := func() {
+		close(done)
+		server.Close()
+	}
+	return server.Addr(), stop, nil
+}
@@ -0,0 +1,719 @@
+package memorybackend_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kzdgt/redCorn"
+	"github.com/kzdgt/redCorn/memorybackend"
+)
+
+// epoch 测试时钟的起始时间，位于整分钟，"*/10 * * * * *" 的下一次调度时刻为10秒后
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// waitTimeout 等待任务执行的最长时间，任务由时钟触发，正常情况下立即执行
+const waitTimeout = 5 * time.Second
+
+// testCfg 使用进程内后端和 clock 的配置
+func testCfg(clock redCorn.Clock, instance string) redCorn.Cfg {
+	return redCorn.Cfg{
+		LockBackend: redCorn.LockBackendMemory,
+		LockCfg:     redCorn.LockCfg{Prefix: "test:lock:", Expiry: 10 * time.Second},
+		Clock:       clock,
+		InstanceID:  instance,
+		LogLevel:    redCorn.LogLevelError,
+	}
+}
+
+// startManager 按 cfg 创建任务管理器，测试结束时停止
+func startManager(t *testing.T, cfg redCorn.Cfg) *redCorn.DistributedTaskManager {
+	t.Helper()
+	dtm, err := redCorn.NewDistributedTaskManager(cfg)
+	if err != nil {
+		t.Fatalf("NewDistributedTaskManager: %v", err)
+	}
+	t.Cleanup(dtm.Stop)
+	return dtm
+}
+
+// newManager 创建使用进程内后端和 clock 的任务管理器，locker 不为 nil 时多个管理器共享该锁
+func newManager(t *testing.T, clock redCorn.Clock, instance string, locker redCorn.Locker) *redCorn.DistributedTaskManager {
+	t.Helper()
+	cfg := testCfg(clock, instance)
+	cfg.Locker = locker
+	return startManager(t, cfg)
+}
+
+// startRedis 启动多个任务管理器共用的进程内 Redis，返回其地址
+func startRedis(t *testing.T) string {
+	t.Helper()
+	addr, stop, err := memorybackend.Start()
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(stop)
+	return addr
+}
+
+// newClusterManager 创建连接 addr 上共用 Redis 的任务管理器，各管理器使用自己的进程内锁，互斥只能依赖 Redis 中的记录
+func newClusterManager(t *testing.T, clock redCorn.Clock, instance, addr string) *redCorn.DistributedTaskManager {
+	t.Helper()
+	cfg := testCfg(clock, instance)
+	cfg.LockBackend = redCorn.LockBackendRedis
+	cfg.RedisCfg.Addrs = []string{addr}
+	cfg.Locker = redCorn.NewMemoryLocker()
+	return startManager(t, cfg)
+}
+
+// subscribe 订阅任务管理器的事件
+func subscribe(t *testing.T, dtm *redCorn.DistributedTaskManager) <-chan redCorn.TaskEvent {
+	sub, unsubscribe := dtm.Subscribe(20)
+	t.Cleanup(unsubscribe)
+	return sub
+}
+
+// expectEvent 等待任务 task 的 typ 类型事件，忽略其间的其他事件
+func expectEvent(t *testing.T, events <-chan redCorn.TaskEvent, task string, typ redCorn.EventType) redCorn.TaskEvent {
+	t.Helper()
+	timeout := time.After(waitTimeout)
+	for {
+		select {
+		case e := <-events:
+			if e.Task == task && e.Type == typ {
+				return e
+			}
+		case <-timeout:
+			t.Fatalf("no %s event of task %s", typ, task)
+		}
+	}
+}
+
+// expectSkip 等待任务 task 以 reason 原因跳过
+func expectSkip(t *testing.T, events <-chan redCorn.TaskEvent, task, reason string) {
+	t.Helper()
+	if e := expectEvent(t, events, task, redCorn.EventSkipped); e.SkipReason != reason {
+		t.Fatalf("%s skipped because %q, want %q", task, e.SkipReason, reason)
+	}
+}
+
+// expectRun 等待任务执行一次
+func expectRun(t *testing.T, runs <-chan string, want string) {
+	t.Helper()
+	select {
+	case got := <-runs:
+		if got != want {
+			t.Fatalf("ran %s, want %s", got, want)
+		}
+	case <-time.After(waitTimeout):
+		t.Fatalf("task %s did not run", want)
+	}
+}
+
+// expectNoRun 确认没有任务执行
+func expectNoRun(t *testing.T, runs <-chan string) {
+	t.Helper()
+	select {
+	case got := <-runs:
+		t.Fatalf("unexpected run of %s", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestFakeClockFiresSchedule(t *testing.T) {
+	clock := redCorn.NewFakeClock(epoch)
+	dtm := newManager(t, clock, "a", nil)
+
+	runs := make(chan string, 10)
+	if err := dtm.AddTask("tick", "*/10 * * * * *", func() { runs <- "tick" }); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	dtm.Start()
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+	expectRun(t, runs, "tick")
+
+	// 未到下一次调度时刻时不触发
+	clock.BlockUntil(1)
+	clock.Advance(5 * time.Second)
+	expectNoRun(t, runs)
+
+	clock.BlockUntil(1)
+	clock.Advance(5 * time.Second)
+	expectRun(t, runs, "tick")
+}
+
+func TestAddTaskAfterStart(t *testing.T) {
+	clock := redCorn.NewFakeClock(epoch)
+	dtm := newManager(t, clock, "a", nil)
+	dtm.Start()
+
+	runs := make(chan string, 10)
+	if err := dtm.AddTask("late", "*/10 * * * * *", func() { runs <- "late" }); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+	expectRun(t, runs, "late")
+}
+
+func TestAddSchedulerAfterStart(t *testing.T) {
+	clock := redCorn.NewFakeClock(epoch)
+	dtm := newManager(t, clock, "a", nil)
+	dtm.Start()
+
+	runs := make(chan string, 10)
+	ts := redCorn.NewTaskScheduler()
+	if err := ts.Register("every-10s", "*/10 * * * * *", func() { runs <- "every-10s" }); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := ts.Register("every-20s", "*/20 * * * * *", func() { runs <- "every-20s" }); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := dtm.AddScheduler(ts); err != nil {
+		t.Fatalf("AddScheduler: %v", err)
+	}
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+	expectRun(t, runs, "every-10s")
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+	got := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case name := <-runs:
+			got[name] = true
+		case <-time.After(waitTimeout):
+			t.Fatalf("only %v ran", got)
+		}
+	}
+	if !got["every-10s"] || !got["every-20s"] {
+		t.Fatalf("ran %v, want both tasks", got)
+	}
+}
+
+func TestLockContention(t *testing.T) {
+	clock := redCorn.NewFakeClock(epoch)
+	locker := redCorn.NewMemoryLocker()
+	managers := []*redCorn.DistributedTaskManager{
+		newManager(t, clock, "a", locker),
+		newManager(t, clock, "b", locker),
+	}
+
+	var executions atomic.Int32
+	release := make(chan struct{})
+	events := make(chan redCorn.TaskEvent, 10)
+	for _, dtm := range managers {
+		sub, unsubscribe := dtm.Subscribe(10)
+		t.Cleanup(unsubscribe)
+		go func() {
+			for e := range sub {
+				events <- e
+			}
+		}()
+
+		// 执行中的任务一直持有锁，直到另一个节点因锁被占用而跳过
+		err := dtm.AddTask("report", "*/10 * * * * *", func() {
+			executions.Add(1)
+			<-release
+		})
+		if err != nil {
+			t.Fatalf("AddTask: %v", err)
+		}
+		dtm.Start()
+	}
+
+	clock.BlockUntil(len(managers))
+	clock.Advance(10 * time.Second)
+
+	var started, skipped int
+	for started+skipped < len(managers) {
+		select {
+		case e := <-events:
+			switch e.Type {
+			case redCorn.EventStarted:
+				started++
+			case redCorn.EventSkipped:
+				if e.SkipReason != redCorn.SkipReasonLockHeld {
+					t.Fatalf("skipped because %q, want %q", e.SkipReason, redCorn.SkipReasonLockHeld)
+				}
+				skipped++
+			}
+		case <-time.After(waitTimeout):
+			t.Fatalf("started %d, skipped %d executions", started, skipped)
+		}
+	}
+	close(release)
+
+	if started != 1 || skipped != 1 {
+		t.Fatalf("started %d, skipped %d executions, want 1 and 1", started, skipped)
+	}
+	if n := executions.Load(); n != 1 {
+		t.Fatalf("task executed %d times, want 1", n)
+	}
+}
+
+// sessionLocker 模拟随会话持有、不会过期的锁后端
+type sessionLocker struct {
+	redCorn.Locker
+}
+
+func (sessionLocker) ExpiresLocks() bool { return false }
+
+func TestHoldLockRequiresExpiringLocker(t *testing.T) {
+	dtm := newManager(t, redCorn.NewFakeClock(epoch), "a", sessionLocker{redCorn.NewMemoryLocker()})
+
+	err := dtm.AddTask("report", "*/10 * * * * *", func() {}, redCorn.WithHoldLockUntilNextFire())
+	if err == nil {
+		t.Fatal("AddTask with a non-expiring locker succeeded")
+	}
+	if err := dtm.AddTask("report", "*/10 * * * * *", func() {}); err != nil {
+		t.Fatalf("AddTask without hold lock: %v", err)
+	}
+}
+
+// 时钟偏差等原因使多个节点都获取到锁时，同一调度时刻仍只执行一次
+func TestTickClaimAcrossNodes(t *testing.T) {
+	addr := startRedis(t)
+	clock := redCorn.NewFakeClock(epoch)
+	managers := []*redCorn.DistributedTaskManager{
+		newClusterManager(t, clock, "a", addr),
+		newClusterManager(t, clock, "b", addr),
+	}
+
+	var executions atomic.Int32
+	events := make(chan redCorn.TaskEvent, 10)
+	for _, dtm := range managers {
+		sub := subscribe(t, dtm)
+		go func() {
+			for e := range sub {
+				events <- e
+			}
+		}()
+		if err := dtm.AddTask("report", "*/10 * * * * *", func() { executions.Add(1) }); err != nil {
+			t.Fatalf("AddTask: %v", err)
+		}
+		dtm.Start()
+	}
+
+	clock.BlockUntil(len(managers))
+	clock.Advance(10 * time.Second)
+
+	var succeeded, skipped int
+	for succeeded+skipped < len(managers) {
+		select {
+		case e := <-events:
+			switch e.Type {
+			case redCorn.EventSucceeded:
+				succeeded++
+			case redCorn.EventSkipped:
+				if e.SkipReason != redCorn.SkipReasonTickDone {
+					t.Fatalf("skipped because %q, want %q", e.SkipReason, redCorn.SkipReasonTickDone)
+				}
+				skipped++
+			}
+		case <-time.After(waitTimeout):
+			t.Fatalf("succeeded %d, skipped %d executions", succeeded, skipped)
+		}
+	}
+	if n := executions.Load(); n != 1 {
+		t.Fatalf("task executed %d times, want 1", n)
+	}
+}
+
+// 执行结束后锁保持到下一次调度时刻之前，移除任务时立即释放
+func TestHoldLockUntilNextFire(t *testing.T) {
+	locker := redCorn.NewMemoryLocker()
+	clock := redCorn.NewFakeClock(epoch)
+	holder := newManager(t, clock, "a", locker)
+	other := newManager(t, redCorn.NewFakeClock(epoch), "b", locker)
+
+	holderEvents := subscribe(t, holder)
+	otherEvents := subscribe(t, other)
+	if err := holder.AddTask("report", "*/10 * * * * *", func() {}, redCorn.WithHoldLockUntilNextFire()); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	// 另一个节点上同名的任务使用同一个锁，只手动触发
+	if err := other.AddTask("report", redCorn.ManualSpec, func() {}); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	holder.Start()
+	other.Start()
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+	expectEvent(t, holderEvents, "report", redCorn.EventSucceeded)
+
+	if err := other.TriggerTask("report"); err != nil {
+		t.Fatalf("TriggerTask: %v", err)
+	}
+	expectSkip(t, otherEvents, "report", redCorn.SkipReasonLockHeld)
+
+	if err := holder.RemoveTask("report"); err != nil {
+		t.Fatalf("RemoveTask: %v", err)
+	}
+	if err := other.TriggerTask("report"); err != nil {
+		t.Fatalf("TriggerTask: %v", err)
+	}
+	expectEvent(t, otherEvents, "report", redCorn.EventSucceeded)
+}
+
+// 看门狗续期期间锁一直由执行持有，执行结束后释放
+func TestWatchdogExtendsLock(t *testing.T) {
+	clock := redCorn.NewFakeClock(epoch)
+	cfg := testCfg(clock, "a")
+	cfg.LockCfg.ExtendInterval = 3 * time.Second
+	dtm := startManager(t, cfg)
+	events := subscribe(t, dtm)
+
+	release := make(chan struct{})
+	if err := dtm.AddTask("report", "0 0 * * * *", func() { <-release }); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	dtm.Start()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	expectEvent(t, events, "report", redCorn.EventStarted)
+
+	// 调度器和看门狗各等待一个定时器，快进的总时间超过锁的过期时间
+	for i := 0; i < 5; i++ {
+		clock.BlockUntil(2)
+		clock.Advance(3 * time.Second)
+	}
+	clock.BlockUntil(2)
+	lock, err := dtm.LockHolder("report")
+	if err != nil {
+		t.Fatalf("LockHolder: %v", err)
+	}
+	if !lock.Locked || lock.TTL > cfg.LockCfg.Expiry {
+		t.Fatalf("lock = %+v, want held for at most %s", lock, cfg.LockCfg.Expiry)
+	}
+
+	close(release)
+	expectEvent(t, events, "report", redCorn.EventSucceeded)
+	if lock, err := dtm.LockHolder("report"); err != nil || lock.Locked {
+		t.Fatalf("lock after execution = %+v, %v, want released", lock, err)
+	}
+}
+
+// 失败的执行按退避时间重试，等待按时钟计算
+func TestRetryBackoff(t *testing.T) {
+	clock := redCorn.NewFakeClock(epoch)
+	dtm := newManager(t, clock, "a", nil)
+	events := subscribe(t, dtm)
+
+	attempts := make(chan int, 10)
+	var calls atomic.Int32
+	err := dtm.AddTaskE("flaky", "0 0 * * * *", func(ctx context.Context) error {
+		n := calls.Add(1)
+		attempts <- int(n)
+		if n < 3 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	}, redCorn.WithRetry(redCorn.RetryPolicy{MaxRetries: 3, InitialBackoff: 2 * time.Second}))
+	if err != nil {
+		t.Fatalf("AddTaskE: %v", err)
+	}
+	dtm.Start()
+
+	expectAttempt := func(want int) {
+		t.Helper()
+		select {
+		case got := <-attempts:
+			if got != want {
+				t.Fatalf("attempt %d, want %d", got, want)
+			}
+		case <-time.After(waitTimeout):
+			t.Fatalf("attempt %d did not run", want)
+		}
+	}
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	expectAttempt(1)
+
+	// 第一次重试前等待2秒，未到时间时不重试
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+	select {
+	case n := <-attempts:
+		t.Fatalf("attempt %d ran before backoff elapsed", n)
+	case <-time.After(100 * time.Millisecond):
+	}
+	clock.Advance(time.Second)
+	expectAttempt(2)
+
+	// 第二次重试前的等待按倍数增加到4秒
+	clock.BlockUntil(2)
+	clock.Advance(4 * time.Second)
+	expectAttempt(3)
+	expectEvent(t, events, "flaky", redCorn.EventSucceeded)
+}
+
+// 上游任务执行成功后触发下游任务
+func TestThenTriggersDownstream(t *testing.T) {
+	clock := redCorn.NewFakeClock(epoch)
+	dtm := newManager(t, clock, "a", nil)
+
+	runs := make(chan string, 10)
+	if err := dtm.AddTask("extract", "*/10 * * * * *", func() { runs <- "extract" }, redCorn.WithThen("transform")); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	if err := dtm.AddTask("transform", redCorn.ManualSpec, func() { runs <- "transform" }); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	dtm.Start()
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+	expectRun(t, runs, "extract")
+	expectRun(t, runs, "transform")
+}
+
+// 依赖任务在前置任务都成功后才执行
+func TestDependsOn(t *testing.T) {
+	clock := redCorn.NewFakeClock(epoch)
+	dtm := newManager(t, clock, "a", nil)
+	events := subscribe(t, dtm)
+
+	runs := make(chan string, 10)
+	for _, name := range []string{"extract", "load"} {
+		name := name
+		if err := dtm.AddTask(name, redCorn.ManualSpec, func() { runs <- name }); err != nil {
+			t.Fatalf("AddTask: %v", err)
+		}
+	}
+	if err := dtm.AddTask("report", redCorn.ManualSpec, func() { runs <- "report" }, redCorn.WithDependsOn("extract", "load")); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	if err := dtm.AddTask("summary", "*/10 * * * * *", func() { runs <- "summary" }, redCorn.WithDependsOn("extract")); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	dtm.Start()
+
+	// 定时调度的依赖任务在前置任务成功之前跳过
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+	expectSkip(t, events, "summary", redCorn.SkipReasonDependencies)
+
+	// 只有一个前置任务成功时不触发
+	if err := dtm.TriggerTask("extract"); err != nil {
+		t.Fatalf("TriggerTask: %v", err)
+	}
+	expectRun(t, runs, "extract")
+	expectNoRun(t, runs)
+
+	if err := dtm.TriggerTask("load"); err != nil {
+		t.Fatalf("TriggerTask: %v", err)
+	}
+	expectRun(t, runs, "load")
+	expectRun(t, runs, "report")
+
+	// 前置任务已在本窗口内成功，下一次调度时执行
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+	expectRun(t, runs, "summary")
+}
+
+// 超出配额的执行跳过，下一个窗口重新计数
+func TestQuota(t *testing.T) {
+	clock := redCorn.NewFakeClock(epoch)
+	dtm := newManager(t, clock, "a", nil)
+	events := subscribe(t, dtm)
+
+	if err := dtm.AddTask("sync", "*/10 * * * * *", func() {}, redCorn.WithQuota(2, time.Minute)); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	dtm.Start()
+
+	for i := 0; i < 2; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(10 * time.Second)
+		expectEvent(t, events, "sync", redCorn.EventSucceeded)
+	}
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+	expectSkip(t, events, "sync", redCorn.SkipReasonQuotaExceeded)
+
+	// 一次快进到下一分钟，只触发一次
+	clock.BlockUntil(1)
+	clock.Advance(30 * time.Second)
+	expectEvent(t, events, "sync", redCorn.EventSucceeded)
+}
+
+// 达到最大执行次数后移除任务，计数器保留一段时间后过期
+func TestMaxRuns(t *testing.T) {
+	clock := redCorn.NewFakeClock(epoch)
+	dtm := newManager(t, clock, "a", nil)
+
+	runs := make(chan string, 10)
+	if err := dtm.AddTask("limited", "*/10 * * * * *", func() { runs <- "limited" }, redCorn.WithMaxRuns(2)); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	dtm.Start()
+
+	for i := 0; i < 2; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(10 * time.Second)
+		expectRun(t, runs, "limited")
+	}
+
+	deadline := time.Now().Add(waitTimeout)
+	for {
+		if _, err := dtm.NextRuns("limited", 1); errors.Is(err, redCorn.ErrTaskNotFound) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("task was not removed after max runs")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ttl, err := dtm.GetRedisClient().TTL(context.Background(), "redcorn:runs:limited").Result()
+	if err != nil {
+		t.Fatalf("TTL: %v", err)
+	}
+	if ttl <= 0 {
+		t.Fatalf("run count TTL = %s, want an expiry", ttl)
+	}
+}
+
+// 移除任务时删除执行次数计数器，同名重新添加的任务重新计数
+func TestRemoveTaskDeletesRunCount(t *testing.T) {
+	clock := redCorn.NewFakeClock(epoch)
+	dtm := newManager(t, clock, "a", nil)
+
+	runs := make(chan string, 10)
+	if err := dtm.AddTask("limited", "*/10 * * * * *", func() { runs <- "limited" }, redCorn.WithMaxRuns(5)); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	dtm.Start()
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+	expectRun(t, runs, "limited")
+
+	if err := dtm.RemoveTask("limited"); err != nil {
+		t.Fatalf("RemoveTask: %v", err)
+	}
+	n, err := dtm.GetRedisClient().Exists(context.Background(), "redcorn:runs:limited").Result()
+	if err != nil {
+		t.Fatalf("Exists: %v", err)
+	}
+	if n != 0 {
+		t.Fatal("run count was not deleted")
+	}
+}
+
+// 工作协程都在忙时执行按优先级排队，队列满时丢弃
+func TestWorkerPoolPriorityAndOverflow(t *testing.T) {
+	cfg := testCfg(redCorn.NewFakeClock(epoch), "a")
+	cfg.MaxConcurrentTasks = 1
+	cfg.MaxQueuedTasks = 2
+	dtm := startManager(t, cfg)
+	events := subscribe(t, dtm)
+
+	release := make(chan struct{})
+	runs := make(chan string, 10)
+	if err := dtm.AddTask("busy", redCorn.ManualSpec, func() { <-release }); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	tasks := []struct {
+		name     string
+		priority int
+	}{{"low", 0}, {"high", 10}, {"extra", 0}}
+	for _, task := range tasks {
+		name := task.name
+		if err := dtm.AddTask(name, redCorn.ManualSpec, func() { runs <- name }, redCorn.WithPriority(task.priority)); err != nil {
+			t.Fatalf("AddTask: %v", err)
+		}
+	}
+	dtm.Start()
+
+	trigger := func(name string) {
+		t.Helper()
+		if err := dtm.TriggerTask(name); err != nil {
+			t.Fatalf("TriggerTask: %v", err)
+		}
+	}
+	trigger("busy")
+	expectEvent(t, events, "busy", redCorn.EventStarted)
+	trigger("low")
+	waitQueued(t, dtm, "low")
+	trigger("high")
+	waitQueued(t, dtm, "high")
+
+	trigger("extra")
+	expectSkip(t, events, "extra", redCorn.SkipReasonQueueFull)
+
+	close(release)
+	expectRun(t, runs, "high")
+	expectRun(t, runs, "low")
+	expectNoRun(t, runs)
+}
+
+// waitQueued 等待任务 name 在本地队列中排队
+func waitQueued(t *testing.T, dtm *redCorn.DistributedTaskManager, name string) {
+	t.Helper()
+	deadline := time.Now().Add(waitTimeout)
+	for time.Now().Before(deadline) {
+		for _, s := range dtm.Stats() {
+			if s.Task == name && s.Queued > 0 {
+				return
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("task %s was not queued", name)
+}
+
+// 重新加载配置文件时添加新任务、移除删掉的任务
+func TestReloadScheduleConfig(t *testing.T) {
+	clock := redCorn.NewFakeClock(epoch)
+	dtm := newManager(t, clock, "a", nil)
+
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	writeConfig := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig(`{"tasks": {"old": {"cron": "*/10 * * * * *"}}}`)
+
+	runs := make(chan string, 10)
+	handlers := map[string]func(ctx context.Context) error{
+		"old": func(ctx context.Context) error { runs <- "old"; return nil },
+		"new": func(ctx context.Context) error { runs <- "new"; return nil },
+	}
+	watcher, err := dtm.WatchScheduleConfig(path, handlers)
+	if err != nil {
+		t.Fatalf("WatchScheduleConfig: %v", err)
+	}
+	t.Cleanup(func() { watcher.Close() })
+	dtm.Start()
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+	expectRun(t, runs, "old")
+
+	writeConfig(`{"tasks": {"new": {"cron": "*/10 * * * * *"}}}`)
+	if err := watcher.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+	expectRun(t, runs, "new")
+	expectNoRun(t, runs)
+}
@@ -0,0 +1,45 @@
+package redCorn
+
+// TaskInfo 中间件可见的任务信息
+type TaskInfo struct {
+	Name   string // 任务名
+	RunID  string // 本次执行的唯一标识
+	Group  string // 任务分组，未分组时为空
+	Spec   string // Cron 表达式
+	NodeID string // 执行节点
+	Manual bool   // 是否为手动触发
+
+	FencingToken int64 // 配置了 WithFencingToken 时本次执行的 fencing token，否则为0
+}
+
+// Middleware 任务执行中间件，调用 next 执行后续中间件及任务本身（含重试），不调用 next 则跳过本次执行
+type Middleware func(info TaskInfo, next func())
+
+// SkipReasonMiddleware 中间件未调用 next 时的跳过原因
+const SkipReasonMiddleware = "skipped by middleware"
+
+// Use 注册中间件，对所有任务生效，先注册的中间件位于外层
+func (dtm *DistributedTaskManager) Use(middleware ...Middleware) {
+	dtm.mu.Lock()
+	defer dtm.mu.Unlock()
+	dtm.middlewares = append(dtm.middlewares, middleware...)
+}
+
+// runMiddleware 以中间件链包裹 fn 执行，返回 fn 是否被执行
+func (dtm *DistributedTaskManager) runMiddleware(info TaskInfo, fn func()) bool {
+	dtm.mu.RLock()
+	chain := dtm.middlewares
+	dtm.mu.RUnlock()
+
+	called := false
+	next := func() {
+		called = true
+		fn()
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		mw, inner := chain[i], next
+		next = func() { mw(info, inner) }
+	}
+	next()
+	return called
+}
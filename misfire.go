@@ -0,0 +1,116 @@
+package redCorn
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// MisfirePolicy 所有节点都停机期间错过的调度如何补偿
+type MisfirePolicy string
+
+const (
+	MisfireIgnore  MisfirePolicy = "ignore"   // 忽略错过的调度（默认）
+	MisfireRunOnce MisfirePolicy = "run_once" // 启动后补执行一次
+	MisfireRunAll  MisfirePolicy = "run_all"  // 启动后按错过的次数逐次补执行
+)
+
+const (
+	// misfireGrace 距今不足该时长的调度可能正在其他节点上执行，不视为错过
+	misfireGrace = time.Second
+	// maxMisfireRuns MisfireRunAll 单次补执行的最大次数
+	maxMisfireRuns = 100
+	// misfireClaimTTL 补执行认领记录的保留时间
+	misfireClaimTTL = 24 * time.Hour
+)
+
+// WithMisfirePolicy 覆盖任务的错过调度补偿策略（默认使用 Cfg.MisfirePolicy）
+func WithMisfirePolicy(policy MisfirePolicy) TaskOption {
+	return func(o *taskOptions) {
+		o.misfire = policy
+	}
+}
+
+// misfirePolicy 任务生效的补偿策略，任务级配置优先于全局配置
+func (dtm *DistributedTaskManager) misfirePolicy(t *distributedTask) MisfirePolicy {
+	// 一次性任务启动调度时已经过期会立即触发，无需补偿
+	if t.isOnce() {
+		return MisfireIgnore
+	}
+	if t.opts.misfire != "" {
+		return t.opts.misfire
+	}
+	if dtm.cfg.MisfirePolicy != "" {
+		return dtm.cfg.MisfirePolicy
+	}
+	return MisfireIgnore
+}
+
+// catchUpMisfires 根据 Redis 中记录的集群最近执行时间，补执行停机期间错过的调度
+func (dtm *DistributedTaskManager) catchUpMisfires() {
+	dtm.mu.RLock()
+	tasks := make([]*distributedTask, 0, len(dtm.tasks))
+	for _, t := range dtm.tasks {
+		if dtm.misfirePolicy(t) != MisfireIgnore {
+			tasks = append(tasks, t)
+		}
+	}
+	dtm.mu.RUnlock()
+
+	for _, t := range tasks {
+		go dtm.catchUpTask(t)
+	}
+}
+
+// catchUpTask 补执行单个任务错过的调度，同一段错过的调度在集群中只由一个节点补执行
+func (dtm *DistributedTaskManager) catchUpTask(t *distributedTask) {
+	status, err := dtm.controller.Status(dtm.ctx, t.name)
+	if err != nil {
+		dtm.logRun(slog.LevelError, t.name, "", "Failed to load status for misfire check", slog.Any("error", err))
+		return
+	}
+	// 从未执行过的任务无法判断错过了哪些调度
+	if status.LastRun.IsZero() {
+		return
+	}
+
+	missed := countMissedRuns(t, status.LastRun, dtm.clock.Now().Add(-misfireGrace))
+	if missed == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+	key := dtm.key("misfire", t.name, strconv.FormatInt(status.LastRun.UnixNano(), 10))
+	claimed, err := dtm.redisClient.SetNX(ctx, key, dtm.nodeID, misfireClaimTTL).Result()
+	if err != nil {
+		dtm.logRun(slog.LevelError, t.name, "", "Failed to claim misfired runs", slog.Any("error", err))
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	runs := 1
+	if dtm.misfirePolicy(t) == MisfireRunAll {
+		runs = missed
+	}
+	dtm.logRun(slog.LevelWarn, t.name, "", "Catching up missed runs", slog.Int("missed", missed), slog.Time("last_run", status.LastRun), slog.Int("runs", runs))
+	for i := 0; i < runs && !dtm.isStopping(); i++ {
+		if dtm.cfg.Role == RoleScheduler {
+			dtm.enqueueExecution(t, false, time.Time{})
+			continue
+		}
+		dtm.executeDistributedTask(t, false, time.Time{})
+	}
+}
+
+// countMissedRuns 计算 (lastRun, until) 之间错过的调度次数，最多 maxMisfireRuns 次
+func countMissedRuns(t *distributedTask, lastRun, until time.Time) int {
+	n := 0
+	for next := t.schedule.Next(lastRun); !next.IsZero() && next.Before(until) && n < maxMisfireRuns; next = t.schedule.Next(next) {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,44 @@
+package redCorn
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// newNodeID 生成当前节点的标识：配置了 nodeID 时直接使用，否则为主机名:实例标识，未配置实例标识时使用进程号
+// 节点标识会写入锁的值 "<节点>/<执行标识>"，因此节点标识和实例标识都不能包含 "/"
+func newNodeID(nodeID, instanceID string) (string, error) {
+	if nodeID != "" {
+		if strings.Contains(nodeID, "/") {
+			return "", fmt.Errorf("invalid node ID %q: must not contain \"/\"", nodeID)
+		}
+		return nodeID, nil
+	}
+	if strings.Contains(instanceID, "/") {
+		return "", fmt.Errorf("invalid instance ID %q: must not contain \"/\"", instanceID)
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "unknown"
+	}
+	if instanceID == "" {
+		instanceID = strconv.Itoa(os.Getpid())
+	}
+	return hostname + ":" + instanceID, nil
+}
+
+// NodeID 当前节点的标识，可用于在应用自己的日志和监控指标中区分节点
+func (dtm *DistributedTaskManager) NodeID() string {
+	return dtm.nodeID
+}
+
+// splitLockValue 将锁的值拆分为节点标识和执行标识，不是 "<节点>/<执行标识>" 格式时都返回空
+func splitLockValue(value string) (node, runID string) {
+	i := strings.LastIndex(value, "/")
+	if i <= 0 || i == len(value)-1 {
+		return "", ""
+	}
+	return value[:i], value[i+1:]
+}
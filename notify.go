@@ -0,0 +1,166 @@
+package redCorn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// NotificationKind 通知类型
+type NotificationKind string
+
+const (
+	NotifyError   NotificationKind = "error"   // 任务返回错误
+	NotifyPanic   NotificationKind = "panic"   // 任务发生 panic
+	NotifyTimeout NotificationKind = "timeout" // 任务超过执行时限
+	NotifyMissed  NotificationKind = "missed"  // 任务未在预期时间内执行
+)
+
+const (
+	defaultNotifyRetries = 3               // 通知投递失败时的默认重试次数
+	defaultNotifyTimeout = 5 * time.Second // 单次投递的默认超时
+)
+
+// Notification 发送给通知渠道的告警内容
+type Notification struct {
+	Kind     NotificationKind `json:"kind"`
+	RunID    string           `json:"run_id"`
+	Task     string           `json:"task"`
+	Node     string           `json:"node"`
+	Time     time.Time        `json:"time"`
+	Duration time.Duration    `json:"duration"`
+	Error    string           `json:"error"`
+}
+
+// Notifier 通知渠道
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// NotifyCfg 任务失败通知配置
+type NotifyCfg struct {
+	Webhooks  []string      // 接收 JSON 通知的 Webhook 地址
+	Notifiers []Notifier    // 自定义通知渠道
+	Timeout   time.Duration // 单次投递超时，默认5秒
+	Retry     RetryPolicy   // 投递失败时的重试策略，MaxRetries 默认3，小于0表示不重试
+}
+
+// WebhookNotifier 以 HTTP POST 发送 JSON 格式通知
+type WebhookNotifier struct {
+	URL    string
+	Header http.Header  // 附加的请求头，可选
+	Client *http.Client // 默认 http.DefaultClient
+}
+
+// Notify 发送通知，非 2xx 响应视为失败
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %v", err)
+	}
+	return postJSON(ctx, w.Client, w.URL, w.Header, body)
+}
+
+// postJSON 发送 JSON 请求，非 2xx 响应视为失败
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// newNotifiers 根据配置创建通知渠道
+func newNotifiers(cfg NotifyCfg) []Notifier {
+	notifiers := make([]Notifier, 0, len(cfg.Webhooks)+len(cfg.Notifiers))
+	for _, url := range cfg.Webhooks {
+		notifiers = append(notifiers, &WebhookNotifier{URL: url})
+	}
+	return append(notifiers, cfg.Notifiers...)
+}
+
+// notificationKind 根据任务错误判断通知类型
+func notificationKind(err error) NotificationKind {
+	var pe *PanicError
+	switch {
+	case errors.As(err, &pe):
+		return NotifyPanic
+	case errors.Is(err, context.DeadlineExceeded):
+		return NotifyTimeout
+	default:
+		return NotifyError
+	}
+}
+
+// notifyFailure 执行失败时异步向所有通知渠道发送告警
+func (dtm *DistributedTaskManager) notifyFailure(t *distributedTask, runID string, rec *ExecutionRecord) {
+	dtm.sendNotification(t, Notification{
+		Kind:     notificationKind(rec.err),
+		RunID:    runID,
+		Task:     rec.Task,
+		Node:     rec.Node,
+		Time:     dtm.clock.Now(),
+		Duration: rec.Duration,
+		Error:    rec.Error,
+	})
+}
+
+// sendNotification 异步向全局和任务级通知渠道发送告警
+func (dtm *DistributedTaskManager) sendNotification(t *distributedTask, n Notification) {
+	for _, notifier := range dtm.notifiers {
+		go dtm.deliver(notifier, n)
+	}
+	for _, notifier := range t.opts.notifiers {
+		go dtm.deliver(notifier, n)
+	}
+}
+
+// deliver 投递一条通知，失败时按退避策略重试
+func (dtm *DistributedTaskManager) deliver(notifier Notifier, n Notification) {
+	cfg := dtm.cfg.Notify
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultNotifyTimeout
+	}
+	retries := cfg.Retry.MaxRetries
+	if retries == 0 {
+		retries = defaultNotifyRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := notifier.Notify(ctx, n)
+		cancel()
+		if err == nil {
+			return
+		}
+		if attempt >= retries {
+			dtm.logRun(slog.LevelError, n.Task, n.RunID, "Failed to deliver notification", slog.String("kind", string(n.Kind)), slog.Any("error", err))
+			return
+		}
+		delay := cfg.Retry.backoff(attempt + 1)
+		dtm.logRun(slog.LevelWarn, n.Task, n.RunID, "Notification attempt failed, retrying", slog.String("kind", string(n.Kind)), slog.Int("attempt", attempt+1), slog.Duration("delay", delay), slog.Any("error", err))
+		dtm.clock.Sleep(delay)
+	}
+}
@@ -0,0 +1,102 @@
+package redCorn
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// onceSpecPrefix 一次性任务的表达式前缀，表达式为 "@at <RFC3339 时间>"
+	onceSpecPrefix = "@at "
+	// onceClaimTTL 一次性任务调度时刻认领记录的保留时间，在此期间重复添加的同名同时刻任务不会再次执行
+	onceClaimTTL = 24 * time.Hour
+)
+
+// onceSchedule 只在 at 触发一次的调度
+type onceSchedule struct {
+	at time.Time
+}
+
+// Next at 之前返回 at，之后不再触发
+func (s onceSchedule) Next(t time.Time) time.Time {
+	if t.Before(s.at) {
+		return s.at
+	}
+	return time.Time{}
+}
+
+// onceJob 交给 cron 的一次性调度和任务：到达 at 或启动调度时已过 at 都会触发一次，触发后从管理器中移除任务
+type onceJob struct {
+	dtm   *DistributedTaskManager
+	task  *distributedTask
+	at    time.Time
+	fired atomic.Bool
+}
+
+// Next 尚未触发时，已经过了 at 的调度在下一秒触发
+func (j *onceJob) Next(t time.Time) time.Time {
+	if j.fired.Load() {
+		return time.Time{}
+	}
+	if t.Before(j.at) {
+		return j.at
+	}
+	return t.Truncate(time.Second).Add(time.Second)
+}
+
+// Run 执行一次任务后移除任务，调度时刻固定为 at，各节点按同一时刻认领，集群中只执行一次
+func (j *onceJob) Run() {
+	if !j.fired.CompareAndSwap(false, true) {
+		return
+	}
+	j.dtm.dispatch(j.task, false, j.at.Truncate(time.Second))
+
+	dtm := j.dtm
+	dtm.mu.Lock()
+	defer dtm.mu.Unlock()
+	if dtm.tasks[j.task.name] == j.task {
+		dtm.uninstallTask(j.task)
+		dtm.log.Info("Removed one-off task after execution: ", j.task.name)
+	}
+}
+
+// isOnce 是否为 RunAt / RunAfter 添加的一次性任务
+func (t *distributedTask) isOnce() bool {
+	_, ok := t.schedule.(onceSchedule)
+	return ok
+}
+
+// RunAt 添加在 at 执行一次的任务，执行后自动移除；at 已经过去时添加后立即执行
+// 与定时任务一样受分布式锁保护，并按调度时刻 at 在集群中认领，各节点以相同的 name 和 at 添加时只有一个节点执行
+// 执行时任务处于暂停状态则跳过本次执行并同样移除任务
+func (dtm *DistributedTaskManager) RunAt(name string, at time.Time, task func(ctx context.Context) error, opts ...TaskOption) error {
+	if at.IsZero() {
+		return fmt.Errorf("failed to add one-off task %s: time is required", name)
+	}
+
+	dtm.mu.Lock()
+	defer dtm.mu.Unlock()
+
+	if err := dtm.checkAddTask(name); err != nil {
+		return err
+	}
+
+	t, err := dtm.newTask(name, onceSpecPrefix+at.Format(time.RFC3339), onceSchedule{at: at}, task, newTaskOptions(opts))
+	if err == nil {
+		err = dtm.checkTaskCycle(t)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add one-off task %s: %v", name, err)
+	}
+
+	dtm.addTask(t)
+	return nil
+}
+
+// RunAfter 添加在 d 之后执行一次的任务，见 RunAt
+// 执行时刻按调用时当前节点的时间计算，多个节点分别调用时执行时刻不同，需要集群中只执行一次时应使用 RunAt 指定同一时刻
+func (dtm *DistributedTaskManager) RunAfter(name string, d time.Duration, task func(ctx context.Context) error, opts ...TaskOption) error {
+	return dtm.RunAt(name, dtm.clock.Now().Add(d), task, opts...)
+}
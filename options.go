@@ -0,0 +1,86 @@
+package redCorn
+
+import (
+	"context"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// TaskOption 任务选项，用于在注册任务时配置单个任务的行为
+type TaskOption func(*taskOptions)
+
+// taskOptions 单个任务的可选配置
+type taskOptions struct {
+	group   string
+	timeout time.Duration
+	retry   RetryPolicy
+	jitter  time.Duration
+
+	priority int            // 本地队列中的优先级
+	overflow OverflowPolicy // 本地队列已满时的处理策略
+
+	location   *time.Location
+	cronFormat CronFormat
+	parser     cron.ScheduleParser
+	calendar   Calendar // 只在工作日调度
+
+	lockExpiry  time.Duration
+	lockPrefix  *string
+	lockRetries int
+	holdLock    bool
+	fencing     bool
+
+	rateLimiter string // 使用的限流器名称
+	quota       *quota // 执行配额
+
+	shard  int // 分片任务的分片序号
+	shards int // 分片任务的分片总数，普通任务为0
+
+	notifiers []Notifier
+	pingURL   string
+
+	misfire MisfirePolicy
+
+	then      []string // 执行成功后触发的下游任务
+	dependsOn []string // 前置任务
+
+	condition func(ctx context.Context) bool // 执行条件
+
+	startTime  time.Time     // 开始时间，之前不调度
+	startAfter time.Duration // WithStartAfter 指定的开始时间，注册时按任务管理器的时钟换算为 startTime
+	endTime    time.Time     // 结束时间，之后不再调度
+	maxRuns    int64         // 集群中的最大执行次数，为0表示不限制
+
+	runOnStart bool // 启动时执行一次
+}
+
+// newTaskOptions 应用任务选项
+func newTaskOptions(opts []TaskOption) taskOptions {
+	var o taskOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithTimeout 覆盖任务的执行超时时间（默认使用 Cfg.TaskTimeout）
+func WithTimeout(timeout time.Duration) TaskOption {
+	return func(o *taskOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithRetry 任务返回错误时按策略重试
+func WithRetry(policy RetryPolicy) TaskOption {
+	return func(o *taskOptions) {
+		o.retry = policy
+	}
+}
+
+// WithNotifier 为任务追加通知渠道，与 Cfg.Notify 中的全局渠道同时生效
+func WithNotifier(notifiers ...Notifier) TaskOption {
+	return func(o *taskOptions) {
+		o.notifiers = append(o.notifiers, notifiers...)
+	}
+}
@@ -0,0 +1,54 @@
+package redCorn
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// WithPingURL 任务执行成功后请求 url，失败后请求 url+"/fail"，兼容 Healthchecks.io 等 cron 监控服务
+func WithPingURL(url string) TaskOption {
+	return func(o *taskOptions) {
+		o.pingURL = strings.TrimSuffix(url, "/")
+	}
+}
+
+// ping 异步向任务的监控地址上报执行结果，失败时请求体中携带错误信息
+func (dtm *DistributedTaskManager) ping(t *distributedTask, rec *ExecutionRecord) {
+	if t.opts.pingURL == "" {
+		return
+	}
+	url := t.opts.pingURL
+	if rec.Status == ExecutionFailed {
+		url += "/fail"
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultNotifyTimeout)
+		defer cancel()
+		if err := sendPing(ctx, url, rec.Error); err != nil {
+			dtm.logRun(slog.LevelWarn, t.name, rec.RunID, "Failed to ping", slog.String("url", url), slog.Any("error", err))
+		}
+	}()
+}
+
+// sendPing 以 POST 请求上报，非 2xx 响应视为失败
+func sendPing(ctx context.Context, url, body string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package redCorn
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// OverflowPolicy 本地队列已满（达到 Cfg.MaxQueuedTasks）时如何处理新的执行
+type OverflowPolicy string
+
+const (
+	OverflowDrop  OverflowPolicy = "drop"  // 丢弃本次执行，记录为跳过（默认）
+	OverflowBlock OverflowPolicy = "block" // 等待队列有空位，等待期间调度协程被占用
+	OverflowRun   OverflowPolicy = "run"   // 不排队，立即在调度协程中执行，超出并发上限
+)
+
+// SkipReasonQueueFull 本地队列已满、按 OverflowDrop 丢弃执行时的跳过原因
+const SkipReasonQueueFull = "execution queue full"
+
+// submitResult 加入队列的结果
+type submitResult int
+
+const (
+	submitted  submitResult = iota // 已加入队列
+	queueFull                      // 队列已满
+	poolClosed                     // 协程池已关闭
+)
+
+// execution 一次等待执行的任务执行
+type execution struct {
+	task   *distributedTask
+	manual bool
+	tick   time.Time
+}
+
+// workerPool 固定数量的工作协程，限制当前节点同时执行的任务数，超出的执行在本地队列中排队，
+// 优先级高的执行排在前面，同优先级按先后顺序
+type workerPool struct {
+	mu       sync.Mutex
+	notEmpty *sync.Cond // 队列中有执行或协程池已关闭
+	notFull  *sync.Cond // 队列有空位或协程池已关闭
+	queue    []execution
+	capacity int            // 队列长度上限，0表示不限制
+	queued   map[string]int // 各任务在队列中等待的执行数
+	closed   bool
+}
+
+// newWorkerPool 创建工作协程池并启动 workers 个工作协程，每个协程依次取出队列中的执行交给 run
+func newWorkerPool(workers, capacity int, run func(execution)) *workerPool {
+	p := &workerPool{capacity: capacity, queued: make(map[string]int)}
+	p.notEmpty = sync.NewCond(&p.mu)
+	p.notFull = sync.NewCond(&p.mu)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				e, ok := p.next()
+				if !ok {
+					return
+				}
+				run(e)
+			}
+		}()
+	}
+	return p
+}
+
+// submit 将一次执行加入队列，队列已满时 block 为 true 则等待空位，否则返回 queueFull
+func (p *workerPool) submit(e execution, block bool) submitResult {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for block && p.full() && !p.closed {
+		p.notFull.Wait()
+	}
+	if p.closed {
+		return poolClosed
+	}
+	if p.full() {
+		return queueFull
+	}
+	// 插入到优先级不低于它的执行之后
+	i := len(p.queue)
+	for i > 0 && p.queue[i-1].task.opts.priority < e.task.opts.priority {
+		i--
+	}
+	p.queue = append(p.queue, execution{})
+	copy(p.queue[i+1:], p.queue[i:])
+	p.queue[i] = e
+	p.queued[e.task.name]++
+	p.notEmpty.Signal()
+	return submitted
+}
+
+// full 队列是否已满，调用方需持有 p.mu
+func (p *workerPool) full() bool {
+	return p.capacity > 0 && len(p.queue) >= p.capacity
+}
+
+// next 取出队列中最靠前的执行，队列为空时等待，协程池关闭后返回 false
+func (p *workerPool) next() (execution, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.queue) == 0 && !p.closed {
+		p.notEmpty.Wait()
+	}
+	if p.closed {
+		return execution{}, false
+	}
+
+	e := p.queue[0]
+	p.queue[0] = execution{}
+	p.queue = p.queue[1:]
+	if p.queued[e.task.name]--; p.queued[e.task.name] == 0 {
+		delete(p.queued, e.task.name)
+	}
+	p.notFull.Signal()
+	return e, true
+}
+
+// close 关闭协程池，丢弃队列中尚未开始的执行并返回其数量；正在执行的任务不受影响，结束后工作协程退出
+func (p *workerPool) close() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	dropped := len(p.queue)
+	p.closed = true
+	p.queue = nil
+	p.queued = make(map[string]int)
+	p.notEmpty.Broadcast()
+	p.notFull.Broadcast()
+	return dropped
+}
+
+// depth 队列中等待的执行总数
+func (p *workerPool) depth() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.queue)
+}
+
+// queuedCounts 各任务在队列中等待的执行数
+func (p *workerPool) queuedCounts() map[string]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	counts := make(map[string]int, len(p.queued))
+	for name, n := range p.queued {
+		counts[name] = n
+	}
+	return counts
+}
+
+// WithPriority 任务的优先级，默认0，数值越大越优先；配置了 Cfg.MaxConcurrentTasks 且工作协程都在忙时，
+// 优先级高的执行在本地队列中排到优先级低的前面，不影响正在执行的任务，也不影响各节点争抢锁的顺序
+func WithPriority(priority int) TaskOption {
+	return func(o *taskOptions) {
+		o.priority = priority
+	}
+}
+
+// WithOverflowPolicy 覆盖任务在本地队列已满时的处理策略（默认使用 Cfg.OverflowPolicy）
+func WithOverflowPolicy(policy OverflowPolicy) TaskOption {
+	return func(o *taskOptions) {
+		o.overflow = policy
+	}
+}
+
+// overflowPolicy 任务生效的队列溢出策略，任务级配置优先于全局配置
+func (dtm *DistributedTaskManager) overflowPolicy(t *distributedTask) OverflowPolicy {
+	if t.opts.overflow != "" {
+		return t.opts.overflow
+	}
+	if dtm.cfg.OverflowPolicy != "" {
+		return dtm.cfg.OverflowPolicy
+	}
+	return OverflowDrop
+}
+
+// dispatch 执行一次任务：调度节点加入工作队列由工作节点执行，配置了 MaxConcurrentTasks 时加入本地队列由工作协程执行，
+// 否则在调用方的协程中直接执行
+// 队列已满时按任务的 OverflowPolicy 丢弃、等待或直接执行
+func (dtm *DistributedTaskManager) dispatch(t *distributedTask, manual bool, tick time.Time) {
+	if dtm.cfg.Role == RoleScheduler {
+		dtm.enqueueExecution(t, manual, tick)
+		return
+	}
+	if dtm.pool == nil {
+		dtm.executeDistributedTask(t, manual, tick)
+		return
+	}
+
+	policy := dtm.overflowPolicy(t)
+	switch dtm.pool.submit(execution{task: t, manual: manual, tick: tick}, policy == OverflowBlock) {
+	case poolClosed:
+		dtm.logRun(slog.LevelDebug, t.name, "", "Manager is stopping, skipping execution")
+	case queueFull:
+		if policy == OverflowRun {
+			dtm.logRun(slog.LevelWarn, t.name, "", "Execution queue full, running without queueing")
+			dtm.executeDistributedTask(t, manual, tick)
+			return
+		}
+		dtm.dropExecution(t)
+	}
+}
+
+// dropExecution 队列已满时丢弃一次执行，与其他跳过的执行一样写入统计、事件和执行历史
+func (dtm *DistributedTaskManager) dropExecution(t *distributedTask) {
+	runID := newRunID()
+	rec := &ExecutionRecord{
+		RunID:      runID,
+		Task:       t.name,
+		Node:       dtm.nodeID,
+		StartedAt:  dtm.clock.Now(),
+		Status:     ExecutionSkipped,
+		SkipReason: SkipReasonQueueFull,
+	}
+	dtm.logRun(slog.LevelWarn, t.name, runID, "Execution queue full, dropping execution")
+	dtm.finishExecution(t, runID, rec)
+}
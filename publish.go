@@ -0,0 +1,57 @@
+package redCorn
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	goredislib "github.com/go-redis/redis/v8"
+)
+
+// defaultEventStreamMaxLen 事件流默认保留的近似最大条数
+const defaultEventStreamMaxLen = 10000
+
+// EventsCfg 任务事件发布配置
+type EventsCfg struct {
+	Channel string // 发布任务事件的 Redis Pub/Sub 频道，为空表示不发布
+	Stream  string // 持久化任务事件的 Redis Stream，为空表示不写入
+	MaxLen  int64  // Stream 保留的近似最大条数，默认10000
+}
+
+// publishEvent 将任务事件以 JSON 格式发布到 Redis，供其他服务订阅
+// Pub/Sub 只投递给在线的订阅者，Stream 则允许离线的消费者之后补读
+func (dtm *DistributedTaskManager) publishEvent(event TaskEvent) {
+	cfg := dtm.cfg.Events
+	if cfg.Channel == "" && cfg.Stream == "" {
+		return
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		dtm.logRun(slog.LevelError, event.Task, event.RunID, "Failed to encode event", slog.Any("error", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	if cfg.Channel != "" {
+		if err := dtm.redisClient.Publish(ctx, cfg.Channel, data).Err(); err != nil {
+			dtm.logRun(slog.LevelError, event.Task, event.RunID, "Failed to publish event", slog.String("event", string(event.Type)), slog.Any("error", err))
+		}
+	}
+	if cfg.Stream != "" {
+		maxLen := cfg.MaxLen
+		if maxLen <= 0 {
+			maxLen = defaultEventStreamMaxLen
+		}
+		err := dtm.redisClient.XAdd(ctx, &goredislib.XAddArgs{
+			Stream: cfg.Stream,
+			MaxLen: maxLen,
+			Approx: true,
+			Values: map[string]interface{}{"event": data},
+		}).Err()
+		if err != nil {
+			dtm.logRun(slog.LevelError, event.Task, event.RunID, "Failed to append event to stream", slog.String("event", string(event.Type)), slog.Any("error", err))
+		}
+	}
+}
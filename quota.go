@@ -0,0 +1,68 @@
+package redCorn
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// 执行配额的跳过原因
+const (
+	SkipReasonQuotaExceeded = "quota exceeded"
+	SkipReasonQuotaError    = "failed to check quota"
+)
+
+// quotaKeyGrace 配额计数在窗口结束后的保留时间
+const quotaKeyGrace = time.Minute
+
+// quota 任务的执行配额
+type quota struct {
+	limit int64
+	per   time.Duration
+}
+
+// WithQuota 任务在整个集群中每个 per 窗口内最多执行 n 次，例如 WithQuota(100, 24*time.Hour) 表示每天最多 100 次
+// 窗口按 per 对齐到 UTC 零点（如每天、每小时的整点）；超出配额的执行以 SkipReasonQuotaExceeded 跳过，手动触发同样计入
+func WithQuota(n int, per time.Duration) TaskOption {
+	return func(o *taskOptions) {
+		o.quota = &quota{limit: int64(n), per: per}
+	}
+}
+
+// validate 检查配额，未配置配额时返回 nil
+func (q *quota) validate() error {
+	if q != nil && (q.limit < 1 || q.per <= 0) {
+		return fmt.Errorf("invalid quota %d per %s: both must be positive", q.limit, q.per)
+	}
+	return nil
+}
+
+// takeQuota 在当前窗口的计数器 <Namespace>:quota:<任务名>:<窗口开始时间> 上计数，超出配额或出错时在执行记录中写入跳过原因
+func (dtm *DistributedTaskManager) takeQuota(ctx context.Context, t *distributedTask, rec *ExecutionRecord) bool {
+	q := t.opts.quota
+	now := dtm.clock.Now()
+	window := now.UTC().Truncate(q.per)
+	key := dtm.key("quota", t.name, strconv.FormatInt(window.Unix(), 10))
+
+	pipe := dtm.redisClient.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	// 按时长而不是时刻设置过期，Cfg.Clock 与 Redis 服务端的时间不一致时计数器仍保留到窗口结束之后
+	pipe.Expire(ctx, key, window.Add(q.per+quotaKeyGrace).Sub(now))
+	if _, err := pipe.Exec(ctx); err != nil {
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonQuotaError
+		rec.setError(err)
+		dtm.logRun(slog.LevelError, t.name, rec.RunID, "Failed to check quota, skipping execution", slog.Any("error", err))
+		return false
+	}
+	if used := incr.Val(); used > q.limit {
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonQuotaExceeded
+		dtm.logRun(slog.LevelWarn, t.name, rec.RunID, "Quota exceeded, skipping execution",
+			slog.Int64("limit", q.limit), slog.Duration("per", q.per), slog.Time("window", window))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package redCorn
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	goredislib "github.com/go-redis/redis/v8"
+)
+
+// 限流的跳过原因
+const (
+	SkipReasonRateLimited    = "rate limit exceeded"
+	SkipReasonRateLimitError = "failed to check rate limit"
+)
+
+// RateLimit 集群范围的令牌桶限流：使用同一限流器的所有任务在所有节点上每 Per 时间内合计最多执行 Limit 次
+// 令牌按 Limit/Per 的速率持续补充，桶满时最多允许连续执行 Limit 次
+type RateLimit struct {
+	Limit int
+	Per   time.Duration
+}
+
+// validate 检查限流配置
+func (r RateLimit) validate(name string) error {
+	if r.Limit < 1 || r.Per <= 0 {
+		return fmt.Errorf("invalid rate limit %s: limit and per must be positive", name)
+	}
+	return nil
+}
+
+// WithRateLimit 任务的每次执行从名为 limiter 的限流器（见 Cfg.RateLimits）中取一个令牌，没有令牌时跳过本次执行
+// 多个任务可以共用同一个限流器，例如访问同一个有调用频率限制的第三方接口的任务
+func WithRateLimit(limiter string) TaskOption {
+	return func(o *taskOptions) {
+		o.rateLimiter = limiter
+	}
+}
+
+// takeTokenScript 按 Redis 服务器时间补充令牌并尝试取出一个，返回 1 表示取到令牌
+var takeTokenScript = goredislib.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * capacity / interval)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("PEXPIRE", KEYS[1], interval * 2)
+return allowed
+`)
+
+// checkRateLimiter 确认任务使用的限流器已配置
+func (dtm *DistributedTaskManager) checkRateLimiter(options taskOptions) error {
+	if options.rateLimiter == "" {
+		return nil
+	}
+	if _, ok := dtm.cfg.RateLimits[options.rateLimiter]; !ok {
+		return fmt.Errorf("unknown rate limiter %q", options.rateLimiter)
+	}
+	return nil
+}
+
+// takeToken 从任务的限流器中取一个令牌，没有令牌或出错时在执行记录中写入跳过原因
+// 限流用于保护下游系统，无法确认是否超出限制时不执行
+func (dtm *DistributedTaskManager) takeToken(ctx context.Context, t *distributedTask, rec *ExecutionRecord) bool {
+	limiter := t.opts.rateLimiter
+	limit := dtm.cfg.RateLimits[limiter]
+
+	key := dtm.key("ratelimit", limiter)
+	allowed, err := takeTokenScript.Run(ctx, dtm.redisClient, []string{key},
+		limit.Limit, strconv.FormatInt(limit.Per.Milliseconds(), 10)).Int()
+	if err != nil {
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonRateLimitError
+		rec.setError(err)
+		dtm.logRun(slog.LevelError, t.name, rec.RunID, "Failed to check rate limit, skipping execution", slog.String("limiter", limiter), slog.Any("error", err))
+		return false
+	}
+	if allowed == 0 {
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonRateLimited
+		dtm.logRun(slog.LevelWarn, t.name, rec.RunID, "Rate limit exceeded, skipping execution", slog.String("limiter", limiter))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,30 @@
+package redCorn
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+)
+
+// PanicError 任务执行过程中发生 panic 时返回的错误
+type PanicError struct {
+	Value interface{} // recover() 得到的值
+	Stack []byte      // 发生 panic 时的调用栈
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// callTask 执行一次任务，将 panic 转换为 *PanicError，避免单个任务拖垮整个进程
+func (dtm *DistributedTaskManager) callTask(ctx context.Context, t *distributedTask) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			pe := &PanicError{Value: r, Stack: debug.Stack()}
+			dtm.logRun(slog.LevelError, t.name, RunIDFromContext(ctx), "Task panicked", slog.Any("error", pe), slog.String("stack", string(pe.Stack)))
+			err = pe
+		}
+	}()
+	return t.task(ctx)
+}
@@ -4,154 +4,910 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	goredislib "github.com/go-redis/redis/v8"
-	"github.com/go-redsync/redsync/v4"
-	"github.com/go-redsync/redsync/v4/redis/goredis/v8"
 	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// ErrTaskNotFound 任务不存在
+var ErrTaskNotFound = errors.New("task not found")
+
+// ErrManagerStopped 任务管理器已停止
+var ErrManagerStopped = errors.New("task manager stopped")
+
+// defaultNamespace 默认的 Redis 键命名空间
+const defaultNamespace = "redcorn"
+
+// specParser Cron 表达式解析器，支持秒级定时
+var specParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
 // Cfg 配置结构体
 type Cfg struct {
 	RedisCfg goredislib.UniversalOptions
-	LockCfg  LockCfg
-	Logger   Logger // 自定义日志器，可选
+	// RedisTLS 连接 Redis 的 TLS 配置，启用后覆盖 RedisCfg.TLSConfig，
+	// 同时用于未配置 TLSConfig 的 RedisCfgQuorum 实例；配置 RedisClient 时不生效
+	RedisTLS TLSCfg
+	// RedisClient 复用应用已有的 Redis 客户端，配置后忽略 RedisCfg，Stop() 时不关闭该客户端
+	RedisClient goredislib.UniversalClient
+	// RedisMode Redis 的部署模式，可选，为空时由 go-redis 根据 RedisCfg 自动选择；
+	// 配置后启动时校验 RedisCfg 和实际的拓扑，并在其上获取并释放一次锁，配置有误时返回明确的错误
+	RedisMode RedisMode
+	LockCfg   LockCfg
+	// RateLimits 集群范围的限流器，键为限流器名称，任务通过 WithRateLimit 使用
+	RateLimits map[string]RateLimit
+	// Load 按节点负载延迟获取锁，可选，让负载较低的节点优先执行任务
+	Load LoadCfg
+	// Locker 分布式锁后端，可选，默认使用 RedisCfg 连接的 Redis（redsync）
+	Locker Locker
+	// RedisCfgQuorum 用于 Redlock 的多个相互独立的 Redis 实例，建议 3 或 5 个，未配置 Locker 时生效；
+	// 配置后分布式锁在多数实例上加锁成功才算获取到锁，任务状态、执行历史等仍保存在 RedisCfg 中
+	RedisCfgQuorum []goredislib.UniversalOptions
+	// LockBackend 内置的锁后端，未配置 Locker 时生效，默认 LockBackendRedis；
+	// 设为 LockBackendMemory 时不连接 Redis，忽略 RedisCfg，需导入 memorybackend 包，用于单元测试和本地开发
+	LockBackend LockBackend
+	Logger      Logger // 自定义日志器，可选，默认输出到 slog.Default()
+	// LogLevel 最低日志级别，例如设为 LogLevelWarn 可屏蔽每次执行的 Info 日志，为空表示不过滤
+	LogLevel LogLevel
+	// InstanceID 实例标识，与主机名组成节点标识 "<主机名>:<实例标识>" 并写入锁的值，默认为进程号；
+	// 同一主机上运行多个实例（如容器）时可设为 Pod 名称等便于识别的值，不能包含 "/"
+	InstanceID string
+	// NodeID 完整的节点标识，配置后直接使用并忽略 InstanceID，默认为 "<主机名>:<进程号>"；
+	// 出现在锁的值、日志、事件、执行历史、通知和 span 属性中，集群内必须唯一，不能包含 "/"
+	NodeID string
+
+	// TaskTimeout 单次任务执行的超时时间，超时后任务上下文被取消，为0表示不限制
+	TaskTimeout time.Duration
+	// MaxConcurrentTasks 当前节点同时执行的任务数上限，超出的定时调度和手动触发在本地队列中排队，
+	// 排队期间不获取锁；为0表示不限制，每次执行使用独立的协程
+	MaxConcurrentTasks int
+	// MaxQueuedTasks 本地队列的长度上限，仅在配置了 MaxConcurrentTasks 时生效，为0表示不限制；
+	// 队列已满时新的执行按 OverflowPolicy 处理
+	MaxQueuedTasks int
+	// OverflowPolicy 本地队列已满时的默认处理策略，默认 OverflowDrop，可通过 WithOverflowPolicy 按任务覆盖
+	OverflowPolicy OverflowPolicy
+	// ShutdownTimeout Stop() 等待正在执行的任务完成的最长时间，超时后取消任务上下文，默认30秒，为负数表示不等待
+	ShutdownTimeout time.Duration
+
+	// TracerProvider 用于创建任务执行 span 的 TracerProvider，可选，默认使用 otel 全局 TracerProvider
+	TracerProvider trace.TracerProvider
+
+	// Namespace 除锁以外的 Redis 键（执行历史等）的命名空间，默认 "redcorn"
+	Namespace string
+	// Hooks 任务生命周期钩子，可选
+	Hooks Hooks
+	// NodeHooks 集群节点加入、下线时的回调，可选
+	NodeHooks NodeHooks
+	// Events 任务事件发布配置，可选
+	Events EventsCfg
+	// Notify 任务失败通知配置，可选
+	Notify NotifyCfg
+	// Deadman 漏执行检测配置，可选
+	Deadman DeadmanCfg
+	// MisfirePolicy 所有节点停机期间错过的调度的补偿策略，默认 MisfireIgnore
+	MisfirePolicy MisfirePolicy
+	// Location 解析 Cron 表达式使用的默认时区，默认为本地时区
+	Location *time.Location
+	// CronFormat Cron 表达式格式，默认 CronFormatSeconds（6位，包含秒）
+	CronFormat CronFormat
+
+	// SchedulingMode 调度模式，默认 SchedulingLock，可选 SchedulingLeader、SchedulingHash
+	SchedulingMode SchedulingMode
+	// Leader 领导者选举配置，仅在 SchedulingLeader 模式下生效
+	Leader LeaderCfg
+	// Role 节点角色，默认 RoleAll；RoleScheduler 节点只计算调度并把执行加入工作队列，RoleWorker 节点只执行队列中的任务
+	Role NodeRole
+
+	// JobQueue 延迟任务队列配置，通过 HandleJob 注册处理函数后生效
+	JobQueue JobQueueCfg
+	// WorkQueue 基于 Redis Streams 的工作队列配置，通过 HandleJob 注册处理函数后生效
+	WorkQueue WorkQueueCfg
+
+	// DynamicTasks 保存在 Redis 中的动态任务配置，可选
+	DynamicTasks DynamicTasksCfg
+
+	// Clock 计算调度时刻、随机延迟和锁过期时间使用的时钟，可选，默认使用系统时钟；
+	// 单元测试中可设为 NewFakeClock 或 clockwork.NewFakeClock()，通过 Advance 快进调度而不是等待
+	Clock Clock
+
+	// History 执行历史配置
+	History HistoryCfg
+	// AdminHTTP 内嵌 HTTP 管理接口配置，可选
+	AdminHTTP AdminHTTPCfg
 }
 
 type LockCfg struct {
 	Expiry time.Duration
 	Prefix string
+
+	// DisableTickScope 关闭按调度时刻去重，默认开启：获取锁后还需在集群中认领本次调度时刻，
+	// 防止时钟偏慢的节点在快节点执行完并释放锁后再次执行同一时刻
+	DisableTickScope bool
+
+	// ExtendInterval 任务执行期间自动续期锁的间隔，应小于 Expiry，为0表示不续期
+	ExtendInterval time.Duration
+	// MaxLifetime 自动续期的最长时间，超过后停止续期，为0表示不限制
+	MaxLifetime time.Duration
+
+	// Tries 获取锁的尝试次数，为0时只尝试一次；任务通过 WithLockRetries 配置了重试时以任务配置为准
+	Tries int
+	// RetryDelay 两次尝试之间的间隔，为0时使用锁后端的默认值（Redis 锁为 50~250 毫秒随机）
+	RetryDelay time.Duration
+	// DriftFactor 计算锁有效时间时扣除的时钟漂移比例，为0时使用 redsync 的默认值 0.01，只对 Redis 锁生效
+	DriftFactor float64
+	// GenValue 生成锁的值，默认为 "<节点>/<执行标识>"；
+	// 自定义的值不是该格式时无法从锁找到持有者，ForceUnlock 也不能释放
+	GenValue func(node, runID string) (string, error)
 }
 
 // DistributedTaskManager 分布式任务管理器
 type DistributedTaskManager struct {
-	redisClient goredislib.UniversalClient
-	redsync     *redsync.Redsync
-	cron        *cron.Cron
-	ctx         context.Context
-	cancel      context.CancelFunc
-	cfg         Cfg
-	log         Logger
+	redisClient   goredislib.UniversalClient
+	ownsClient    bool                         // redisClient 是否由任务管理器创建，Stop() 时关闭
+	memoryRedis   *memoryRedis                 // memory 后端的进程内 Redis
+	lockClients   []goredislib.UniversalClient // Redlock 使用的 Redis 实例
+	locker        Locker
+	cron          cronScheduler
+	clock         Clock
+	ctx           context.Context
+	cancel        context.CancelFunc
+	cfg           Cfg
+	log           Logger
+	tracer        trace.Tracer
+	nodeID        string
+	adminServer   *http.Server
+	controller    *Controller
+	controlSub    *goredislib.PubSub
+	started       atomic.Bool
+	stopped       atomic.Bool
+	leader        atomic.Bool
+	leaderDone    chan struct{}
+	deadmanDone   chan struct{}
+	heartbeatDone chan struct{}
+	jobPollerDone chan struct{}
+	aliveNodes    map[string]bool          // 上一次心跳时在线的节点，仅由心跳协程访问
+	members       atomic.Pointer[[]string] // 在线节点，按节点标识排序，用于一致性哈希分配任务
+
+	// 停止时等待正在执行的任务
+	runMu     sync.Mutex
+	running   sync.WaitGroup
+	executing atomic.Int64
+	stopping  chan struct{}
+	pool      *workerPool // 配置了 MaxConcurrentTasks 时的工作协程池
+	heldLocks sync.Map    // 任务名 -> 执行结束后按 WithHoldLockUntilNextFire 保持的 *taskMutex，移除任务时释放
+
+	mu           sync.RWMutex
+	tasks        map[string]*distributedTask
+	pausedGroups map[string]bool
+
+	middlewares []Middleware
+
+	subMu       sync.RWMutex
+	subscribers map[chan TaskEvent]struct{}
+
+	notifiers []Notifier
+
+	jobsMu      sync.RWMutex
+	jobHandlers map[string]JobHandler
+	consuming   bool                  // 是否已启动工作队列消费，之后注册的处理函数立即开始消费
+	consumers   sync.WaitGroup        // 工作队列消费协程
+	taskQueues  map[string]*workQueue // 工作节点正在消费的任务工作队列，由 dtm.mu 保护，未启动时为 nil
+
+	statsMu sync.Mutex
+	stats   map[string]*TaskStats
+
+	dynamic     map[string]DynamicTask // 当前节点上的动态任务，仅由同步协程访问
+	dynamicSync chan struct{}          // 请求立即同步动态任务
+	dynamicDone chan struct{}
 }
 
-// NewDistributedTaskManager 创建分布式任务管理器
+// distributedTask 已注册的分布式任务
+type distributedTask struct {
+	name        string
+	spec        string
+	description string // 表达式的易读描述，无法描述时为空
+	schedule    cron.Schedule
+	task        func(ctx context.Context) error
+	opts        taskOptions
+	entryID     cron.EntryID
+	paused      atomic.Bool
+}
+
+// NewDistributedTaskManager 创建分布式任务管理器，配置不合法时返回列出所有问题的 *ConfigError
 func NewDistributedTaskManager(cfg Cfg) (*DistributedTaskManager, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// 设置日志器
 	logger := cfg.Logger
 	if logger == nil {
-		logger = newDefaultLogger()
+		logger = NewSlogLogger(nil)
+	}
+	if cfg.LogLevel != "" {
+		level, err := cfg.LogLevel.slogLevel()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		logger = filterLevel(logger, level)
+	}
+
+	nodeID, err := newNodeID(cfg.NodeID, cfg.InstanceID)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// 设置链路追踪
+	tp := cfg.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
 	}
 
-	// 创建Redis客户端
-	client := goredislib.NewUniversalClient(&cfg.RedisCfg)
+	// 按 RedisTLS 设置 TLS
+	redisCfg := cfg.RedisCfg
+	quorumCfgs := cfg.RedisCfgQuorum
+	tlsConfig, err := cfg.RedisTLS.tlsConfig()
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to build Redis TLS config: %v", err)
+	}
+	if tlsConfig != nil {
+		redisCfg.TLSConfig = tlsConfig
+		quorumCfgs = make([]goredislib.UniversalOptions, len(cfg.RedisCfgQuorum))
+		copy(quorumCfgs, cfg.RedisCfgQuorum)
+		for i := range quorumCfgs {
+			if quorumCfgs[i].TLSConfig == nil {
+				quorumCfgs[i].TLSConfig = tlsConfig.Clone()
+			}
+		}
+	}
+
+	// memory 后端使用进程内的 Redis
+	var memRedis *memoryRedis
+	switch cfg.LockBackend {
+	case "", LockBackendRedis:
+	case LockBackendMemory:
+		r, err := startMemoryRedis()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		memRedis = r
+		redisCfg = goredislib.UniversalOptions{Addrs: []string{r.addr}}
+	default:
+		cancel()
+		return nil, fmt.Errorf("invalid lock backend %q", string(cfg.LockBackend))
+	}
+
+	// 创建Redis客户端，复用调用方的客户端时由调用方负责关闭
+	mode := cfg.RedisMode
+	if memRedis != nil {
+		mode = ""
+	}
+	client := cfg.RedisClient
+	ownsClient := client == nil || memRedis != nil
+	if ownsClient {
+		c, err := newRedisClient(mode, &redisCfg)
+		if err != nil {
+			cancel()
+			if memRedis != nil {
+				memRedis.close()
+			}
+			return nil, err
+		}
+		client = c
+	} else if err := checkClientMode(mode, client); err != nil {
+		cancel()
+		return nil, err
+	}
 
 	// 测试Redis连接
 	if err := client.Ping(ctx).Err(); err != nil {
 		cancel()
-		return nil, fmt.Errorf("failed to connect to Redis: %v", err)
+		if ownsClient {
+			client.Close()
+		}
+		if memRedis != nil {
+			memRedis.close()
+		}
+		return nil, connectError(mode, redisCfg.MasterName, err)
 	}
 
-	// 创建Redsync连接池
-	pool := goredis.NewPool(client)
-	rs := redsync.New(pool)
-	// 创建Cron实例
-	c := cron.New(cron.WithSeconds()) // 支持秒级定时
+	// 配置了部署模式时确认实际的拓扑与之一致
+	if err := verifyTopology(ctx, mode, client); err != nil {
+		cancel()
+		if ownsClient {
+			client.Close()
+		}
+		return nil, err
+	}
+
+	// 创建Cron实例，配置了时钟时按该时钟调度
+	clock := cfg.Clock
+	var c cronScheduler
+	if clock != nil {
+		c = newClockCron(clock)
+	} else {
+		clock = realClock{}
+		c = cron.New(cron.WithParser(specParser)) // 支持秒级定时
+	}
+
+	// 未配置锁后端时按 LockBackend 选择
+	locker := cfg.Locker
+	var lockClients []goredislib.UniversalClient
+	switch {
+	case locker != nil:
+	case memRedis != nil:
+		locker = newMemoryLocker(clock)
+	case len(quorumCfgs) > 0:
+		clients, err := connectQuorum(ctx, quorumCfgs, logger)
+		if err != nil {
+			cancel()
+			if ownsClient {
+				client.Close()
+			}
+			return nil, err
+		}
+		lockClients = clients
+		locker = NewRedisLocker(clients...)
+	default:
+		locker = NewRedisLocker(client)
+	}
 
-	return &DistributedTaskManager{
-		redisClient: client,
-		redsync:     rs,
-		cron:        c,
-		ctx:         ctx,
-		cancel:      cancel,
-		cfg:         cfg,
-		log:         logger,
-	}, nil
+	// 配置了部署模式时在实际的拓扑上获取并释放一次锁
+	if mode != "" {
+		key := cfg.LockCfg.Prefix + "redcorn-startup-check:" + nodeID
+		if err := verifyLockRoundTrip(ctx, locker, key, nodeID+"/startup-check"); err != nil {
+			cancel()
+			if ownsClient {
+				client.Close()
+			}
+			for _, c := range lockClients {
+				c.Close()
+			}
+			return nil, err
+		}
+	}
+
+	dtm := &DistributedTaskManager{
+		redisClient:  client,
+		ownsClient:   ownsClient,
+		memoryRedis:  memRedis,
+		lockClients:  lockClients,
+		locker:       locker,
+		cron:         c,
+		clock:        clock,
+		ctx:          ctx,
+		cancel:       cancel,
+		cfg:          cfg,
+		log:          logger,
+		tracer:       tp.Tracer(tracerName),
+		nodeID:       nodeID,
+		controller:   NewController(client, cfg.Namespace),
+		tasks:        make(map[string]*distributedTask),
+		pausedGroups: make(map[string]bool),
+		stopping:     make(chan struct{}),
+		stats:        make(map[string]*TaskStats),
+		subscribers:  make(map[chan TaskEvent]struct{}),
+		jobHandlers:  make(map[string]JobHandler),
+		notifiers:    newNotifiers(cfg.Notify),
+		dynamic:      make(map[string]DynamicTask),
+		dynamicSync:  make(chan struct{}, 1),
+	}
+	dtm.controller.clock = clock
+	if cfg.MaxConcurrentTasks > 0 {
+		dtm.pool = newWorkerPool(cfg.MaxConcurrentTasks, cfg.MaxQueuedTasks, func(e execution) {
+			dtm.executeDistributedTask(e.task, e.manual, e.tick)
+		})
+	}
+	return dtm, nil
 }
 
 // addDistributedTask 添加分布式定时任务
-func (dtm *DistributedTaskManager) addDistributedTask(name, spec string, task func()) error {
+func (dtm *DistributedTaskManager) addDistributedTask(name, spec string, task func(ctx context.Context) error, opts ...TaskOption) error {
+	dtm.mu.Lock()
+	defer dtm.mu.Unlock()
+
+	if err := dtm.checkAddTask(name); err != nil {
+		return err
+	}
+
+	t, err := dtm.newDistributedTask(name, spec, task, newTaskOptions(opts))
+	if err == nil {
+		err = dtm.checkTaskCycle(t)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add cron task %s: %v", name, err)
+	}
+
+	dtm.addTask(t)
+	return nil
+}
+
+// checkAddTask 检查任务能否添加，调用方需持有 dtm.mu
+func (dtm *DistributedTaskManager) checkAddTask(name string) error {
+	if dtm.isStopping() {
+		return ErrManagerStopped
+	}
+	if _, exists := dtm.tasks[name]; exists {
+		return fmt.Errorf("task %s already exists", name)
+	}
+	return nil
+}
+
+// addTask 添加新任务，Start() 之后添加的任务立即参与调度，调用方需持有 dtm.mu
+func (dtm *DistributedTaskManager) addTask(t *distributedTask) {
+	dtm.installTask(t)
+
+	if t.description != "" {
+		dtm.log.Info("Added distributed task: ", t.name, ", schedule: ", t.spec, " (", t.description, ")")
+	} else {
+		dtm.log.Info("Added distributed task: ", t.name, ", schedule: ", t.spec)
+	}
+
+	// 运行中添加的任务同样补偿停机期间错过的调度
+	if dtm.scheduling() && dtm.misfirePolicy(t) != MisfireIgnore {
+		go dtm.catchUpTask(t)
+	}
+	if dtm.scheduling() && t.opts.runOnStart {
+		go dtm.runOnStart(t)
+	}
+}
+
+// scheduling 当前节点是否正在运行定时调度
+func (dtm *DistributedTaskManager) scheduling() bool {
+	if !dtm.started.Load() || dtm.isStopping() || dtm.isWorker() {
+		return false
+	}
+	return !dtm.leaderMode() || dtm.leader.Load()
+}
+
+// installTask 将任务加入定时调度并注册，调用方需持有 dtm.mu
+func (dtm *DistributedTaskManager) installTask(t *distributedTask) {
+	// 加入已暂停分组的任务同样处于暂停状态
+	if t.opts.group != "" && dtm.pausedGroups[t.opts.group] {
+		t.paused.Store(true)
+	}
+
+	// 添加定时任务
+	dtm.scheduleTask(t)
+	dtm.tasks[t.name] = t
+	dtm.startTaskQueue(t)
+	dtm.registerTask(t)
+	dtm.recordNextRun(t)
+}
+
+// replaceTask 用新的任务对象替换旧任务，沿用暂停状态，调用方需持有 dtm.mu
+func (dtm *DistributedTaskManager) replaceTask(old, t *distributedTask) {
+	t.paused.Store(old.paused.Load())
+	dtm.cron.Remove(old.entryID)
+	dtm.installTask(t)
+}
+
+// uninstallTask 注销任务的定时调度和注册信息并删除执行次数计数器，调用方需持有 dtm.mu
+func (dtm *DistributedTaskManager) uninstallTask(t *distributedTask) {
+	dtm.detachTask(t)
+	dtm.deleteRunCount(t)
+}
+
+// detachTask 注销任务的定时调度和注册信息，释放锁状态，调用方需持有 dtm.mu
+func (dtm *DistributedTaskManager) detachTask(t *distributedTask) {
+	dtm.cron.Remove(t.entryID)
+	delete(dtm.tasks, t.name)
+	dtm.stopTaskQueue(t.name)
+	dtm.unregisterTask(t)
+	dtm.releaseTaskLocks(t)
+}
+
+// newDistributedTask 解析表达式并创建任务，尚未加入调度
+func (dtm *DistributedTaskManager) newDistributedTask(name, spec string, task func(ctx context.Context) error, options taskOptions) (*distributedTask, error) {
+	options = dtm.resolveStartAfter(options)
+	schedule, err := dtm.parseSpec(spec, options)
+	if err != nil {
+		return nil, err
+	}
+	return dtm.newTask(name, spec, schedule, task, options)
+}
+
+// newTask 按已解析的调度创建任务，尚未加入调度
+func (dtm *DistributedTaskManager) newTask(name, spec string, schedule cron.Schedule, task func(ctx context.Context) error, options taskOptions) (*distributedTask, error) {
+	if err := dtm.checkRateLimiter(options); err != nil {
+		return nil, err
+	}
+	if err := options.quota.validate(); err != nil {
+		return nil, err
+	}
+	if err := options.validateThen(name); err != nil {
+		return nil, err
+	}
+	if err := options.validateDependsOn(name); err != nil {
+		return nil, err
+	}
+	if err := options.validateLifetime(); err != nil {
+		return nil, err
+	}
+	if err := dtm.validateJitter(options); err != nil {
+		return nil, err
+	}
+	if err := dtm.validateHoldLock(options); err != nil {
+		return nil, err
+	}
+
+	t := &distributedTask{
+		name:     name,
+		spec:     spec,
+		schedule: schedule,
+		task:     task,
+		opts:     options,
+	}
+	t.description, _ = DescribeSpec(spec)
+	return t, nil
+}
+
+// scheduleTask 将任务加入定时调度
+func (dtm *DistributedTaskManager) scheduleTask(t *distributedTask) {
+	if s, ok := t.schedule.(onceSchedule); ok {
+		job := &onceJob{dtm: dtm, task: t, at: s.at}
+		t.entryID = dtm.cron.Schedule(job, job)
+		return
+	}
+	if !t.opts.endTime.IsZero() {
+		job := &endJob{dtm: dtm, task: t, end: t.opts.endTime}
+		t.entryID = dtm.cron.Schedule(job, job)
+		return
+	}
+
 	// 包装任务，添加分布式锁逻辑
+	// 调度时刻按秒取整，各节点按自己的时钟得到同一个时刻
 	wrappedTask := func() {
-		dtm.executeDistributedTask(name, task)
+		dtm.dispatch(t, false, dtm.clock.Now().Truncate(time.Second))
 	}
+	t.entryID = dtm.cron.Schedule(t.schedule, cron.FuncJob(wrappedTask))
+}
 
-	// 添加定时任务
-	_, err := dtm.cron.AddFunc(spec, wrappedTask)
+// updateDistributedTask 替换任务的表达式，task 为 nil 时沿用原任务函数，opts 为 nil 时沿用原选项
+// 替换为新的任务对象而不是原地修改，正在执行的任务不受影响
+func (dtm *DistributedTaskManager) updateDistributedTask(name, spec string, task func(ctx context.Context) error, opts []TaskOption) error {
+	dtm.mu.Lock()
+	defer dtm.mu.Unlock()
+
+	old, exists := dtm.tasks[name]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+	if task == nil {
+		task = old.task
+	}
+	options := old.opts
+	if opts != nil {
+		options = newTaskOptions(opts)
+	}
+
+	t, err := dtm.newDistributedTask(name, spec, task, options)
+	if err == nil {
+		err = dtm.checkTaskCycle(t)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to add cron task %s: %v", name, err)
+		return fmt.Errorf("failed to update cron task %s: %v", name, err)
 	}
+	dtm.replaceTask(old, t)
 
-	dtm.log.Info("Added distributed task: ", name, ", schedule: ", spec)
+	dtm.log.Info("Updated distributed task: ", name, ", schedule: ", old.spec, " -> ", spec)
 	return nil
 }
 
-// executeDistributedTask 执行分布式任务（带锁）
-func (dtm *DistributedTaskManager) executeDistributedTask(taskName string, task func()) {
-	lockName := dtm.cfg.LockCfg.Prefix + taskName
-	mutex := dtm.redsync.NewMutex(lockName, redsync.WithExpiry(dtm.cfg.LockCfg.Expiry))
+// executeDistributedTask 执行分布式任务（带锁），manual 表示手动触发，手动触发不受暂停影响
+// tick 为定时调度的调度时刻，手动触发和补执行时为零值
+func (dtm *DistributedTaskManager) executeDistributedTask(t *distributedTask, manual bool, tick time.Time) {
+	taskName := t.name
 
-	// 尝试获取分布式锁
-	if err := mutex.TryLock(); err != nil {
-		if errors.Is(err, redsync.ErrFailed) {
-			dtm.log.Info("Task ", taskName, ": is running, skipping execution")
-		} else {
-			dtm.log.Error("Task ", taskName, ": Failed to acquire lock, skipping execution, err:", err)
-		}
+	// 暂停的任务不参与调度
+	if t.paused.Load() && !manual {
+		dtm.logRun(slog.LevelDebug, taskName, "", "Paused, skipping execution")
+		return
+	}
+
+	// 管理器停止后不再开始新的执行
+	if !dtm.beginExecution() {
+		dtm.logRun(slog.LevelDebug, taskName, "", "Manager is stopping, skipping execution")
 		return
 	}
+	defer dtm.endExecution()
 
-	// 确保释放锁
-	defer func() {
-		if ok, err := mutex.Unlock(); !ok || err != nil {
-			if errors.Is(err, redsync.ErrLockAlreadyExpired) {
-				dtm.log.Warn("WARN!!! Task ", taskName, ": LockCfg already expired, skipping release")
-			} else {
-				dtm.log.Error("Task ", taskName, ": Failed to release lock: ", err)
+	// 记录本次执行，最后写入执行历史
+	runID := newRunID()
+	rec := &ExecutionRecord{
+		RunID:     runID,
+		Task:      taskName,
+		Node:      dtm.nodeID,
+		StartedAt: dtm.clock.Now(),
+	}
+	defer dtm.finishExecution(t, runID, rec)
+
+	// 每次执行对应一个 span，上下文携带 span 和执行标识
+	spanCtx, span := dtm.startTaskSpan(t, runID)
+	defer span.End()
+
+	// 集群暂停期间所有执行都跳过，包括手动触发
+	if !dtm.checkClusterPause(spanCtx, t, rec) {
+		return
+	}
+
+	// 已达到最大执行次数的任务不再争抢锁，并从当前节点移除
+	if t.opts.maxRuns > 0 && !manual && !dtm.checkRunCount(spanCtx, t, rec) {
+		return
+	}
+
+	var mutex *taskMutex
+	// 工作节点执行的是调度节点已经认领过的执行，不受领导者和一致性哈希分配的限制
+	if dtm.leaderMode() && !dtm.isWorker() {
+		// 领导者模式下只有领导者执行任务，无需争抢锁
+		if !dtm.IsLeader() {
+			rec.Status = ExecutionSkipped
+			rec.SkipReason = SkipReasonNotLeader
+			dtm.logRun(slog.LevelInfo, taskName, runID, "Node is not leader, skipping execution")
+			return
+		}
+		dtm.logRun(slog.LevelInfo, taskName, runID, "Running on leader, starting execution")
+	} else {
+		// 一致性哈希模式下只有分配到任务的节点争抢锁，手动触发不受分配限制
+		if dtm.cfg.SchedulingMode == SchedulingHash && !manual && !dtm.isWorker() {
+			if owner, ok := dtm.assignedToSelf(taskName); !ok {
+				rec.Status = ExecutionSkipped
+				rec.SkipReason = SkipReasonNotAssigned
+				dtm.logRun(slog.LevelDebug, taskName, runID, "Task assigned to another node, skipping execution", slog.String("assigned_node", owner))
+				return
 			}
-		} else {
-			dtm.log.Info("Task ", taskName, ": LockCfg released successfully")
 		}
-	}()
+		m, release, ok := dtm.acquireTaskLock(spanCtx, span, t, rec, tick)
+		if !ok {
+			return
+		}
+		defer release()
+		mutex = m
+		if !tick.IsZero() && !dtm.claimTick(spanCtx, t, tick, rec) {
+			return
+		}
+		if t.opts.fencing {
+			if !dtm.nextFencingToken(spanCtx, t, rec) {
+				return
+			}
+			spanCtx = withFencingToken(spanCtx, rec.FencingToken)
+		}
+		dtm.logRun(slog.LevelInfo, taskName, runID, "Lock acquired, starting execution")
+	}
+
+	// 依赖任务只在前置任务都已成功的调度时刻执行
+	if !tick.IsZero() && len(t.opts.dependsOn) > 0 && !dtm.checkDependencies(spanCtx, t, tick, rec) {
+		return
+	}
+
+	// 处于维护窗口内的调度跳过，手动触发不受影响
+	if !manual && !dtm.checkMaintenance(spanCtx, t, rec) {
+		return
+	}
+
+	// 执行条件不满足时跳过，不消耗限流令牌和配额
+	if t.opts.condition != nil && !manual && !dtm.checkCondition(spanCtx, t, rec) {
+		return
+	}
+
+	// 集群范围限流和执行配额，只有实际执行的节点消耗令牌和配额
+	if t.opts.rateLimiter != "" && !dtm.takeToken(spanCtx, t, rec) {
+		return
+	}
+	if t.opts.quota != nil && !dtm.takeQuota(spanCtx, t, rec) {
+		return
+	}
+
+	// 计入最大执行次数，最后一次执行结束后移除任务
+	if t.opts.maxRuns > 0 && !manual {
+		ok, last := dtm.countRun(spanCtx, t, rec)
+		if !ok {
+			return
+		}
+		if last {
+			defer dtm.expireTask(t, "max runs reached")
+		}
+	}
+
+	// 随机延迟执行，错开各任务对下游系统的访问
+	if t.opts.jitter > 0 && !manual && !dtm.waitJitter(t, mutex, rec) {
+		return
+	}
+	dtm.emit(TaskEvent{Type: EventStarted, RunID: runID, Task: taskName, Node: dtm.nodeID, Time: dtm.clock.Now()})
+
+	// 任务上下文派生自管理器上下文，Stop() 或超时时被取消
+	ctx, cancel := dtm.taskContext(spanCtx, t)
+	defer cancel()
 
-	dtm.log.Info("Task ", taskName, ": LockCfg acquired, starting execution")
+	// 启动锁续期看门狗，防止长任务执行期间锁过期
+	stopWatchdog := dtm.startWatchdog(ctx, cancel, taskName, mutex)
+	defer stopWatchdog()
 
 	// 执行任务
-	startTime := time.Now()
-	task()
-	duration := time.Since(startTime)
+	info := TaskInfo{Name: taskName, RunID: runID, Group: t.opts.group, Spec: t.spec, NodeID: dtm.nodeID, Manual: manual, FencingToken: rec.FencingToken}
+	var err error
+	startTime := dtm.clock.Now()
+	called := dtm.runMiddleware(info, func() {
+		err = dtm.runWithRetry(ctx, t, mutex)
+	})
+	duration := dtm.clock.Since(startTime)
+
+	if !called {
+		rec.Status = ExecutionSkipped
+		rec.SkipReason = SkipReasonMiddleware
+		dtm.logRun(slog.LevelInfo, taskName, runID, "Skipped by middleware")
+		return
+	}
+
+	rec.Duration = duration
+	if err != nil {
+		rec.Status = ExecutionFailed
+		rec.setError(err)
+		recordSpanError(span, err)
+		dtm.logRun(slog.LevelError, taskName, runID, "Failed", slog.Duration("duration", duration), slog.Any("error", err))
+		return
+	}
+	rec.Status = ExecutionSuccess
+	dtm.logRun(slog.LevelInfo, taskName, runID, "Completed", slog.Duration("duration", duration))
 
-	dtm.log.Info("Task ", taskName, ": Completed in ", duration)
+	// 链式任务：执行成功后触发下游任务，并为依赖本任务的任务记录成功
+	if len(t.opts.then) > 0 {
+		dtm.triggerTasks(t, runID, t.opts.then...)
+	}
+	dtm.notifyDependents(t, runID)
 }
 
-// Start 启动任务管理器
+// finishExecution 执行结束后分发事件、写入执行历史，实际执行过的任务同时更新集群状态并上报监控地址，失败时发送通知
+func (dtm *DistributedTaskManager) finishExecution(t *distributedTask, runID string, rec *ExecutionRecord) {
+	dtm.recordStats(rec)
+	dtm.emit(executionEvent(runID, rec, dtm.clock.Now()))
+	dtm.recordHistory(rec)
+	if rec.Status != ExecutionSkipped {
+		dtm.recordStatus(t, rec)
+		dtm.ping(t, rec)
+	}
+	if rec.Status == ExecutionFailed {
+		dtm.notifyFailure(t, runID, rec)
+	}
+}
+
+// taskContext 基于 parent 创建单次任务执行的上下文
+func (dtm *DistributedTaskManager) taskContext(parent context.Context, t *distributedTask) (context.Context, context.CancelFunc) {
+	timeout := dtm.cfg.TaskTimeout
+	if t.opts.timeout > 0 {
+		timeout = t.opts.timeout
+	}
+	if timeout > 0 {
+		return context.WithTimeout(parent, timeout)
+	}
+	return context.WithCancel(parent)
+}
+
+// key 生成命名空间下的 Redis 键
+func (dtm *DistributedTaskManager) key(parts ...string) string {
+	return namespacedKey(dtm.controller.namespace, parts...)
+}
+
+// Start 启动任务管理器，启动后仍可通过 AddTask、AddScheduler 等方法添加任务
 func (dtm *DistributedTaskManager) Start() {
-	dtm.cron.Start()
+	dtm.started.Store(true)
+	switch {
+	case dtm.isWorker():
+		// 工作节点不运行定时调度，只执行调度节点加入工作队列的任务
+	case dtm.leaderMode():
+		// 当选领导者后才启动定时调度
+		dtm.startLeaderElection()
+	default:
+		dtm.cron.Start()
+		dtm.catchUpMisfires()
+		dtm.runOnStartTasks()
+	}
+	dtm.startHeartbeat()
+	dtm.startControlListener()
+	dtm.startDeadman()
+	dtm.startDynamicTasks()
+	dtm.startJobPoller()
+	dtm.startConsumers()
+	dtm.startTaskQueues()
+	dtm.startAdminHTTP()
 	dtm.log.Info("Distributed task manager started")
 }
 
-// Stop 停止任务管理器
+// Stop 停止任务管理器，等待正在执行的任务完成，最长等待 Cfg.ShutdownTimeout，超时后取消任务上下文
 func (dtm *DistributedTaskManager) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), dtm.shutdownTimeout())
+	defer cancel()
+	_ = dtm.Shutdown(ctx)
+}
+
+// Shutdown 停止任务管理器，语义与 http.Server.Shutdown 一致：
+// 先停止调度，然后等待正在执行的任务完成；ctx 结束时取消任务上下文，强制结束并返回 ctx.Err()
+// 管理器已停止时返回 ErrManagerStopped
+func (dtm *DistributedTaskManager) Shutdown(ctx context.Context) error {
+	// 停止定时器，不再开始新的执行
+	if !dtm.beginStop() {
+		return ErrManagerStopped
+	}
 	dtm.log.Info("Stopping distributed task manager...")
 
-	// 停止定时器
-	ctx := dtm.cron.Stop()
-	<-ctx.Done()
+	// 关闭管理接口
+	dtm.stopAdminHTTP()
+	dtm.cron.Stop()
+	if dtm.pool != nil {
+		if dropped := dtm.pool.close(); dropped > 0 {
+			dtm.log.Warn("Dropped ", dropped, " queued executions")
+		}
+	}
+
+	// 等待正在执行的任务完成，ctx 结束时取消任务上下文
+	err := dtm.waitExecutions(ctx)
 
 	// 取消上下文
 	dtm.cancel()
 
-	// 等待所有任务完成
-	//dtm.wg.Wait()
+	// 停止接收控制指令、漏执行检测、动态任务同步、延迟任务轮询、工作队列消费和节点心跳，释放领导者租约
+	dtm.stopControlListener()
+	dtm.stopDeadman()
+	dtm.stopDynamicTasks()
+	dtm.stopJobPoller()
+	dtm.stopConsumers()
+	dtm.stopLeaderElection()
+	dtm.stopHeartbeat()
 
-	// 关闭Redis连接
-	if err := dtm.redisClient.Close(); err != nil {
-		dtm.log.Error("Error closing RedisCfg connection: ", err)
+	// 关闭Redis连接，复用的客户端由调用方关闭
+	if dtm.ownsClient {
+		if err := dtm.redisClient.Close(); err != nil {
+			dtm.log.Error("Error closing RedisCfg connection: ", err)
+		}
+	}
+	for _, client := range dtm.lockClients {
+		if err := client.Close(); err != nil {
+			dtm.log.Error("Error closing Redlock connection: ", err)
+		}
+	}
+	if dtm.memoryRedis != nil {
+		dtm.memoryRedis.close()
 	}
 
+	dtm.stopped.Store(true)
 	dtm.log.Info("Distributed task manager stopped")
+	return err
+}
+
+// Run 启动任务管理器并阻塞，收到 SIGINT 或 SIGTERM 后优雅停止，等待时长同 Stop()
+// 返回值同 Shutdown；其他协程调用 Stop() 或 Shutdown() 时 Run 返回 nil
+func (dtm *DistributedTaskManager) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dtm.Start()
+	select {
+	case <-ctx.Done():
+	case <-dtm.stopping:
+		return nil
+	}
+	// 恢复默认的信号处理，再次收到信号时立即退出
+	stop()
+	dtm.log.Info("Received shutdown signal")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), dtm.shutdownTimeout())
+	defer cancel()
+	return dtm.Shutdown(shutdownCtx)
 }
 
 // GetRedisClient 获取Redis客户端（供外部使用）
@@ -159,22 +915,213 @@ func (dtm *DistributedTaskManager) GetRedisClient() goredislib.UniversalClient {
 	return dtm.redisClient
 }
 
+// GetController 获取基于同一 Redis 客户端和命名空间的集群控制器（供外部使用）
+func (dtm *DistributedTaskManager) GetController() *Controller {
+	return dtm.controller
+}
+
 // GetContext 获取上下文（供外部使用）
 func (dtm *DistributedTaskManager) GetContext() context.Context {
 	return dtm.ctx
 }
 
-// AddScheduler 批量添加任务调度器中的所有任务
+// AddScheduler 批量添加任务调度器中的所有任务，Start() 之前和之后均可调用
+// 所有任务校验通过后才会添加，任一任务已存在或表达式无效时不添加任何任务
 func (dtm *DistributedTaskManager) AddScheduler(scheduler *TaskScheduler) error {
-	for name, schedule := range scheduler.GetAll() {
-		if err := dtm.addDistributedTask(name, schedule.Cron, schedule.Task); err != nil {
+	dtm.mu.Lock()
+	defer dtm.mu.Unlock()
+
+	schedules := scheduler.GetAll()
+	tasks := make([]*distributedTask, 0, len(schedules))
+	for name, schedule := range schedules {
+		if err := dtm.checkAddTask(name); err != nil {
 			return fmt.Errorf("failed to add task %s: %v", name, err)
 		}
+		t, err := dtm.newDistributedTask(name, schedule.Cron, schedule.handler(), newTaskOptions(schedule.Options))
+		if err != nil {
+			return fmt.Errorf("failed to add task %s: %v", name, err)
+		}
+		tasks = append(tasks, t)
+	}
+	if err := dtm.checkTaskCycle(tasks...); err != nil {
+		return fmt.Errorf("failed to add scheduler: %v", err)
+	}
+
+	for _, t := range tasks {
+		dtm.addTask(t)
+	}
+	return nil
+}
+
+// ReplaceScheduler 用调度器中的任务整体替换当前注册的任务：
+// 调度器中没有的任务被移除（RunAt / RunAfter 添加的一次性任务除外），已有的任务被更新，新的任务被添加，适用于按配置重新加载任务
+// 所有表达式校验通过后才会生效，任一任务无效时不做任何修改；被更新的任务沿用原暂停状态，正在执行的任务不受影响
+func (dtm *DistributedTaskManager) ReplaceScheduler(scheduler *TaskScheduler) error {
+	dtm.mu.Lock()
+	defer dtm.mu.Unlock()
+
+	if dtm.isStopping() {
+		return ErrManagerStopped
+	}
+
+	schedules := scheduler.GetAll()
+	tasks := make([]*distributedTask, 0, len(schedules))
+	for name, schedule := range schedules {
+		t, err := dtm.newDistributedTask(name, schedule.Cron, schedule.handler(), newTaskOptions(schedule.Options))
+		if err != nil {
+			return fmt.Errorf("failed to replace scheduler: task %s: %v", name, err)
+		}
+		tasks = append(tasks, t)
+	}
+	options := make(map[string]taskOptions, len(tasks))
+	for _, t := range tasks {
+		options[t.name] = t.opts
+	}
+	for name, old := range dtm.tasks {
+		if _, replaced := options[name]; !replaced && old.isOnce() {
+			options[name] = old.opts
+		}
+	}
+	if err := checkDependencyCycle(options); err != nil {
+		return fmt.Errorf("failed to replace scheduler: %v", err)
+	}
+
+	removed := 0
+	for name, old := range dtm.tasks {
+		if _, keep := schedules[name]; !keep && !old.isOnce() {
+			dtm.uninstallTask(old)
+			removed++
+		}
+	}
+
+	added, updated := 0, 0
+	for _, t := range tasks {
+		if old, exists := dtm.tasks[t.name]; exists {
+			dtm.replaceTask(old, t)
+			updated++
+		} else {
+			dtm.addTask(t)
+			added++
+		}
+	}
+
+	dtm.log.Info("Replaced scheduler: added ", added, ", updated ", updated, ", removed ", removed, " tasks")
+	return nil
+}
+
+// RemoveScheduler 移除调度器中的所有任务，当前未注册的任务会被忽略
+func (dtm *DistributedTaskManager) RemoveScheduler(scheduler *TaskScheduler) {
+	dtm.mu.Lock()
+	defer dtm.mu.Unlock()
+
+	removed := 0
+	for name := range scheduler.GetAll() {
+		if t, exists := dtm.tasks[name]; exists {
+			dtm.uninstallTask(t)
+			removed++
+		}
+	}
+	dtm.log.Info("Removed scheduler: removed ", removed, " tasks")
+}
+
+// AddTask 仍然支持单个任务添加（保持灵活性），Start() 之后添加的任务立即参与调度
+func (dtm *DistributedTaskManager) AddTask(name, cron string, task func(), opts ...TaskOption) error {
+	return dtm.addDistributedTask(name, cron, func(context.Context) error {
+		task()
+		return nil
+	}, opts...)
+}
+
+// AddTaskCtx 添加可感知上下文的任务，上下文在 Stop() 或任务超时时被取消
+func (dtm *DistributedTaskManager) AddTaskCtx(name, cron string, task func(ctx context.Context), opts ...TaskOption) error {
+	return dtm.addDistributedTask(name, cron, func(ctx context.Context) error {
+		task(ctx)
+		return nil
+	}, opts...)
+}
+
+// AddTaskE 添加返回错误的任务，可通过 WithRetry 配置失败重试
+func (dtm *DistributedTaskManager) AddTaskE(name, cron string, task func(ctx context.Context) error, opts ...TaskOption) error {
+	return dtm.addDistributedTask(name, cron, task, opts...)
+}
+
+// RemoveTask 移除任务，注销其定时调度，释放当前节点按 WithHoldLockUntilNextFire 保持的锁并删除调度时刻的认领记录
+// 正在执行中的任务会继续运行，并在结束时照常释放其分布式锁
+func (dtm *DistributedTaskManager) RemoveTask(name string) error {
+	dtm.mu.Lock()
+	defer dtm.mu.Unlock()
+
+	t, exists := dtm.tasks[name]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, name)
 	}
+
+	dtm.uninstallTask(t)
+
+	dtm.log.Info("Removed distributed task: ", name)
 	return nil
 }
 
-// AddTask 仍然支持单个任务添加（保持灵活性）
-func (dtm *DistributedTaskManager) AddTask(name, cron string, task func()) error {
-	return dtm.addDistributedTask(name, cron, task)
+// UpdateTask 在运行时修改任务的 Cron 表达式，沿用原任务函数和选项，无需重启即可调整调度
+func (dtm *DistributedTaskManager) UpdateTask(name, spec string) error {
+	return dtm.updateDistributedTask(name, spec, nil, nil)
+}
+
+// UpdateTaskE 在运行时同时替换任务的 Cron 表达式和任务函数，沿用原选项
+func (dtm *DistributedTaskManager) UpdateTaskE(name, spec string, task func(ctx context.Context) error) error {
+	return dtm.updateDistributedTask(name, spec, task, nil)
+}
+
+// PauseTask 暂停任务，暂停期间跳过定时调度，手动触发不受影响
+func (dtm *DistributedTaskManager) PauseTask(name string) error {
+	return dtm.setPaused(name, true)
+}
+
+// ResumeTask 恢复已暂停的任务
+func (dtm *DistributedTaskManager) ResumeTask(name string) error {
+	return dtm.setPaused(name, false)
+}
+
+// IsPaused 查询任务在当前节点是否处于暂停状态
+func (dtm *DistributedTaskManager) IsPaused(name string) (bool, error) {
+	dtm.mu.RLock()
+	t, exists := dtm.tasks[name]
+	dtm.mu.RUnlock()
+	if !exists {
+		return false, fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+	return t.paused.Load(), nil
+}
+
+// setPaused 设置任务的暂停状态
+func (dtm *DistributedTaskManager) setPaused(name string, paused bool) error {
+	dtm.mu.RLock()
+	t, exists := dtm.tasks[name]
+	dtm.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+
+	if t.paused.Swap(paused) != paused {
+		if paused {
+			dtm.logRun(slog.LevelInfo, name, "", "Paused")
+		} else {
+			dtm.logRun(slog.LevelInfo, name, "", "Resumed")
+		}
+	}
+	return nil
+}
+
+// TriggerTask 立即触发一次已注册任务的执行（异步），与定时调度无关，仍然遵循分布式锁
+func (dtm *DistributedTaskManager) TriggerTask(name string) error {
+	dtm.mu.RLock()
+	t, exists := dtm.tasks[name]
+	dtm.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+
+	dtm.logRun(slog.LevelInfo, name, "", "Triggered manually")
+	go dtm.dispatch(t, true, time.Time{})
+	return nil
 }
@@ -0,0 +1,21 @@
+// Package redigoadapter 使用 redigo 连接池执行 redCorn 的分布式锁操作
+//
+// redigo 连接池只用于加锁，任务管理器本身仍需要 go-redis 的连接配置
+package redigoadapter
+
+import (
+	"github.com/go-redsync/redsync/v4/redis"
+	redsyncredigo "github.com/go-redsync/redsync/v4/redis/redigo"
+	redigo "github.com/gomodule/redigo/redis"
+	"github.com/kzdgt/redCorn"
+)
+
+// NewLocker 基于已有的 redigo 连接池创建分布式锁，用于 Cfg.Locker
+// 传入多个相互独立的实例的连接池时使用 Redlock 算法
+func NewLocker(pools ...*redigo.Pool) redCorn.Locker {
+	redsyncPools := make([]redis.Pool, len(pools))
+	for i, pool := range pools {
+		redsyncPools[i] = redsyncredigo.NewPool(pool)
+	}
+	return redCorn.NewRedsyncLocker(redsyncPools...)
+}
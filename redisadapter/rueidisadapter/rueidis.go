@@ -0,0 +1,22 @@
+// Package rueidisadapter 使用 rueidis 客户端执行 redCorn 的分布式锁操作
+//
+// 只有锁经由 rueidis 发送，任务管理器的其余读写仍使用 go-redis 客户端（Cfg.RedisCfg 或 Cfg.RedisClient）
+package rueidisadapter
+
+import (
+	"github.com/go-redsync/redsync/v4/redis"
+	redsyncrueidis "github.com/go-redsync/redsync/v4/redis/rueidis"
+	"github.com/kzdgt/redCorn"
+	"github.com/redis/rueidis"
+	"github.com/redis/rueidis/rueidiscompat"
+)
+
+// NewLocker 基于 rueidis 客户端创建分布式锁，用于 Cfg.Locker
+// 每次执行的加锁、续期和释放都经由 rueidis 的自动流水线发送；传入多个相互独立的实例时使用 Redlock 算法
+func NewLocker(clients ...rueidis.Client) redCorn.Locker {
+	pools := make([]redis.Pool, len(clients))
+	for i, client := range clients {
+		pools[i] = redsyncrueidis.NewPool(rueidiscompat.NewAdapter(client))
+	}
+	return redCorn.NewRedsyncLocker(pools...)
+}
@@ -0,0 +1,218 @@
+package redCorn
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	goredislib "github.com/go-redis/redis/v8"
+)
+
+const (
+	nodeHeartbeatInterval = 10 * time.Second // 节点心跳间隔
+	nodeTTL               = 30 * time.Second // 超过该时间没有心跳的节点视为已下线
+	nodePruneAfter        = 10 * time.Minute // 下线超过该时间的节点从节点列表中删除，此前各节点都已发现其下线
+)
+
+// NodeInfo 集群中的节点
+type NodeInfo struct {
+	Node     string    `json:"node"`
+	LastSeen time.Time `json:"last_seen"` // 最近一次心跳的时间
+	Alive    bool      `json:"alive"`     // 节点的存活键尚未过期，即最近一次心跳在 30 秒内
+}
+
+// NodeEventType 节点事件类型
+type NodeEventType string
+
+const (
+	NodeJoined NodeEventType = "joined" // 节点开始心跳
+	NodeLeft   NodeEventType = "left"   // 节点正常停止或心跳超时
+)
+
+// NodeEvent 集群拓扑变化事件
+type NodeEvent struct {
+	Type       NodeEventType
+	Node       string
+	Time       time.Time
+	Expired    bool // 节点因心跳超时被判定为下线，false 表示节点正常停止
+	AliveNodes int  // 变化后集群中在线的节点数
+}
+
+// NodeHooks 集群节点变化的回调，均为可选
+// 每个节点在自己的心跳中对比集群中的在线节点，因此同一变化会在每个在线节点上各回调一次；
+// 回调在心跳协程中同步调用，应尽快返回
+type NodeHooks struct {
+	OnJoin  func(event NodeEvent) // 有节点加入集群
+	OnLeave func(event NodeEvent) // 有节点离开集群
+}
+
+// startHeartbeat 启动节点心跳，定期把当前节点写入 <Namespace>:nodes 哈希并刷新 <Namespace>:nodes:<节点> 存活键
+func (dtm *DistributedTaskManager) startHeartbeat() {
+	dtm.heartbeat()
+
+	dtm.heartbeatDone = make(chan struct{})
+	go func() {
+		defer close(dtm.heartbeatDone)
+
+		ticker := time.NewTicker(nodeHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-dtm.ctx.Done():
+				return
+			case <-ticker.C:
+				dtm.heartbeat()
+			}
+		}
+	}()
+}
+
+// heartbeat 记录当前节点的心跳时间，并刷新带过期时间的存活键
+// 节点是否在线由存活键是否过期决定，即由 Redis 判断，不比较各节点的时钟；哈希中的时间只用于展示
+func (dtm *DistributedTaskManager) heartbeat() {
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+	_, err := dtm.redisClient.Pipelined(ctx, func(pipe goredislib.Pipeliner) error {
+		pipe.HSet(ctx, dtm.key("nodes"), dtm.nodeID, time.Now().UnixMilli())
+		pipe.Set(ctx, dtm.key("nodes", dtm.nodeID), 1, nodeTTL)
+		return nil
+	})
+	if err != nil {
+		dtm.log.Error("Failed to record node heartbeat: ", err)
+		return
+	}
+	dtm.watchNodes(ctx)
+}
+
+// watchNodes 对比本次与上一次心跳时的在线节点，记录集群拓扑变化并调用 NodeHooks
+// 首次心跳只记录当前在线的节点，不视为变化
+func (dtm *DistributedTaskManager) watchNodes(ctx context.Context) {
+	nodes, err := dtm.controller.Nodes(ctx)
+	if err != nil {
+		dtm.log.Error("Failed to list nodes: ", err)
+		return
+	}
+
+	alive := make(map[string]bool, len(nodes))
+	registered := make(map[string]bool, len(nodes))
+	var stale []string
+	for _, n := range nodes {
+		registered[n.Node] = true
+		if n.Alive {
+			alive[n.Node] = true
+		} else if time.Since(n.LastSeen) > nodePruneAfter {
+			stale = append(stale, n.Node)
+		}
+	}
+	dtm.pruneNodes(ctx, stale)
+	prev := dtm.aliveNodes
+	dtm.aliveNodes = alive
+	dtm.setMembers(alive)
+	if prev == nil {
+		return
+	}
+
+	now := time.Now()
+	for _, n := range nodes {
+		if n.Alive && !prev[n.Node] {
+			dtm.log.Info("Node joined: ", n.Node, ", alive nodes: ", len(alive))
+			if dtm.cfg.NodeHooks.OnJoin != nil {
+				dtm.cfg.NodeHooks.OnJoin(NodeEvent{Type: NodeJoined, Node: n.Node, Time: now, AliveNodes: len(alive)})
+			}
+		}
+	}
+
+	var left []string
+	for node := range prev {
+		if !alive[node] {
+			left = append(left, node)
+		}
+	}
+	sort.Strings(left)
+	for _, node := range left {
+		expired := registered[node]
+		if expired {
+			dtm.log.Warn("Node heartbeat expired: ", node, ", alive nodes: ", len(alive))
+		} else {
+			dtm.log.Info("Node left: ", node, ", alive nodes: ", len(alive))
+		}
+		if dtm.cfg.NodeHooks.OnLeave != nil {
+			dtm.cfg.NodeHooks.OnLeave(NodeEvent{Type: NodeLeft, Node: node, Time: now, Expired: expired, AliveNodes: len(alive)})
+		}
+	}
+}
+
+// pruneNodes 从节点列表中删除早已下线的节点
+// 节点标识默认包含进程号，每次重启都会变化，异常退出的节点不会自己删除记录，不清理时节点列表会无限增长
+func (dtm *DistributedTaskManager) pruneNodes(ctx context.Context, nodes []string) {
+	if len(nodes) == 0 {
+		return
+	}
+	if err := dtm.redisClient.HDel(ctx, dtm.key("nodes"), nodes...).Err(); err != nil {
+		dtm.log.Error("Failed to prune stale nodes: ", err)
+		return
+	}
+	dtm.log.Info("Pruned stale nodes: ", strings.Join(nodes, ", "))
+}
+
+// stopHeartbeat 停止节点心跳并从节点列表中移除当前节点
+func (dtm *DistributedTaskManager) stopHeartbeat() {
+	if dtm.heartbeatDone == nil {
+		return
+	}
+	<-dtm.heartbeatDone
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	_, err := dtm.redisClient.Pipelined(ctx, func(pipe goredislib.Pipeliner) error {
+		pipe.HDel(ctx, dtm.key("nodes"), dtm.nodeID)
+		pipe.Del(ctx, dtm.key("nodes", dtm.nodeID))
+		return nil
+	})
+	if err != nil {
+		dtm.log.Error("Failed to remove node from registry: ", err)
+	}
+}
+
+// Nodes 列出集群中记录过心跳的节点，按节点标识排序；异常退出的节点保留记录约10分钟，Alive 为 false
+func (c *Controller) Nodes(ctx context.Context) ([]NodeInfo, error) {
+	items, err := c.client.HGetAll(ctx, namespacedKey(c.namespace, "nodes")).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %v", err)
+	}
+
+	nodes := make([]NodeInfo, 0, len(items))
+	for node, v := range items {
+		ms, _ := strconv.ParseInt(v, 10, 64)
+		nodes = append(nodes, NodeInfo{Node: node, LastSeen: time.UnixMilli(ms)})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Node < nodes[j].Node })
+	if len(nodes) == 0 {
+		return nodes, nil
+	}
+
+	pipe := c.client.Pipeline()
+	exists := make([]*goredislib.IntCmd, len(nodes))
+	for i, n := range nodes {
+		exists[i] = pipe.Exists(ctx, namespacedKey(c.namespace, "nodes", n.Node))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %v", err)
+	}
+	for i := range nodes {
+		nodes[i].Alive = exists[i].Val() > 0
+	}
+	return nodes, nil
+}
+
+// nodeAlive 节点的存活键是否尚未过期
+func (c *Controller) nodeAlive(ctx context.Context, node string) (bool, error) {
+	n, err := c.client.Exists(ctx, namespacedKey(c.namespace, "nodes", node)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check node %s: %v", node, err)
+	}
+	return n > 0, nil
+}
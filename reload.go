@@ -0,0 +1,302 @@
+package redCorn
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce 文件变更后等待的时间，合并编辑器保存、ConfigMap 更新等产生的一连串事件
+const reloadDebounce = 200 * time.Millisecond
+
+// ConfigWatcher 监听调度配置文件，文件变更或进程收到 SIGHUP 时重新加载，
+// 对比新旧配置后在运行中的任务管理器上添加、更新、移除任务，无需重启
+type ConfigWatcher struct {
+	dtm      *DistributedTaskManager
+	path     string
+	handlers map[string]func(ctx context.Context) error
+
+	mu      sync.Mutex
+	applied map[string]TaskConfig // 当前生效的配置中启用的任务
+
+	watcher  *fsnotify.Watcher
+	signals  chan os.Signal
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// WatchScheduleConfig 从配置文件注册任务并监听变更，取代 LoadSchedulerFromFile + AddScheduler
+// 文件变更（包括编辑器替换文件和 Kubernetes ConfigMap 的更新）或收到 SIGHUP 时重新加载，
+// 只修改配置文件中的任务，代码中添加的任务和动态任务不受影响；逐个任务记录添加、移除和变化的字段
+// 新的配置无效时保留当前配置并记录错误；首次加载失败时返回错误
+//
+//	watcher, err := dtm.WatchScheduleConfig("tasks.yaml", handlers)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer watcher.Close()
+func (dtm *DistributedTaskManager) WatchScheduleConfig(path string, handlers map[string]func(ctx context.Context) error) (*ConfigWatcher, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch schedule config: %v", err)
+	}
+	w := &ConfigWatcher{
+		dtm:      dtm,
+		path:     path,
+		handlers: handlers,
+		applied:  make(map[string]TaskConfig),
+		signals:  make(chan os.Signal, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	// 先建立监听再首次加载，监听失败时不会留下已注册的任务，加载期间的变更也不会丢失；
+	// 监听所在目录而不是文件本身，文件被重命名替换后仍能收到事件
+	w.watcher, err = fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch schedule config: %v", err)
+	}
+	if err := w.watcher.Add(filepath.Dir(path)); err != nil {
+		w.watcher.Close()
+		return nil, fmt.Errorf("failed to watch schedule config: %v", err)
+	}
+	if err := w.Reload(); err != nil {
+		w.watcher.Close()
+		return nil, err
+	}
+	signal.Notify(w.signals, syscall.SIGHUP)
+
+	go w.run()
+	return w, nil
+}
+
+// Close 停止监听，已经生效的任务保持不变；任务管理器停止时自动停止监听
+func (w *ConfigWatcher) Close() error {
+	w.stopOnce.Do(func() { close(w.stop) })
+	<-w.done
+	return nil
+}
+
+// run 处理文件事件和 SIGHUP，直到 Close() 或任务管理器停止
+func (w *ConfigWatcher) run() {
+	defer close(w.done)
+	defer w.watcher.Close()
+	defer signal.Stop(w.signals)
+
+	// debounce 在最后一个相关事件之后触发重新加载
+	debounce := time.NewTimer(reloadDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-w.dtm.ctx.Done():
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if w.relevant(event) {
+				debounce.Reset(reloadDebounce)
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.dtm.log.Error("Failed to watch schedule config: ", err)
+		case <-w.signals:
+			w.dtm.log.Info("Received SIGHUP, reloading schedule config ", w.path)
+			w.reload()
+		case <-debounce.C:
+			w.reload()
+		}
+	}
+}
+
+// relevant 事件是否可能改变配置文件的内容：配置文件本身，或 Kubernetes 挂载 ConfigMap 时切换的 ..data 链接
+func (w *ConfigWatcher) relevant(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	name := filepath.Clean(event.Name)
+	return name == w.path || filepath.Base(name) == "..data"
+}
+
+// reload 重新加载配置，失败时记录错误并保留当前配置
+func (w *ConfigWatcher) reload() {
+	if err := w.Reload(); err != nil {
+		w.dtm.log.Error("Failed to reload schedule config, keeping current schedule: ", err)
+	}
+}
+
+// Reload 立即重新加载配置文件并应用变更，所有任务校验通过后才会生效，任一任务无效时不做任何修改
+func (w *ConfigWatcher) Reload() error {
+	cfg, err := LoadScheduleConfig(w.path)
+	if err != nil {
+		return err
+	}
+	scheduler, err := cfg.Scheduler(w.handlers)
+	if err != nil {
+		return err
+	}
+	enabled := make(map[string]TaskConfig, len(cfg.Tasks))
+	for name, tc := range cfg.Tasks {
+		if !tc.Disabled {
+			enabled[name] = tc
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var changes configChanges
+	for name, tc := range enabled {
+		old, exists := w.applied[name]
+		switch {
+		case !exists:
+			changes.added = append(changes.added, name)
+		case !reflect.DeepEqual(old, tc):
+			changes.updated = append(changes.updated, name)
+		}
+	}
+	for name := range w.applied {
+		if _, exists := enabled[name]; !exists {
+			changes.removed = append(changes.removed, name)
+		}
+	}
+	if changes.empty() {
+		w.dtm.log.Debug("Schedule config unchanged: ", w.path)
+		return nil
+	}
+	changes.sort()
+
+	if err := w.dtm.applyConfigChanges(scheduler.GetAll(), changes); err != nil {
+		return fmt.Errorf("failed to apply schedule config %s: %v", w.path, err)
+	}
+
+	for _, name := range changes.added {
+		w.dtm.log.Info("Schedule config: added task ", name, ", schedule: ", enabled[name].Cron)
+	}
+	for _, name := range changes.updated {
+		w.dtm.log.Info("Schedule config: updated task ", name, ": ", strings.Join(diffTaskConfig(w.applied[name], enabled[name]), ", "))
+	}
+	for _, name := range changes.removed {
+		w.dtm.log.Info("Schedule config: removed task ", name)
+	}
+	w.dtm.log.Info("Reloaded schedule config ", w.path, ": added ", len(changes.added),
+		", updated ", len(changes.updated), ", removed ", len(changes.removed), " tasks")
+	w.applied = enabled
+	return nil
+}
+
+// configChanges 新旧配置之间的任务变更
+type configChanges struct {
+	added, updated, removed []string
+}
+
+func (c configChanges) empty() bool {
+	return len(c.added) == 0 && len(c.updated) == 0 && len(c.removed) == 0
+}
+
+// sort 按名称排序，日志顺序稳定
+func (c configChanges) sort() {
+	sort.Strings(c.added)
+	sort.Strings(c.updated)
+	sort.Strings(c.removed)
+}
+
+// applyConfigChanges 在任务管理器上应用配置变更，先创建和校验所有任务，全部通过后再修改
+// 新增的任务与代码中添加的任务重名时返回错误；要更新的任务已被移除时重新添加，要移除的任务已不存在时忽略
+func (dtm *DistributedTaskManager) applyConfigChanges(schedules map[string]TaskSchedule, changes configChanges) error {
+	dtm.mu.Lock()
+	defer dtm.mu.Unlock()
+
+	if dtm.isStopping() {
+		return ErrManagerStopped
+	}
+
+	for _, name := range changes.added {
+		if _, exists := dtm.tasks[name]; exists {
+			return fmt.Errorf("task %s already exists", name)
+		}
+	}
+	names := append(append([]string{}, changes.added...), changes.updated...)
+	tasks := make([]*distributedTask, 0, len(names))
+	for _, name := range names {
+		schedule := schedules[name]
+		t, err := dtm.newDistributedTask(name, schedule.Cron, schedule.handler(), newTaskOptions(schedule.Options))
+		if err != nil {
+			return fmt.Errorf("task %s: %v", name, err)
+		}
+		tasks = append(tasks, t)
+	}
+
+	options := make(map[string]taskOptions, len(dtm.tasks)+len(tasks))
+	for name, t := range dtm.tasks {
+		options[name] = t.opts
+	}
+	for _, name := range changes.removed {
+		delete(options, name)
+	}
+	for _, t := range tasks {
+		options[t.name] = t.opts
+	}
+	if err := checkDependencyCycle(options); err != nil {
+		return err
+	}
+
+	for _, name := range changes.removed {
+		if old, exists := dtm.tasks[name]; exists {
+			dtm.uninstallTask(old)
+		}
+	}
+	for _, t := range tasks {
+		if old, exists := dtm.tasks[t.name]; exists {
+			dtm.replaceTask(old, t)
+		} else {
+			dtm.addTask(t)
+		}
+	}
+	return nil
+}
+
+// diffTaskConfig 列出两份任务配置中变化的字段，如 `cron "0 * * * * *" -> "0 */5 * * * *"`
+func diffTaskConfig(old, new TaskConfig) []string {
+	var diffs []string
+	ov, nv := reflect.ValueOf(old), reflect.ValueOf(new)
+	typ := ov.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		a, b := ov.Field(i).Interface(), nv.Field(i).Interface()
+		if reflect.DeepEqual(a, b) {
+			continue
+		}
+		field, _, _ := strings.Cut(typ.Field(i).Tag.Get("yaml"), ",")
+		diffs = append(diffs, fmt.Sprintf("%s %s -> %s", field, formatConfigValue(a), formatConfigValue(b)))
+	}
+	return diffs
+}
+
+// formatConfigValue 格式化配置字段的值，字符串加引号以区分空值
+func formatConfigValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []string:
+		return "[" + strings.Join(v, ", ") + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,91 @@
+package redCorn
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy 任务重试策略，仅对返回 error 的任务生效
+type RetryPolicy struct {
+	MaxRetries     int           // 最大重试次数，0表示不重试
+	InitialBackoff time.Duration // 首次重试前的等待时间，默认1秒
+	MaxBackoff     time.Duration // 等待时间上限，0表示不限制
+	Multiplier     float64       // 退避倍数，默认2
+	Jitter         float64       // 随机抖动比例(0~1)，实际等待时间在 ±Jitter 范围内浮动
+
+	OnRetry   func(taskName string, attempt int, err error) // 每次重试前回调，可选
+	OnFailure func(taskName string, err error)              // 重试耗尽后仍失败时回调，可选
+}
+
+// backoff 计算第 attempt 次重试前的等待时间
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	if delay <= 0 {
+		delay = time.Second
+	}
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	d := float64(delay)
+	for i := 1; i < attempt; i++ {
+		d *= multiplier
+		if p.MaxBackoff > 0 && d >= float64(p.MaxBackoff) {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+// runWithRetry 执行任务，失败时按重试策略重试
+// 持有分布式锁时，每次重试前都会续期锁，确保重试期间锁仍由当前节点持有
+func (dtm *DistributedTaskManager) runWithRetry(ctx context.Context, t *distributedTask, mutex *taskMutex) error {
+	policy := t.opts.retry
+	runID := RunIDFromContext(ctx)
+
+	err := dtm.callTask(ctx, t)
+	for attempt := 1; err != nil && attempt <= policy.MaxRetries; attempt++ {
+		delay := policy.backoff(attempt)
+		dtm.logRun(slog.LevelWarn, t.name, runID, "Attempt failed, retrying", slog.Int("attempt", attempt), slog.Duration("delay", delay), slog.Any("error", err))
+		if policy.OnRetry != nil {
+			policy.OnRetry(t.name, attempt, err)
+		}
+
+		timer := dtm.clock.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			err = fmt.Errorf("retry aborted: %w", ctx.Err())
+		case <-timer.Chan():
+			if mutex == nil {
+				err = dtm.callTask(ctx, t)
+				continue
+			}
+			if ok, extendErr := mutex.extend(ctx); !ok || extendErr != nil {
+				err = fmt.Errorf("lock lost before retry %d: %v", attempt, extendErr)
+			} else {
+				err = dtm.callTask(ctx, t)
+				continue
+			}
+		}
+		break
+	}
+
+	if err != nil && policy.MaxRetries > 0 {
+		dtm.logRun(slog.LevelError, t.name, runID, "Giving up after retries", slog.Any("error", err))
+		if policy.OnFailure != nil {
+			policy.OnFailure(t.name, err)
+		}
+	}
+	return err
+}
@@ -0,0 +1,139 @@
+package redCorn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// NodeRole 节点角色，用于把计算调度和执行任务拆分到不同的节点上，独立扩展执行能力
+type NodeRole string
+
+const (
+	RoleAll       NodeRole = "all"       // 计算调度并执行任务（默认）
+	RoleScheduler NodeRole = "scheduler" // 只计算调度，把到期的执行加入任务的工作队列，由工作节点执行
+	RoleWorker    NodeRole = "worker"    // 不计算调度，只从工作队列中取出调度节点加入的执行
+)
+
+// validate 检查节点角色
+func (r NodeRole) validate() error {
+	switch r {
+	case "", RoleAll, RoleScheduler, RoleWorker:
+		return nil
+	}
+	return fmt.Errorf("invalid node role %q", r)
+}
+
+// dispatchPayload 调度节点加入工作队列的一次执行
+type dispatchPayload struct {
+	Manual bool `json:"manual,omitempty"`
+}
+
+// taskQueueKey 调度节点把任务 name 的执行加入的工作队列
+func (c *Controller) taskQueueKey(name string) string {
+	return namespacedKey(c.namespace, "dispatch", name)
+}
+
+// isWorker 当前节点是否为工作节点
+func (dtm *DistributedTaskManager) isWorker() bool {
+	return dtm.cfg.Role == RoleWorker
+}
+
+// enqueueExecution 调度节点不执行任务，把本次执行加入任务的工作队列，由某一个工作节点取出执行
+// 定时调度先在集群中认领调度时刻，多个调度节点同时触发时只有一个加入队列
+func (dtm *DistributedTaskManager) enqueueExecution(t *distributedTask, manual bool, tick time.Time) {
+	if t.paused.Load() && !manual {
+		dtm.logRun(slog.LevelDebug, t.name, "", "Paused, skipping execution")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+	runID := newRunID()
+	rec := &ExecutionRecord{RunID: runID, Task: t.name, Node: dtm.nodeID}
+	if !tick.IsZero() && !dtm.claimTick(ctx, t, tick, rec) {
+		return
+	}
+	if !tick.IsZero() && len(t.opts.dependsOn) > 0 && !dtm.checkDependencies(ctx, t, tick, rec) {
+		return
+	}
+
+	payload, err := json.Marshal(dispatchPayload{Manual: manual})
+	if err != nil {
+		dtm.logRun(slog.LevelError, t.name, runID, "Failed to encode execution", slog.Any("error", err))
+		return
+	}
+	job := Job{ID: runID, Name: t.name, Payload: payload, EnqueuedBy: dtm.nodeID}
+	if _, err := dtm.controller.pushJob(ctx, dtm.controller.taskQueueKey(t.name), job); err != nil {
+		dtm.logRun(slog.LevelError, t.name, runID, "Failed to enqueue execution", slog.Any("error", err))
+		return
+	}
+	dtm.logRun(slog.LevelInfo, t.name, runID, "Execution enqueued for workers")
+}
+
+// startTaskQueues 工作节点为所有已注册的任务启动工作队列消费，之后添加的任务在 installTask 中启动
+func (dtm *DistributedTaskManager) startTaskQueues() {
+	if !dtm.isWorker() {
+		return
+	}
+	dtm.mu.Lock()
+	defer dtm.mu.Unlock()
+	dtm.taskQueues = make(map[string]*workQueue, len(dtm.tasks))
+	for _, t := range dtm.tasks {
+		dtm.startTaskQueue(t)
+	}
+}
+
+// stopTaskQueues 停止所有任务的工作队列消费
+func (dtm *DistributedTaskManager) stopTaskQueues() {
+	dtm.mu.Lock()
+	defer dtm.mu.Unlock()
+	for _, q := range dtm.taskQueues {
+		close(q.stop)
+	}
+	dtm.taskQueues = nil
+}
+
+// startTaskQueue 开始消费任务的工作队列，已在消费或尚未启动时忽略，调用方需持有 dtm.mu
+func (dtm *DistributedTaskManager) startTaskQueue(t *distributedTask) {
+	if dtm.taskQueues == nil {
+		return
+	}
+	if _, exists := dtm.taskQueues[t.name]; exists {
+		return
+	}
+	q := &workQueue{name: t.name, key: dtm.controller.taskQueueKey(t.name), handler: dtm.runQueuedTask, stop: make(chan struct{})}
+	dtm.taskQueues[t.name] = q
+	dtm.startQueue(q)
+}
+
+// stopTaskQueue 停止消费任务的工作队列，调用方需持有 dtm.mu
+func (dtm *DistributedTaskManager) stopTaskQueue(name string) {
+	if q, exists := dtm.taskQueues[name]; exists {
+		close(q.stop)
+		delete(dtm.taskQueues, name)
+	}
+}
+
+// runQueuedTask 工作节点执行调度节点加入队列的一次执行，与本地调度的执行一样获取锁并写入执行历史
+// 任务执行失败不会重新投递（重试使用 WithRetry），只有未能开始执行时才留待重试
+func (dtm *DistributedTaskManager) runQueuedTask(ctx context.Context, job Job) error {
+	dtm.mu.RLock()
+	t, exists := dtm.tasks[job.Name]
+	dtm.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, job.Name)
+	}
+	if dtm.isStopping() {
+		return ErrManagerStopped
+	}
+
+	var p dispatchPayload
+	if err := json.Unmarshal(job.Payload, &p); err != nil {
+		dtm.logRun(slog.LevelWarn, job.Name, job.ID, "Ignoring malformed execution payload", slog.Any("error", err))
+	}
+	dtm.executeDistributedTask(t, p.Manual, time.Time{})
+	return nil
+}
@@ -0,0 +1,51 @@
+package redCorn
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+)
+
+// crockford ULID 使用的 Crockford Base32 字母表
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newRunID 生成一次执行的唯一标识，格式为 ULID：48位毫秒时间戳 + 80位随机数，按时间有序
+func newRunID() string {
+	var id [16]byte
+	binary.BigEndian.PutUint64(id[:8], uint64(time.Now().UnixMilli())<<16)
+	_, _ = rand.Read(id[6:])
+
+	// 128位按5位一组编码为26个字符，首字符只包含最高的3位
+	var out [26]byte
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// runIDKey 上下文中保存执行标识的键
+type runIDKey struct{}
+
+// withRunID 将执行标识写入上下文
+func withRunID(ctx context.Context, runID string) context.Context {
+	return context.WithValue(ctx, runIDKey{}, runID)
+}
+
+// RunIDFromContext 从任务上下文中取出本次执行的唯一标识，可用于在任务自身的日志中关联同一次执行
+func RunIDFromContext(ctx context.Context) string {
+	runID, _ := ctx.Value(runIDKey{}).(string)
+	return runID
+}
+
+// runLabel 日志中标识一次执行，例如 "report [01HZY3...]"
+func runLabel(name, runID string) string {
+	if runID == "" {
+		return name
+	}
+	return name + " [" + runID + "]"
+}
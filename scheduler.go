@@ -1,9 +1,37 @@
 package redCorn
 
+import (
+	"context"
+	"fmt"
+)
+
 // TaskSchedule 任务调度定义
 type TaskSchedule struct {
-	Task func()
-	Cron string
+	Task    func()
+	TaskCtx func(ctx context.Context)       // 可感知上下文的任务，优先于 Task
+	TaskE   func(ctx context.Context) error // 返回错误的任务，优先于 TaskCtx 和 Task
+	Cron    string
+	Options []TaskOption
+}
+
+// handler 返回统一的任务函数
+func (s TaskSchedule) handler() func(ctx context.Context) error {
+	switch {
+	case s.TaskE != nil:
+		return s.TaskE
+	case s.TaskCtx != nil:
+		task := s.TaskCtx
+		return func(ctx context.Context) error {
+			task(ctx)
+			return nil
+		}
+	default:
+		task := s.Task
+		return func(context.Context) error {
+			task()
+			return nil
+		}
+	}
 }
 
 // TaskScheduler 任务调度器 - 集中管理任务和定时信息
@@ -18,12 +46,79 @@ func NewTaskScheduler() *TaskScheduler {
 	}
 }
 
-// Register 注册任务和定时信息
-func (ts *TaskScheduler) Register(name string, cron string, task func()) {
-	ts.tasks[name] = TaskSchedule{
-		Task: task,
-		Cron: cron,
+// Register 注册任务和定时信息，Cron 表达式无效时返回错误
+func (ts *TaskScheduler) Register(name string, cron string, task func(), opts ...TaskOption) error {
+	return ts.register(name, TaskSchedule{
+		Task:    task,
+		Cron:    cron,
+		Options: opts,
+	})
+}
+
+// RegisterCtx 注册可感知上下文的任务和定时信息，Cron 表达式无效时返回错误
+func (ts *TaskScheduler) RegisterCtx(name string, cron string, task func(ctx context.Context), opts ...TaskOption) error {
+	return ts.register(name, TaskSchedule{
+		TaskCtx: task,
+		Cron:    cron,
+		Options: opts,
+	})
+}
+
+// RegisterE 注册返回错误的任务和定时信息，可通过 WithRetry 配置失败重试，Cron 表达式无效时返回错误
+func (ts *TaskScheduler) RegisterE(name string, cron string, task func(ctx context.Context) error, opts ...TaskOption) error {
+	return ts.register(name, TaskSchedule{
+		TaskE:   task,
+		Cron:    cron,
+		Options: opts,
+	})
+}
+
+// register 校验 Cron 表达式后保存任务
+// 调度器不知道任务管理器的 Cfg.CronFormat，任务未指定格式时5位和6位表达式均可通过，位数由 AddScheduler 最终校验
+func (ts *TaskScheduler) register(name string, schedule TaskSchedule) error {
+	parser, err := newTaskOptions(schedule.Options).specParser(CronFormatOptionalSeconds)
+	if err != nil {
+		return fmt.Errorf("failed to register task %s: %v", name, err)
+	}
+	if _, err := parser.Parse(schedule.Cron); err != nil {
+		return fmt.Errorf("failed to register task %s: invalid cron spec %q: %v", name, schedule.Cron, err)
+	}
+	if err := ts.checkCycle(name, schedule); err != nil {
+		return fmt.Errorf("failed to register task %s: %v", name, err)
+	}
+	ts.tasks[name] = schedule
+	return nil
+}
+
+// appendOption 为已注册的任务追加选项，追加后存在循环依赖时返回错误且不做修改
+func (ts *TaskScheduler) appendOption(name string, opt TaskOption) error {
+	schedule, exists := ts.tasks[name]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+	schedule.Options = append(append([]TaskOption(nil), schedule.Options...), opt)
+	if err := ts.checkCycle(name, schedule); err != nil {
+		return fmt.Errorf("failed to update task %s: %v", name, err)
+	}
+	ts.tasks[name] = schedule
+	return nil
+}
+
+// checkCycle 检查以 schedule 替换任务 name 之后调度器中的依赖关系
+func (ts *TaskScheduler) checkCycle(name string, schedule TaskSchedule) error {
+	options := make(map[string]taskOptions, len(ts.tasks)+1)
+	for n, s := range ts.tasks {
+		options[n] = newTaskOptions(s.Options)
+	}
+	o := newTaskOptions(schedule.Options)
+	if err := o.validateThen(name); err != nil {
+		return err
+	}
+	if err := o.validateDependsOn(name); err != nil {
+		return err
 	}
+	options[name] = o
+	return checkDependencyCycle(options)
 }
 
 // Get 获取任务调度信息
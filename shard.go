@@ -0,0 +1,81 @@
+package redCorn
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// shardFailoverDelay 分片不是分配给当前节点时，等待负责的节点先获取分片锁的时间
+const shardFailoverDelay = time.Second
+
+// ShardName 分片任务中第 shard 个分片的任务名 "<name>:<shard>"，用于 TriggerTask、RemoveTask 等按任务名操作的方法
+func ShardName(name string, shard int) string {
+	return name + ":" + strconv.Itoa(shard)
+}
+
+// withShard 标记任务为分片任务的第 shard 个分片
+func withShard(shard, total int) TaskOption {
+	return func(o *taskOptions) {
+		o.shard = shard
+		o.shards = total
+	}
+}
+
+// RegisterSharded 注册分片任务：每个调度时刻把 [0, shards) 个分片分散到集群的各个节点上并行执行，
+// 每个分片是一个名为 "<name>:<分片>" 的任务，有各自的锁、执行历史和统计
+func (ts *TaskScheduler) RegisterSharded(name string, cron string, shards int, task func(shard, total int), opts ...TaskOption) error {
+	if shards < 1 {
+		return fmt.Errorf("failed to register task %s: invalid shard count %d", name, shards)
+	}
+	for i := 0; i < shards; i++ {
+		shard := i
+		shardOpts := make([]TaskOption, 0, len(opts)+1)
+		shardOpts = append(shardOpts, opts...)
+		err := ts.register(ShardName(name, shard), TaskSchedule{
+			Task:    func() { task(shard, shards) },
+			Cron:    cron,
+			Options: append(shardOpts, withShard(shard, shards)),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterSharded 在分组内注册分片任务
+func (g *TaskGroup) RegisterSharded(name string, cron string, shards int, task func(shard, total int), opts ...TaskOption) error {
+	return g.scheduler.RegisterSharded(name, cron, shards, task, g.options(opts)...)
+}
+
+// AddShardedTask 添加分片任务，见 TaskScheduler.RegisterSharded；任一分片无法添加时不添加任何分片
+func (dtm *DistributedTaskManager) AddShardedTask(name, cron string, shards int, task func(shard, total int), opts ...TaskOption) error {
+	scheduler := NewTaskScheduler()
+	if err := scheduler.RegisterSharded(name, cron, shards, task, opts...); err != nil {
+		return err
+	}
+	return dtm.AddScheduler(scheduler)
+}
+
+// waitShard 分片按一致性哈希分配给在线节点，不是分配给当前节点的分片先等待 1 秒（不超过调度间隔的 1/4）再争抢锁，
+// 让各节点优先获取分配给自己的分片；负责的节点下线时其他节点仍可在等待后接手
+// SchedulingHash 模式下未分配的节点已直接跳过，无需等待
+func (dtm *DistributedTaskManager) waitShard(t *distributedTask, tick time.Time, rec *ExecutionRecord) bool {
+	if t.opts.shards == 0 || dtm.cfg.SchedulingMode == SchedulingHash {
+		return true
+	}
+	owner, ok := dtm.assignedToSelf(t.name)
+	if ok {
+		return true
+	}
+
+	delay := shardFailoverDelay
+	if interval := t.schedule.Next(tick).Sub(tick); interval > 0 && interval/4 < delay {
+		delay = interval / 4
+	}
+	dtm.logRun(slog.LevelDebug, t.name, rec.RunID, "Shard assigned to another node, delaying lock acquisition",
+		slog.String("assigned_node", owner), slog.Duration("delay", delay))
+	return dtm.waitDelay(delay, rec)
+}
@@ -0,0 +1,96 @@
+package redCorn
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// defaultShutdownTimeout 停止时等待正在执行的任务完成的默认时长
+	defaultShutdownTimeout = 30 * time.Second
+	// shutdownCancelGrace 取消任务上下文后等待任务退出的时长
+	shutdownCancelGrace = 5 * time.Second
+)
+
+// shutdownTimeout 停止时等待正在执行的任务完成的时长
+func (dtm *DistributedTaskManager) shutdownTimeout() time.Duration {
+	switch {
+	case dtm.cfg.ShutdownTimeout > 0:
+		return dtm.cfg.ShutdownTimeout
+	case dtm.cfg.ShutdownTimeout < 0:
+		return 0
+	}
+	return defaultShutdownTimeout
+}
+
+// isStopping 管理器是否已开始停止
+func (dtm *DistributedTaskManager) isStopping() bool {
+	select {
+	case <-dtm.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// beginStop 标记管理器开始停止，之后不再开始新的执行，管理器已在停止时返回 false
+func (dtm *DistributedTaskManager) beginStop() bool {
+	dtm.runMu.Lock()
+	defer dtm.runMu.Unlock()
+	if dtm.isStopping() {
+		return false
+	}
+	close(dtm.stopping)
+	return true
+}
+
+// beginExecution 登记一次执行，管理器已开始停止时返回 false，登记成功后需调用 endExecution
+func (dtm *DistributedTaskManager) beginExecution() bool {
+	dtm.runMu.Lock()
+	defer dtm.runMu.Unlock()
+	if dtm.isStopping() {
+		return false
+	}
+	dtm.running.Add(1)
+	dtm.executing.Add(1)
+	return true
+}
+
+// joinExecution 在已登记的执行期间登记一次由它派生的执行，不受停止影响，调用方需已通过 beginExecution 登记
+func (dtm *DistributedTaskManager) joinExecution() {
+	dtm.running.Add(1)
+	dtm.executing.Add(1)
+}
+
+// endExecution 注销一次执行
+func (dtm *DistributedTaskManager) endExecution() {
+	dtm.executing.Add(-1)
+	dtm.running.Done()
+}
+
+// waitExecutions 等待正在执行的任务完成，ctx 结束时取消任务上下文，短暂等待后返回 ctx.Err()
+func (dtm *DistributedTaskManager) waitExecutions(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		dtm.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	dtm.log.Warn("Timed out waiting for running tasks, cancelling them")
+	dtm.cancel()
+
+	timer := time.NewTimer(shutdownCancelGrace)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		dtm.log.Warn("Running tasks did not exit within ", shutdownCancelGrace, " after cancellation")
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,66 @@
+package redCorn
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// maxSimulatedRuns Simulate 返回的调度时刻数量上限，避免过大的时间窗口耗尽内存
+const maxSimulatedRuns = 100000
+
+// ErrTooManySimulatedRuns 时间窗口内的调度时刻超过上限
+var ErrTooManySimulatedRuns = errors.New("too many simulated runs")
+
+// SimulatedRun Simulate 计算出的一次调度
+type SimulatedRun struct {
+	Task   string    `json:"task"`
+	Group  string    `json:"group,omitempty"`
+	FireAt time.Time `json:"fire_at"`
+	Paused bool      `json:"paused,omitempty"` // 任务当前处于暂停状态，不会实际执行
+}
+
+// Simulate 列出 from 到 to 之间（包括两端）所有任务的调度时刻，按时间排序，不执行任何任务，也不访问 Redis
+// 已应用时区、工作日历、开始和结束时间；随机延迟、最大执行次数、执行条件等执行时才确定的因素不计入
+// 可以用新的配置创建一个使用 LockBackendMemory（需导入 memorybackend）的任务管理器，注册任务后调用 Simulate，在部署前检查调度变更的影响；
+// 调度时刻超过100000个时返回 ErrTooManySimulatedRuns
+func (dtm *DistributedTaskManager) Simulate(from, to time.Time) ([]SimulatedRun, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid simulation window: end %s is before start %s", to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+
+	dtm.mu.RLock()
+	tasks := make([]*distributedTask, 0, len(dtm.tasks))
+	for _, t := range dtm.tasks {
+		tasks = append(tasks, t)
+	}
+	dtm.mu.RUnlock()
+
+	var runs []SimulatedRun
+	for _, t := range tasks {
+		paused := t.paused.Load()
+		// Next 返回严格晚于参数的时刻，从 from 之前开始计算以包含 from 本身
+		next := from.Add(-time.Nanosecond)
+		for {
+			next = t.schedule.Next(next)
+			// 不会再触发的调度返回零值
+			if next.IsZero() || next.After(to) {
+				break
+			}
+			if len(runs) >= maxSimulatedRuns {
+				return nil, fmt.Errorf("%w: more than %d runs between %s and %s, use a shorter window",
+					ErrTooManySimulatedRuns, maxSimulatedRuns, from.Format(time.RFC3339), to.Format(time.RFC3339))
+			}
+			runs = append(runs, SimulatedRun{Task: t.name, Group: t.opts.group, FireAt: next, Paused: paused})
+		}
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].FireAt.Equal(runs[j].FireAt) {
+			return runs[i].FireAt.Before(runs[j].FireAt)
+		}
+		return runs[i].Task < runs[j].Task
+	})
+	return runs, nil
+}
@@ -0,0 +1,144 @@
+package redCorn
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// SlogLogger 基于 log/slog 的日志器，是未配置 Cfg.Logger 时的默认日志器
+// 任务执行相关的日志以 task、node、run_id、duration 等键值对字段输出
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger 基于 slog.Logger 创建日志器，logger 为 nil 时使用 slog.Default()
+func NewSlogLogger(logger *slog.Logger) *SlogLogger {
+	return &SlogLogger{logger: logger}
+}
+
+// slog 返回实际使用的 slog.Logger，未指定时每次取 slog.Default()，以便 slog.SetDefault 随时生效
+func (l *SlogLogger) slog() *slog.Logger {
+	if l.logger != nil {
+		return l.logger
+	}
+	return slog.Default()
+}
+
+// Debug 调试日志
+func (l *SlogLogger) Debug(args ...interface{}) {
+	l.slog().Debug(fmt.Sprint(args...))
+}
+
+// Info 信息日志
+func (l *SlogLogger) Info(args ...interface{}) {
+	l.slog().Info(fmt.Sprint(args...))
+}
+
+// Warn 警告日志
+func (l *SlogLogger) Warn(args ...interface{}) {
+	l.slog().Warn(fmt.Sprint(args...))
+}
+
+// Error 错误日志
+func (l *SlogLogger) Error(args ...interface{}) {
+	l.slog().Error(fmt.Sprint(args...))
+}
+
+// Fatal 致命错误日志
+func (l *SlogLogger) Fatal(args ...interface{}) {
+	l.slog().Error(fmt.Sprint(args...))
+	os.Exit(1)
+}
+
+// LogAttrs 输出带键值对字段的日志
+func (l *SlogLogger) LogAttrs(level slog.Level, msg string, attrs ...slog.Attr) {
+	l.slog().LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+// loggerOptions 默认日志器配置
+type loggerOptions struct {
+	level  slog.Level
+	output io.Writer
+	prefix string
+	json   bool
+}
+
+// LoggerOption 默认日志器选项
+type LoggerOption func(*loggerOptions)
+
+// WithLogLevel 设置最低日志级别，默认 LogLevelInfo
+func WithLogLevel(level LogLevel) LoggerOption {
+	return func(o *loggerOptions) {
+		if l, err := level.slogLevel(); err == nil {
+			o.level = l
+		}
+	}
+}
+
+// WithLogOutput 设置日志输出，默认 os.Stdout
+func WithLogOutput(w io.Writer) LoggerOption {
+	return func(o *loggerOptions) {
+		o.output = w
+	}
+}
+
+// WithLogPrefix 设置每行日志的前缀，默认 "[RedCorn] "，JSON 格式下不生效
+func WithLogPrefix(prefix string) LoggerOption {
+	return func(o *loggerOptions) {
+		o.prefix = prefix
+	}
+}
+
+// WithJSONLog 以 JSON 格式输出日志，每行一个对象
+func WithJSONLog() LoggerOption {
+	return func(o *loggerOptions) {
+		o.json = true
+	}
+}
+
+// NewDefaultLogger 创建独立于 slog.Default() 的日志器，可配置最低级别、输出、前缀和 JSON 格式
+func NewDefaultLogger(opts ...LoggerOption) *SlogLogger {
+	o := loggerOptions{
+		level:  slog.LevelInfo,
+		output: os.Stdout,
+		prefix: "[RedCorn] ",
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: o.level}
+	var handler slog.Handler
+	if o.json {
+		handler = slog.NewJSONHandler(o.output, handlerOpts)
+	} else {
+		handler = slog.NewTextHandler(&prefixWriter{w: o.output, prefix: []byte(o.prefix)}, handlerOpts)
+	}
+	return NewSlogLogger(slog.New(handler))
+}
+
+// prefixWriter 在每条日志前写入前缀，slog 的 Handler 每条日志只调用一次 Write
+type prefixWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	prefix []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	if len(p.prefix) == 0 {
+		return p.w.Write(b)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	line := make([]byte, 0, len(p.prefix)+len(b))
+	line = append(append(line, p.prefix...), b...)
+	if _, err := p.w.Write(line); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
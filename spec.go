@@ -0,0 +1,145 @@
+package redCorn
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// CronFormat Cron 表达式格式
+type CronFormat string
+
+const (
+	CronFormatSeconds         CronFormat = "seconds"          // 6位表达式，第一位为秒（默认）
+	CronFormatStandard        CronFormat = "standard"         // 标准 crontab 的5位表达式
+	CronFormatOptionalSeconds CronFormat = "optional_seconds" // 5位或6位表达式均可，6位时第一位为秒
+)
+
+// Cron 表达式解析器，均支持 @every、@daily 等描述符
+var (
+	standardParser        = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	optionalSecondsParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+)
+
+// parser 返回格式对应的解析器
+func (f CronFormat) parser() (cron.ScheduleParser, error) {
+	switch f {
+	case "", CronFormatSeconds:
+		return specParser, nil
+	case CronFormatStandard:
+		return standardParser, nil
+	case CronFormatOptionalSeconds:
+		return optionalSecondsParser, nil
+	default:
+		return nil, fmt.Errorf("unknown cron format %q", f)
+	}
+}
+
+// WithCronFormat 覆盖任务 Cron 表达式的格式（默认使用 Cfg.CronFormat）
+func WithCronFormat(format CronFormat) TaskOption {
+	return func(o *taskOptions) {
+		o.cronFormat = format
+	}
+}
+
+// WithParser 使用自定义解析器解析任务的 Cron 表达式，优先于 WithCronFormat
+func WithParser(parser cron.ScheduleParser) TaskOption {
+	return func(o *taskOptions) {
+		o.parser = parser
+	}
+}
+
+// specParser 返回任务使用的解析器，任务未指定格式时使用 format
+func (o taskOptions) specParser(format CronFormat) (cron.ScheduleParser, error) {
+	if o.parser != nil {
+		return manualParser{o.parser}, nil
+	}
+	if o.cronFormat != "" {
+		format = o.cronFormat
+	}
+	parser, err := format.parser()
+	if err != nil {
+		return nil, err
+	}
+	return manualParser{parser}, nil
+}
+
+// ValidateSpec 校验 Cron 表达式，默认按6位格式校验，可通过 WithCronFormat 或 WithParser 指定格式
+func ValidateSpec(spec string, opts ...TaskOption) error {
+	parser, err := newTaskOptions(opts).specParser(CronFormatSeconds)
+	if err != nil {
+		return err
+	}
+	if _, err := parser.Parse(spec); err != nil {
+		return fmt.Errorf("invalid cron spec %q: %v", spec, err)
+	}
+	return nil
+}
+
+// WithTimezone 按指定时区解析任务的 Cron 表达式（默认使用 Cfg.Location）
+// 表达式中以 CRON_TZ= 或 TZ= 显式指定的时区优先
+func WithTimezone(loc *time.Location) TaskOption {
+	return func(o *taskOptions) {
+		o.location = loc
+	}
+}
+
+// parseSpec 按任务的格式配置解析 Cron 表达式并应用时区配置
+func (dtm *DistributedTaskManager) parseSpec(spec string, opts taskOptions) (cron.Schedule, error) {
+	parser, err := opts.specParser(dtm.cfg.CronFormat)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := parser.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := dtm.cfg.Location
+	if opts.location != nil {
+		loc = opts.location
+	}
+	if s, ok := schedule.(*cron.SpecSchedule); ok && loc != nil && !hasSpecTimezone(spec) {
+		s.Location = loc
+	}
+	if opts.calendar != nil {
+		schedule = calendarSchedule{Schedule: schedule, calendar: opts.calendar}
+	}
+	if !opts.startTime.IsZero() {
+		schedule = startSchedule{Schedule: schedule, start: opts.startTime}
+	}
+	if !opts.endTime.IsZero() {
+		schedule = endSchedule{Schedule: schedule, end: opts.endTime}
+	}
+	return schedule, nil
+}
+
+// hasSpecTimezone 表达式是否以 CRON_TZ= 或 TZ= 显式指定了时区
+func hasSpecTimezone(spec string) bool {
+	spec = strings.TrimSpace(spec)
+	return strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=")
+}
+
+// NextRuns 计算任务接下来 n 次的调度时间，不考虑暂停状态
+func (dtm *DistributedTaskManager) NextRuns(name string, n int) ([]time.Time, error) {
+	dtm.mu.RLock()
+	t, exists := dtm.tasks[name]
+	dtm.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+
+	runs := make([]time.Time, 0, n)
+	next := dtm.clock.Now()
+	for len(runs) < n {
+		next = t.schedule.Next(next)
+		// 不会再触发的调度返回零值
+		if next.IsZero() {
+			break
+		}
+		runs = append(runs, next)
+	}
+	return runs, nil
+}
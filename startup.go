@@ -0,0 +1,58 @@
+package redCorn
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// startupClaimTTL 启动执行认领记录的保留时间，在此期间启动的其他节点不再执行，覆盖滚动发布时各节点的启动时间差
+const startupClaimTTL = 5 * time.Minute
+
+// WithRunOnStart 任务管理器启动时立即执行一次任务，之后照常按表达式调度，适合预热缓存、一致性检查等任务
+// 集群中 5 分钟内启动的多个节点只有一个节点执行，执行同样受分布式锁保护；Start() 之后添加的任务在添加时执行
+func WithRunOnStart() TaskOption {
+	return func(o *taskOptions) {
+		o.runOnStart = true
+	}
+}
+
+// runOnStartTasks 启动调度时执行配置了 WithRunOnStart 的任务
+func (dtm *DistributedTaskManager) runOnStartTasks() {
+	dtm.mu.RLock()
+	tasks := make([]*distributedTask, 0, len(dtm.tasks))
+	for _, t := range dtm.tasks {
+		if t.opts.runOnStart {
+			tasks = append(tasks, t)
+		}
+	}
+	dtm.mu.RUnlock()
+
+	for _, t := range tasks {
+		go dtm.runOnStart(t)
+	}
+}
+
+// runOnStart 在集群中认领本次启动执行后执行任务，其他节点近期已经执行过时跳过
+// 一致性哈希模式下只由分配到任务的节点认领
+func (dtm *DistributedTaskManager) runOnStart(t *distributedTask) {
+	if !dtm.chainCandidate(t.name) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+	claimed, err := dtm.redisClient.SetNX(ctx, dtm.key("startup", t.name), dtm.nodeID, startupClaimTTL).Result()
+	if err != nil {
+		dtm.logRun(slog.LevelError, t.name, "", "Failed to claim run on start", slog.Any("error", err))
+		return
+	}
+	if !claimed {
+		dtm.logRun(slog.LevelDebug, t.name, "", "Already run on start by another node, skipping")
+		return
+	}
+
+	// 与定时调度的执行一样经过工作协程池，受并发上限、优先级和队列满时的策略约束
+	dtm.logRun(slog.LevelInfo, t.name, "", "Running on start")
+	dtm.dispatch(t, false, time.Time{})
+}
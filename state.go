@@ -0,0 +1,58 @@
+package redCorn
+
+// ManagerStatus 任务管理器的运行状态
+type ManagerStatus string
+
+const (
+	StatusNew      ManagerStatus = "new"      // 已创建，尚未 Start()
+	StatusRunning  ManagerStatus = "running"  // 运行中
+	StatusStopping ManagerStatus = "stopping" // 正在停止，等待执行中的任务完成
+	StatusStopped  ManagerStatus = "stopped"  // 已停止
+)
+
+// ManagerState 任务管理器状态快照，可用于健康检查
+type ManagerState struct {
+	Status    ManagerStatus `json:"status"`
+	NodeID    string        `json:"node_id"`
+	Leader    bool          `json:"leader"`    // 是否为领导者，仅在 SchedulingLeader 模式下有意义
+	Tasks     int           `json:"tasks"`     // 已注册的任务数
+	Paused    int           `json:"paused"`    // 处于暂停状态的任务数
+	Executing int           `json:"executing"` // 当前节点正在执行的任务数
+	Queued    int           `json:"queued"`    // 当前节点本地队列中等待执行的任务数，见 Cfg.MaxConcurrentTasks
+}
+
+// State 返回任务管理器当前的状态快照
+func (dtm *DistributedTaskManager) State() ManagerState {
+	state := ManagerState{
+		Status:    dtm.status(),
+		NodeID:    dtm.nodeID,
+		Leader:    dtm.leader.Load(),
+		Executing: int(dtm.executing.Load()),
+	}
+	if dtm.pool != nil {
+		state.Queued = dtm.pool.depth()
+	}
+
+	dtm.mu.RLock()
+	state.Tasks = len(dtm.tasks)
+	for _, t := range dtm.tasks {
+		if t.paused.Load() {
+			state.Paused++
+		}
+	}
+	dtm.mu.RUnlock()
+	return state
+}
+
+// status 任务管理器的运行状态
+func (dtm *DistributedTaskManager) status() ManagerStatus {
+	switch {
+	case dtm.stopped.Load():
+		return StatusStopped
+	case dtm.isStopping():
+		return StatusStopping
+	case dtm.started.Load():
+		return StatusRunning
+	}
+	return StatusNew
+}
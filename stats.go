@@ -0,0 +1,84 @@
+package redCorn
+
+import (
+	"sort"
+	"time"
+)
+
+// TaskStats 任务在当前节点上的执行统计，自任务管理器创建起在内存中累计
+type TaskStats struct {
+	Task          string        `json:"task"`
+	Runs          int64         `json:"runs"`           // 实际执行次数（成功与失败）
+	Failures      int64         `json:"failures"`       // 失败次数
+	Skips         int64         `json:"skips"`          // 跳过次数
+	LockContended int64         `json:"lock_contended"` // 因锁被其他执行持有而跳过的次数
+	LockErrors    int64         `json:"lock_errors"`    // 获取锁出错（如 Redis 不可用）而跳过的次数
+	AvgDuration   time.Duration `json:"avg_duration"`
+	MaxDuration   time.Duration `json:"max_duration"`
+	LastRun       time.Time     `json:"last_run"`             // 最近一次实际执行的开始时间
+	LastError     string        `json:"last_error,omitempty"` // 最近一次失败的错误信息
+	LastErrorAt   time.Time     `json:"last_error_at"`
+	Queued        int           `json:"queued"` // 当前在本地队列中等待执行的次数，见 Cfg.MaxConcurrentTasks
+
+	totalDuration time.Duration
+}
+
+// recordStats 累计一次执行的统计
+func (dtm *DistributedTaskManager) recordStats(rec *ExecutionRecord) {
+	dtm.statsMu.Lock()
+	defer dtm.statsMu.Unlock()
+
+	stats, exists := dtm.stats[rec.Task]
+	if !exists {
+		stats = &TaskStats{Task: rec.Task}
+		dtm.stats[rec.Task] = stats
+	}
+
+	if rec.Status == ExecutionSkipped {
+		stats.Skips++
+		switch rec.SkipReason {
+		case SkipReasonLockHeld:
+			stats.LockContended++
+		case SkipReasonLockError:
+			stats.LockErrors++
+		}
+		return
+	}
+	stats.Runs++
+	stats.LastRun = rec.StartedAt
+	stats.totalDuration += rec.Duration
+	stats.AvgDuration = stats.totalDuration / time.Duration(stats.Runs)
+	if rec.Duration > stats.MaxDuration {
+		stats.MaxDuration = rec.Duration
+	}
+	if rec.Status == ExecutionFailed {
+		stats.Failures++
+		stats.LastError = rec.Error
+		stats.LastErrorAt = rec.StartedAt
+	}
+}
+
+// Stats 返回当前节点上各任务的执行统计快照，按任务名称排序，无需外部监控系统即可查看
+// 只包含至少被调度过一次（或正在排队）的任务
+func (dtm *DistributedTaskManager) Stats() []TaskStats {
+	var queued map[string]int
+	if dtm.pool != nil {
+		queued = dtm.pool.queuedCounts()
+	}
+
+	dtm.statsMu.Lock()
+	stats := make([]TaskStats, 0, len(dtm.stats))
+	for _, s := range dtm.stats {
+		stats = append(stats, *s)
+		stats[len(stats)-1].Queued = queued[s.Task]
+	}
+	for name, n := range queued {
+		if _, exists := dtm.stats[name]; !exists {
+			stats = append(stats, TaskStats{Task: name, Queued: n})
+		}
+	}
+	dtm.statsMu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Task < stats[j].Task })
+	return stats
+}
@@ -0,0 +1,66 @@
+package redCorn
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// 任务状态哈希中的字段
+const (
+	statusFieldLastRun      = "last_run"
+	statusFieldLastNode     = "last_node"
+	statusFieldLastStatus   = "last_status"
+	statusFieldLastDuration = "last_duration"
+	statusFieldNextRun      = "next_run"
+)
+
+// TaskStatus 任务在整个集群中的运行状态
+type TaskStatus struct {
+	Task         string          `json:"task"`
+	LastRun      time.Time       `json:"last_run"`      // 最近一次执行的开始时间，从未执行过时为零值
+	LastNode     string          `json:"last_node"`     // 最近一次执行所在的节点
+	LastStatus   ExecutionStatus `json:"last_status"`   // 最近一次执行的结果
+	LastDuration time.Duration   `json:"last_duration"` // 最近一次执行的耗时
+	NextRun      time.Time       `json:"next_run"`      // 下一次调度时间
+}
+
+// statusKey 任务状态在 Redis 中的键
+func (dtm *DistributedTaskManager) statusKey(taskName string) string {
+	return dtm.key("status", taskName)
+}
+
+// recordNextRun 记录任务的下一次调度时间
+func (dtm *DistributedTaskManager) recordNextRun(t *distributedTask) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	next := t.schedule.Next(dtm.clock.Now())
+	if err := dtm.redisClient.HSet(ctx, dtm.statusKey(t.name), statusFieldNextRun, next.Format(time.RFC3339Nano)).Err(); err != nil {
+		dtm.logRun(slog.LevelError, t.name, "", "Failed to record next run", slog.Any("error", err))
+	}
+}
+
+// recordStatus 记录任务最近一次执行的信息和下一次调度时间
+func (dtm *DistributedTaskManager) recordStatus(t *distributedTask, rec *ExecutionRecord) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	next := t.schedule.Next(dtm.clock.Now())
+	err := dtm.redisClient.HSet(ctx, dtm.statusKey(t.name),
+		statusFieldLastRun, rec.StartedAt.Format(time.RFC3339Nano),
+		statusFieldLastNode, rec.Node,
+		statusFieldLastStatus, string(rec.Status),
+		statusFieldLastDuration, int64(rec.Duration),
+		statusFieldNextRun, next.Format(time.RFC3339Nano),
+	).Err()
+	if err != nil {
+		dtm.logRun(slog.LevelError, t.name, rec.RunID, "Failed to record status", slog.Any("error", err))
+	}
+}
+
+// GetTaskStatus 获取任务在整个集群中的最近执行时间和下一次调度时间
+// 任务不必在当前节点注册，只要集群中任意节点注册过即可查询
+func (dtm *DistributedTaskManager) GetTaskStatus(name string) (TaskStatus, error) {
+	return dtm.controller.Status(dtm.ctx, name)
+}
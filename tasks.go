@@ -0,0 +1,75 @@
+package redCorn
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// TaskEntry 当前节点上注册的任务信息
+type TaskEntry struct {
+	Name        string    `json:"name"`
+	Group       string    `json:"group,omitempty"`
+	Spec        string    `json:"spec"`
+	Description string    `json:"description,omitempty"` // 表达式的易读描述
+	Next        time.Time `json:"next"`                  // 下一次调度时间
+	Prev        time.Time `json:"prev"`                  // 当前节点上一次调度时间，从未调度时为零值
+	Paused      bool      `json:"paused"`
+	Lock        LockInfo  `json:"lock"` // 分布式锁的持有情况
+}
+
+// ListTasks 列出当前节点上注册的所有任务，按名称排序
+func (dtm *DistributedTaskManager) ListTasks() ([]TaskEntry, error) {
+	// 调度器运行时每次查询都会复制全部条目，一次性取出
+	cronEntries := make(map[cron.EntryID]cron.Entry)
+	for _, e := range dtm.cron.Entries() {
+		cronEntries[e.ID] = e
+	}
+
+	dtm.mu.RLock()
+	entries := make([]TaskEntry, 0, len(dtm.tasks))
+	for _, t := range dtm.tasks {
+		entry := cronEntries[t.entryID]
+		next := entry.Next
+		if next.IsZero() {
+			next = t.schedule.Next(dtm.clock.Now())
+		}
+		entries = append(entries, TaskEntry{
+			Name:        t.name,
+			Group:       t.opts.group,
+			Spec:        t.spec,
+			Description: t.description,
+			Next:        next,
+			Prev:        entry.Prev,
+			Paused:      t.paused.Load(),
+			Lock:        LockInfo{Task: t.name, Key: dtm.lockKey(t)},
+		})
+	}
+	dtm.mu.RUnlock()
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	locks := make([]LockInfo, len(entries))
+	for i := range entries {
+		locks[i] = entries[i].Lock
+	}
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+	if err := dtm.inspectLocks(ctx, locks); err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Lock = locks[i]
+	}
+	return entries, nil
+}
+
+// nextRun 任务的下一次调度时间，调度器未启动时根据表达式计算
+func (dtm *DistributedTaskManager) nextRun(t *distributedTask) time.Time {
+	next := dtm.cron.Entry(t.entryID).Next
+	if next.IsZero() {
+		next = t.schedule.Next(dtm.clock.Now())
+	}
+	return next
+}
@@ -0,0 +1,56 @@
+package redCorn
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLSCfg Redis 的 TLS 配置，无需自行构造 tls.Config
+type TLSCfg struct {
+	Enabled            bool   // 启用 TLS，只启用时使用系统根证书校验服务端
+	CAFile             string // 校验服务端证书的 CA 证书文件（PEM），为空表示使用系统根证书
+	CertFile           string // 客户端证书文件（PEM），双向认证时与 KeyFile 一起配置
+	KeyFile            string // 客户端私钥文件（PEM）
+	ServerName         string // SNI 和证书校验使用的服务端名称，为空表示使用连接地址中的主机名
+	InsecureSkipVerify bool   // 跳过服务端证书校验，仅用于测试
+}
+
+// tlsConfig 根据配置构造 tls.Config，未启用时返回 nil
+func (c TLSCfg) tlsConfig() (*tls.Config, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("failed to parse CA file %s: no PEM certificates found", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return nil, errors.New("TLS CertFile and KeyFile must be set together")
+	}
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,143 @@
+package redCorn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	goredislib "github.com/go-redis/redis/v8"
+)
+
+// RedisMode Redis 的部署模式
+type RedisMode string
+
+const (
+	// RedisModeSingle 单个 Redis 实例，RedisCfg.Addrs 只能有一个地址
+	RedisModeSingle RedisMode = "single"
+	// RedisModeSentinel 哨兵模式，RedisCfg.Addrs 为哨兵地址，必须配置 RedisCfg.MasterName
+	RedisModeSentinel RedisMode = "sentinel"
+	// RedisModeCluster 集群模式，RedisCfg.Addrs 为集群节点地址
+	RedisModeCluster RedisMode = "cluster"
+)
+
+// startupCheckExpiry 启动检查使用的锁的过期时间
+const startupCheckExpiry = 10 * time.Second
+
+// newRedisClient 按部署模式创建 Redis 客户端，未配置模式时由 go-redis 根据 RedisCfg 自动选择
+func newRedisClient(mode RedisMode, cfg *goredislib.UniversalOptions) (goredislib.UniversalClient, error) {
+	switch mode {
+	case "":
+		return goredislib.NewUniversalClient(cfg), nil
+	case RedisModeSingle:
+		if cfg.MasterName != "" {
+			return nil, fmt.Errorf("redis mode %q does not use RedisCfg.MasterName %q, use %q for Sentinel", mode, cfg.MasterName, RedisModeSentinel)
+		}
+		if len(cfg.Addrs) != 1 {
+			return nil, fmt.Errorf("redis mode %q requires exactly one address in RedisCfg.Addrs, got %d", mode, len(cfg.Addrs))
+		}
+		return goredislib.NewClient(cfg.Simple()), nil
+	case RedisModeSentinel:
+		if cfg.MasterName == "" {
+			return nil, fmt.Errorf("redis mode %q requires RedisCfg.MasterName", mode)
+		}
+		if len(cfg.Addrs) == 0 {
+			return nil, fmt.Errorf("redis mode %q requires Sentinel addresses in RedisCfg.Addrs", mode)
+		}
+		return goredislib.NewFailoverClient(cfg.Failover()), nil
+	case RedisModeCluster:
+		if cfg.MasterName != "" {
+			return nil, fmt.Errorf("redis mode %q does not use RedisCfg.MasterName %q", mode, cfg.MasterName)
+		}
+		if len(cfg.Addrs) == 0 {
+			return nil, fmt.Errorf("redis mode %q requires cluster node addresses in RedisCfg.Addrs", mode)
+		}
+		return goredislib.NewClusterClient(cfg.Cluster()), nil
+	default:
+		return nil, fmt.Errorf("invalid redis mode %q", string(mode))
+	}
+}
+
+// checkClientMode 检查复用的 Redis 客户端与部署模式是否一致
+func checkClientMode(mode RedisMode, client goredislib.UniversalClient) error {
+	_, isCluster := client.(*goredislib.ClusterClient)
+	switch mode {
+	case "":
+		return nil
+	case RedisModeSingle, RedisModeSentinel:
+		if isCluster {
+			return fmt.Errorf("redis mode %q does not match RedisClient of type %T", mode, client)
+		}
+	case RedisModeCluster:
+		if !isCluster {
+			return fmt.Errorf("redis mode %q requires a *redis.ClusterClient as RedisClient, got %T", mode, client)
+		}
+	default:
+		return fmt.Errorf("invalid redis mode %q", string(mode))
+	}
+	return nil
+}
+
+// connectError 连接 Redis 失败的错误，哨兵模式下提示主节点名称
+func connectError(mode RedisMode, masterName string, err error) error {
+	if mode == RedisModeSentinel {
+		return fmt.Errorf("failed to connect to Redis master %q via Sentinel: %v", masterName, err)
+	}
+	return fmt.Errorf("failed to connect to Redis: %v", err)
+}
+
+// verifyTopology 确认连接到的 Redis 与部署模式一致
+func verifyTopology(ctx context.Context, mode RedisMode, client goredislib.UniversalClient) error {
+	switch mode {
+	case RedisModeSingle:
+		// 部分代理和托管服务不支持 INFO cluster，无法判断时跳过
+		info, err := client.Info(ctx, "cluster").Result()
+		if err == nil && infoField(info, "cluster_enabled") == "1" {
+			return fmt.Errorf("redis mode %q connected to a node with cluster mode enabled, use %q", mode, RedisModeCluster)
+		}
+	case RedisModeSentinel:
+		role, err := client.Do(ctx, "ROLE").Slice()
+		if err != nil {
+			return fmt.Errorf("failed to verify Redis mode %q: %v", mode, err)
+		}
+		if len(role) == 0 || role[0] != "master" {
+			return fmt.Errorf("redis mode %q resolved to a node that is not a master (ROLE %v), check RedisCfg.MasterName", mode, role)
+		}
+	case RedisModeCluster:
+		info, err := client.(*goredislib.ClusterClient).ClusterInfo(ctx).Result()
+		if err != nil {
+			return fmt.Errorf("failed to verify Redis mode %q: %v", mode, err)
+		}
+		if state := infoField(info, "cluster_state"); state != "ok" {
+			return fmt.Errorf("redis mode %q: cluster state is %q, expected \"ok\"", mode, state)
+		}
+	}
+	return nil
+}
+
+// verifyLockRoundTrip 获取并释放一次检查用的锁，确认锁后端在实际部署上可用
+func verifyLockRoundTrip(ctx context.Context, locker Locker, key, value string) error {
+	mutex := locker.NewMutex(key, LockOptions{Expiry: startupCheckExpiry, Tries: 1, Value: value})
+	if err := mutex.Lock(ctx); err != nil {
+		return fmt.Errorf("failed to verify lock round-trip on key %s: %v", key, err)
+	}
+	ok, err := mutex.Unlock(ctx)
+	if err == nil && !ok {
+		err = errors.New("lock was not released")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to verify lock round-trip on key %s: %v", key, err)
+	}
+	return nil
+}
+
+// infoField 读取 INFO 格式文本中的字段值
+func infoField(info, name string) string {
+	for _, line := range strings.Split(info, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), name+":"); ok {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package redCorn
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName 创建 Tracer 时使用的 instrumentation 名称
+const tracerName = "github.com/kzdgt/redCorn"
+
+// Span 属性名
+const (
+	attrTaskName     = attribute.Key("redcorn.task.name")
+	attrTaskSchedule = attribute.Key("redcorn.task.schedule")
+	attrNodeID       = attribute.Key("redcorn.node.id")
+	attrRunID        = attribute.Key("redcorn.run.id")
+	attrLockAcquired = attribute.Key("redcorn.lock.acquired")
+)
+
+// startTaskSpan 为一次任务执行创建 span，返回的上下文携带该 span 和执行标识并最终传入任务函数
+func (dtm *DistributedTaskManager) startTaskSpan(t *distributedTask, runID string) (context.Context, trace.Span) {
+	ctx, span := dtm.tracer.Start(dtm.ctx, "redcorn.task "+t.name,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attrTaskName.String(t.name),
+			attrTaskSchedule.String(t.spec),
+			attrNodeID.String(dtm.nodeID),
+			attrRunID.String(runID),
+		),
+	)
+	return withRunID(ctx, runID), span
+}
+
+// recordSpanError 在 span 上记录错误
+func recordSpanError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,208 @@
+package redCorn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	goredislib "github.com/go-redis/redis/v8"
+)
+
+// ErrLockNotHeld 锁未被持有
+var ErrLockNotHeld = errors.New("lock not held")
+
+// ErrLockHolderAlive 持有锁的节点仍在运行，不能强制释放
+var ErrLockHolderAlive = errors.New("lock holder is still alive")
+
+// ErrForceUnlockUnsupported 锁后端不支持强制释放锁
+var ErrForceUnlockUnsupported = errors.New("lock backend does not support force unlock")
+
+// AuditForceUnlock 强制释放锁的审计操作
+const AuditForceUnlock = "force_unlock"
+
+// auditLimit 审计记录的保留条数
+const auditLimit = 1000
+
+// LockBreaker 可选接口，Locker 实现后可以通过 ForceUnlock 强制释放已下线节点遗留的锁
+type LockBreaker interface {
+	// BreakLock 在锁的值仍为 holder 时删除锁，锁已释放或已被其他执行持有时返回 false
+	BreakLock(ctx context.Context, key, holder string) (bool, error)
+}
+
+// AuditEntry 管理操作的审计记录，保存在 <Namespace>:audit 列表中
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	Action   string    `json:"action"`
+	Task     string    `json:"task,omitempty"`
+	Key      string    `json:"key,omitempty"`
+	Holder   string    `json:"holder,omitempty"` // 被强制释放的锁的值
+	Reason   string    `json:"reason,omitempty"` // 暂停集群的原因
+	Operator string    `json:"operator"`         // 执行操作的节点或用户
+}
+
+// forceUnlockScript 锁的值仍为被确认下线的持有者时才删除，避免误删新的执行刚获取的锁
+var forceUnlockScript = goredislib.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// ForceUnlock 强制释放任务的锁，用于清理崩溃节点遗留的长过期时间的锁
+// 只有确认持有锁的节点已下线（超过 30 秒没有心跳）才会删除锁，并写入审计记录；返回被释放的锁
+// 持有者仍在运行时返回 ErrLockHolderAlive，锁未被持有时返回 ErrLockNotHeld
+func (dtm *DistributedTaskManager) ForceUnlock(taskName string) (LockInfo, error) {
+	dtm.mu.RLock()
+	t, exists := dtm.tasks[taskName]
+	dtm.mu.RUnlock()
+	if !exists {
+		return LockInfo{}, fmt.Errorf("%w: %s", ErrTaskNotFound, taskName)
+	}
+	breaker, ok := dtm.locker.(LockBreaker)
+	if !ok {
+		return LockInfo{}, ErrForceUnlockUnsupported
+	}
+
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+	lock, err := dtm.LockHolder(taskName)
+	if err != nil {
+		return LockInfo{}, err
+	}
+	if !lock.Locked {
+		return lock, fmt.Errorf("%w: %s", ErrLockNotHeld, lock.Key)
+	}
+	if lock.Node == dtm.nodeID {
+		return lock, fmt.Errorf("%w: %s holds lock %s", ErrLockHolderAlive, lock.Node, lock.Key)
+	}
+	if err := dtm.controller.checkHolderGone(ctx, lock); err != nil {
+		return lock, err
+	}
+
+	released, err := breaker.BreakLock(ctx, lock.Key, lock.Holder)
+	if err != nil {
+		return lock, fmt.Errorf("failed to force unlock %s: %v", lock.Key, err)
+	}
+	if !released {
+		return lock, fmt.Errorf("failed to force unlock %s: lock was released or taken over", lock.Key)
+	}
+
+	dtm.logRun(slog.LevelWarn, t.name, "", "Lock force unlocked",
+		slog.String("key", lock.Key), slog.String("holder", lock.Holder))
+	entry := AuditEntry{Time: dtm.clock.Now(), Action: AuditForceUnlock, Task: t.name, Key: lock.Key, Holder: lock.Holder, Operator: dtm.nodeID}
+	if err := dtm.controller.recordAudit(ctx, entry); err != nil {
+		dtm.log.Error("Failed to record audit entry: ", err)
+	}
+	return lock, nil
+}
+
+// ForceUnlock 强制释放任务的锁，规则同 DistributedTaskManager.ForceUnlock，operator 记录在审计记录中
+// 直接删除 Redis 中的锁键，只适用于锁保存在同一个 Redis 中的默认配置
+func (c *Controller) ForceUnlock(ctx context.Context, name, operator string) (LockInfo, error) {
+	def, err := c.task(ctx, name)
+	if err != nil {
+		return LockInfo{}, err
+	}
+
+	locks := []LockInfo{{Task: name, Key: def.LockKey}}
+	if err := inspectLocks(ctx, c.client, locks); err != nil {
+		return LockInfo{}, err
+	}
+	parseHolders(locks)
+	lock := locks[0]
+	if !lock.Locked {
+		return lock, fmt.Errorf("%w: %s", ErrLockNotHeld, lock.Key)
+	}
+	if err := c.checkHolderGone(ctx, lock); err != nil {
+		return lock, err
+	}
+
+	n, err := forceUnlockScript.Run(ctx, c.client, []string{lock.Key}, lock.Holder).Int()
+	if err != nil {
+		return lock, fmt.Errorf("failed to force unlock %s: %v", lock.Key, err)
+	}
+	if n == 0 {
+		return lock, fmt.Errorf("failed to force unlock %s: lock was released or taken over", lock.Key)
+	}
+
+	entry := AuditEntry{Time: c.clock.Now(), Action: AuditForceUnlock, Task: name, Key: lock.Key, Holder: lock.Holder, Operator: operator}
+	if err := c.recordAudit(ctx, entry); err != nil {
+		return lock, err
+	}
+	return lock, nil
+}
+
+// task 读取注册到 Redis 中的任务定义
+func (c *Controller) task(ctx context.Context, name string) (TaskDefinition, error) {
+	item, err := c.client.HGet(ctx, namespacedKey(c.namespace, "tasks"), name).Result()
+	if err == goredislib.Nil {
+		return TaskDefinition{}, fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+	if err != nil {
+		return TaskDefinition{}, fmt.Errorf("failed to get task %s: %v", name, err)
+	}
+
+	var def TaskDefinition
+	if err := json.Unmarshal([]byte(item), &def); err != nil {
+		return TaskDefinition{}, fmt.Errorf("failed to decode task definition: %v", err)
+	}
+	return def, nil
+}
+
+// checkHolderGone 确认持有锁的节点已下线
+func (c *Controller) checkHolderGone(ctx context.Context, lock LockInfo) error {
+	if lock.Node == "" {
+		return fmt.Errorf("cannot determine the node holding lock %s (holder %q)", lock.Key, lock.Holder)
+	}
+	alive, err := c.nodeAlive(ctx, lock.Node)
+	if err != nil {
+		return err
+	}
+	if alive {
+		return fmt.Errorf("%w: %s holds lock %s", ErrLockHolderAlive, lock.Node, lock.Key)
+	}
+	return nil
+}
+
+// recordAudit 写入一条审计记录，只保留最近的 1000 条
+func (c *Controller) recordAudit(ctx context.Context, entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %v", err)
+	}
+
+	key := namespacedKey(c.namespace, "audit")
+	pipe := c.client.TxPipeline()
+	pipe.LPush(ctx, key, data)
+	pipe.LTrim(ctx, key, 0, auditLimit-1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record audit entry: %v", err)
+	}
+	return nil
+}
+
+// AuditLog 获取集群中的管理操作审计记录，最新的在最前，limit<=0 时返回全部保留的记录
+func (c *Controller) AuditLog(ctx context.Context, limit int64) ([]AuditEntry, error) {
+	stop := int64(-1)
+	if limit > 0 {
+		stop = limit - 1
+	}
+
+	items, err := c.client.LRange(ctx, namespacedKey(c.namespace, "audit"), 0, stop).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %v", err)
+	}
+
+	entries := make([]AuditEntry, 0, len(items))
+	for _, item := range items {
+		var entry AuditEntry
+		if err := json.Unmarshal([]byte(item), &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode audit entry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
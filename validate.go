@@ -0,0 +1,111 @@
+package redCorn
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ConfigError Cfg.Validate 返回的错误，列出所有不合法的配置项，可通过 errors.Is / errors.As 检查其中的单个错误
+type ConfigError struct {
+	Problems []error
+}
+
+func (e *ConfigError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.Error()
+	}
+	if len(msgs) == 1 {
+		return "invalid config: " + msgs[0]
+	}
+	return fmt.Sprintf("invalid config (%d problems): %s", len(msgs), strings.Join(msgs, "; "))
+}
+
+// Unwrap 返回所有不合法的配置项
+func (e *ConfigError) Unwrap() []error {
+	return e.Problems
+}
+
+// Validate 检查配置，一次返回所有不合法的配置项而不是只返回第一个，返回的错误为 *ConfigError
+// NewDistributedTaskManager 创建前会调用；也可以在部署前单独调用，提前发现配置错误
+func (cfg Cfg) Validate() error {
+	var problems []error
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	// 复用 RedisClient 或使用进程内后端时不需要 Redis 地址
+	if cfg.RedisClient == nil && cfg.LockBackend != LockBackendMemory && len(cfg.RedisCfg.Addrs) == 0 {
+		add("RedisCfg.Addrs is empty: set at least one Redis address such as \"localhost:6379\" (%s), or set RedisClient", EnvRedisAddrs)
+	}
+	for i, addr := range cfg.RedisCfg.Addrs {
+		if strings.TrimSpace(addr) == "" {
+			add("RedisCfg.Addrs[%d] is empty: remove it or set a Redis address", i)
+		}
+	}
+	for i, quorum := range cfg.RedisCfgQuorum {
+		if len(quorum.Addrs) == 0 {
+			add("RedisCfgQuorum[%d].Addrs is empty: set the address of the Redlock instance", i)
+		}
+	}
+	switch cfg.RedisMode {
+	case "", RedisModeSingle, RedisModeSentinel, RedisModeCluster:
+	default:
+		add("invalid RedisMode %q: must be %q, %q or %q", cfg.RedisMode, RedisModeSingle, RedisModeSentinel, RedisModeCluster)
+	}
+	switch cfg.LockBackend {
+	case "", LockBackendRedis:
+	case LockBackendMemory:
+		if registeredMemoryServer() == nil {
+			problems = append(problems, errMemoryBackendNotRegistered)
+		}
+	default:
+		add("invalid lock backend %q: must be %q or %q", cfg.LockBackend, LockBackendRedis, LockBackendMemory)
+	}
+
+	if cfg.LockCfg.Expiry <= 0 {
+		add("invalid LockCfg.Expiry %s: must be positive, longer than the usual task duration, e.g. 30 * time.Second (%s)", cfg.LockCfg.Expiry, EnvLockExpiry)
+	}
+	if cfg.LockCfg.Prefix == "" {
+		add("LockCfg.Prefix is empty: set a prefix such as \"myapp:lock:\" so that lock keys do not collide with other keys (%s)", EnvLockPrefix)
+	}
+	if err := cfg.LockCfg.validate(); err != nil {
+		problems = append(problems, err)
+	}
+
+	if _, err := cfg.CronFormat.parser(); err != nil {
+		add("invalid CronFormat: %v: must be %q, %q or %q", err, CronFormatSeconds, CronFormatStandard, CronFormatOptionalSeconds)
+	}
+	if cfg.LogLevel != "" {
+		if _, err := cfg.LogLevel.slogLevel(); err != nil {
+			add("%v: must be %q, %q, %q or %q", err, LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError)
+		}
+	}
+	if _, err := newNodeID(cfg.NodeID, cfg.InstanceID); err != nil {
+		problems = append(problems, err)
+	}
+	if err := cfg.Load.validate(); err != nil {
+		problems = append(problems, err)
+	}
+	if err := cfg.Role.validate(); err != nil {
+		problems = append(problems, err)
+	}
+
+	// 按名称顺序检查限流器，错误信息稳定
+	names := make([]string, 0, len(cfg.RateLimits))
+	for name := range cfg.RateLimits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := cfg.RateLimits[name].validate(name); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ConfigError{Problems: problems}
+	}
+	return nil
+}
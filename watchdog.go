@@ -0,0 +1,57 @@
+package redCorn
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// startWatchdog 启动锁续期看门狗，任务执行期间按 LockCfg.ExtendInterval 定期续期分布式锁
+// 续期失败说明锁已丢失，此时取消任务上下文；超过 LockCfg.MaxLifetime 后停止续期，锁随后自然过期
+// 返回的函数用于停止看门狗，并等待其退出
+func (dtm *DistributedTaskManager) startWatchdog(ctx context.Context, cancel context.CancelFunc, taskName string, mutex *taskMutex) func() {
+	interval := dtm.cfg.LockCfg.ExtendInterval
+	if interval <= 0 || mutex == nil {
+		return func() {}
+	}
+
+	var deadline time.Time
+	if dtm.cfg.LockCfg.MaxLifetime > 0 {
+		deadline = dtm.clock.Now().Add(dtm.cfg.LockCfg.MaxLifetime)
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		for {
+			timer := dtm.clock.NewTimer(interval)
+			select {
+			case <-done:
+				timer.Stop()
+				return
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.Chan():
+			}
+
+			if !deadline.IsZero() && dtm.clock.Now().After(deadline) {
+				dtm.logRun(slog.LevelWarn, taskName, RunIDFromContext(ctx), "Lock max lifetime reached, stopping lock extension")
+				return
+			}
+
+			if ok, err := mutex.extend(ctx); !ok || err != nil {
+				dtm.logRun(slog.LevelError, taskName, RunIDFromContext(ctx), "Failed to extend lock, cancelling execution", slog.Any("error", err))
+				cancel()
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
@@ -0,0 +1,367 @@
+package redCorn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	goredislib "github.com/go-redis/redis/v8"
+)
+
+const (
+	defaultWorkQueueGroup = "redcorn"    // 默认的消费者组名称
+	defaultWorkQueueBlock = time.Second  // 默认每次读取等待新消息的最长时间
+	defaultWorkQueueBatch = 10           // 默认每次读取的最大消息数
+	defaultClaimIdle      = time.Minute  // 默认的消息接手时间
+	defaultMaxDeliveries  = 5            // 默认的最多投递次数
+	deadJobsLimit         = 1000         // 死信队列保留的最大消息数
+	workQueueRetryDelay   = time.Second  // 读取出错后重试的间隔
+	jobStreamField        = "job"        // 消息中保存任务的字段
+	deliveriesStreamField = "deliveries" // 死信消息中保存投递次数的字段
+)
+
+// WorkQueueCfg 基于 Redis Streams 消费者组的工作队列配置
+type WorkQueueCfg struct {
+	Group string // 消费者组名称，默认 "redcorn"，集群中消费同一队列的节点需一致
+	// Block 每次读取等待新消息的最长时间，默认1秒，也是停止时消费协程退出的最长等待时间
+	Block time.Duration
+	// BatchSize 每次读取的最大消息数，同一批消息并发执行，全部结束后再读取下一批，默认10
+	BatchSize int
+	// ClaimIdle 消息被取出后超过该时长仍未确认（执行失败或节点崩溃）时由其他节点接手重试，默认1分钟，应大于任务的执行时间
+	ClaimIdle time.Duration
+	// MaxDeliveries 每条消息最多投递的次数，超过后移入死信队列，默认5
+	MaxDeliveries int64
+}
+
+// withDefaults 填充未配置的默认值
+func (c WorkQueueCfg) withDefaults() WorkQueueCfg {
+	if c.Group == "" {
+		c.Group = defaultWorkQueueGroup
+	}
+	if c.Block <= 0 {
+		c.Block = defaultWorkQueueBlock
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultWorkQueueBatch
+	}
+	if c.ClaimIdle <= 0 {
+		c.ClaimIdle = defaultClaimIdle
+	}
+	if c.MaxDeliveries <= 0 {
+		c.MaxDeliveries = defaultMaxDeliveries
+	}
+	return c
+}
+
+// streamKey 名为 name 的工作队列
+func (c *Controller) streamKey(name string) string {
+	return namespacedKey(c.namespace, "stream", name)
+}
+
+// deadStreamKey 工作队列 key 的死信队列
+func deadStreamKey(key string) string {
+	return key + ":dead"
+}
+
+// workQueue 一个工作队列及执行其中消息的处理函数
+type workQueue struct {
+	name    string // 任务名称，用于日志
+	key     string
+	handler JobHandler
+	stop    chan struct{} // 关闭后消费协程退出，为 nil 时消费到管理器停止
+}
+
+// stopped 是否已单独停止消费
+func (q *workQueue) stopped() bool {
+	select {
+	case <-q.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// PushJob 将任务加入工作队列，由注册了 job.Name 处理函数的某一个节点尽快执行
+// 执行失败或执行期间节点崩溃时由其他节点接手重试，保证至少执行一次；job.ID 为空时自动生成，返回加入队列的任务
+func (c *Controller) PushJob(ctx context.Context, job Job) (Job, error) {
+	if job.Name == "" {
+		return Job{}, errors.New("failed to push job: name is required")
+	}
+	return c.pushJob(ctx, c.streamKey(job.Name), job)
+}
+
+// pushJob 将任务加入工作队列 key
+func (c *Controller) pushJob(ctx context.Context, key string, job Job) (Job, error) {
+	if job.ID == "" {
+		job.ID = newRunID()
+	}
+	job.EnqueuedAt = c.clock.Now()
+	job.DueAt = job.EnqueuedAt
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return Job{}, fmt.Errorf("failed to encode job: %v", err)
+	}
+	args := &goredislib.XAddArgs{Stream: key, Values: map[string]interface{}{jobStreamField: data}}
+	if err := c.client.XAdd(ctx, args).Err(); err != nil {
+		return Job{}, fmt.Errorf("failed to push job %s: %v", job.Name, err)
+	}
+	return job, nil
+}
+
+// DeadJobs 列出名为 name 的工作队列中超过最大投递次数被移入死信队列的任务，最新的在最前，limit<=0 时返回全部保留的任务
+func (c *Controller) DeadJobs(ctx context.Context, name string, limit int64) ([]Job, error) {
+	key := deadStreamKey(c.streamKey(name))
+	var msgs []goredislib.XMessage
+	var err error
+	if limit > 0 {
+		msgs, err = c.client.XRevRangeN(ctx, key, "+", "-", limit).Result()
+	} else {
+		msgs, err = c.client.XRevRange(ctx, key, "+", "-").Result()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead jobs %s: %v", name, err)
+	}
+
+	jobs := make([]Job, 0, len(msgs))
+	for _, msg := range msgs {
+		job, err := decodeStreamJob(msg)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// decodeStreamJob 解析消息中的任务
+func decodeStreamJob(msg goredislib.XMessage) (Job, error) {
+	data, _ := msg.Values[jobStreamField].(string)
+	var job Job
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		return Job{}, fmt.Errorf("failed to decode job %s: %v", msg.ID, err)
+	}
+	return job, nil
+}
+
+// PushJob 将任务加入工作队列，由集群中某一个注册了 name 处理函数的节点尽快执行，至少执行一次，返回任务标识
+func (dtm *DistributedTaskManager) PushJob(name string, payload []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+	job, err := dtm.controller.PushJob(ctx, Job{Name: name, Payload: payload, EnqueuedBy: dtm.nodeID})
+	if err != nil {
+		return "", err
+	}
+	dtm.logRun(slog.LevelDebug, name, job.ID, "Job pushed")
+	return job.ID, nil
+}
+
+// startConsumers 为已注册的处理函数启动工作队列消费协程，之后注册的处理函数在 HandleJob 中启动
+func (dtm *DistributedTaskManager) startConsumers() {
+	dtm.jobsMu.Lock()
+	defer dtm.jobsMu.Unlock()
+	dtm.consuming = true
+	for name, handler := range dtm.jobHandlers {
+		dtm.startConsumer(name, handler)
+	}
+}
+
+// stopConsumers 等待工作队列消费协程退出
+func (dtm *DistributedTaskManager) stopConsumers() {
+	dtm.jobsMu.Lock()
+	dtm.consuming = false
+	dtm.jobsMu.Unlock()
+	dtm.stopTaskQueues()
+	dtm.consumers.Wait()
+}
+
+// startConsumer 启动名为 name 的工作队列的消费协程，调用方需持有 dtm.jobsMu
+func (dtm *DistributedTaskManager) startConsumer(name string, handler JobHandler) {
+	dtm.startQueue(&workQueue{name: name, key: dtm.controller.streamKey(name), handler: handler})
+}
+
+// startQueue 启动工作队列的消费协程
+func (dtm *DistributedTaskManager) startQueue(q *workQueue) {
+	dtm.consumers.Add(1)
+	go func() {
+		defer dtm.consumers.Done()
+		dtm.consume(q)
+	}()
+}
+
+// consume 以当前节点为消费者读取工作队列，定期接手其他节点长时间未确认的消息，直到管理器停止或队列单独停止
+func (dtm *DistributedTaskManager) consume(q *workQueue) {
+	cfg := dtm.cfg.WorkQueue.withDefaults()
+
+	var lastClaim time.Time
+	groupReady := false
+	for !dtm.isStopping() && !q.stopped() {
+		if !groupReady {
+			if err := dtm.createGroup(q.key, cfg.Group); err != nil {
+				dtm.logRun(slog.LevelError, q.name, "", "Failed to create consumer group", slog.Any("error", err))
+				dtm.waitRetry()
+				continue
+			}
+			groupReady = true
+		}
+
+		if time.Since(lastClaim) >= cfg.ClaimIdle/2 {
+			dtm.reclaimJobs(q, cfg)
+			lastClaim = time.Now()
+		}
+
+		streams, err := dtm.redisClient.XReadGroup(dtm.ctx, &goredislib.XReadGroupArgs{
+			Group:    cfg.Group,
+			Consumer: dtm.nodeID,
+			Streams:  []string{q.key, ">"},
+			Count:    int64(cfg.BatchSize),
+			Block:    cfg.Block,
+		}).Result()
+		if err == goredislib.Nil {
+			continue
+		}
+		if err != nil {
+			if dtm.isStopping() {
+				return
+			}
+			// 队列被删除后重新创建消费者组
+			groupReady = !strings.HasPrefix(err.Error(), "NOGROUP")
+			dtm.logRun(slog.LevelError, q.name, "", "Failed to read work queue", slog.Any("error", err))
+			dtm.waitRetry()
+			continue
+		}
+		for _, stream := range streams {
+			dtm.runMessages(q, cfg, stream.Messages, nil)
+		}
+	}
+}
+
+// createGroup 创建消费者组，已存在时忽略
+func (dtm *DistributedTaskManager) createGroup(key, group string) error {
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+	err := dtm.redisClient.XGroupCreateMkStream(ctx, key, group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	return nil
+}
+
+// waitRetry 出错后等待一段时间再重试，管理器停止时立即返回
+func (dtm *DistributedTaskManager) waitRetry() {
+	timer := time.NewTimer(workQueueRetryDelay)
+	defer timer.Stop()
+	select {
+	case <-dtm.stopping:
+	case <-timer.C:
+	}
+}
+
+// reclaimJobs 接手超过 ClaimIdle 仍未确认的消息重新执行，投递次数已达上限的消息移入死信队列
+// 接手通过 XCLAIM 完成，同一条消息只会被一个节点接手
+func (dtm *DistributedTaskManager) reclaimJobs(q *workQueue, cfg WorkQueueCfg) {
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+
+	pending, err := dtm.redisClient.XPendingExt(ctx, &goredislib.XPendingExtArgs{
+		Stream: q.key,
+		Group:  cfg.Group,
+		Idle:   cfg.ClaimIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  int64(cfg.BatchSize),
+	}).Result()
+	if err == goredislib.Nil || (err == nil && len(pending) == 0) {
+		return
+	}
+	if err != nil {
+		dtm.logRun(slog.LevelError, q.name, "", "Failed to list pending jobs", slog.Any("error", err))
+		return
+	}
+
+	ids := make([]string, 0, len(pending))
+	deliveries := make(map[string]int64, len(pending))
+	for _, p := range pending {
+		ids = append(ids, p.ID)
+		deliveries[p.ID] = p.RetryCount
+	}
+	msgs, err := dtm.redisClient.XClaim(ctx, &goredislib.XClaimArgs{
+		Stream:   q.key,
+		Group:    cfg.Group,
+		Consumer: dtm.nodeID,
+		MinIdle:  cfg.ClaimIdle,
+		Messages: ids,
+	}).Result()
+	if err != nil {
+		dtm.logRun(slog.LevelError, q.name, "", "Failed to claim pending jobs", slog.Any("error", err))
+		return
+	}
+	dtm.runMessages(q, cfg, msgs, deliveries)
+}
+
+// runMessages 并发执行一批消息并等待全部结束，执行成功的消息被确认并删除，失败的消息留待重试
+// deliveries 为接手的消息此前的投递次数，达到上限的消息移入死信队列；停止过程中不再执行，消息留待其他节点接手
+func (dtm *DistributedTaskManager) runMessages(q *workQueue, cfg WorkQueueCfg, msgs []goredislib.XMessage, deliveries map[string]int64) {
+	if len(msgs) == 0 || !dtm.beginExecution() {
+		return
+	}
+	defer dtm.endExecution()
+
+	var wg sync.WaitGroup
+	for _, msg := range msgs {
+		if n, claimed := deliveries[msg.ID]; claimed && n >= cfg.MaxDeliveries {
+			dtm.deadLetter(q, cfg, msg, n)
+			continue
+		}
+		job, err := decodeStreamJob(msg)
+		if err != nil {
+			dtm.logRun(slog.LevelError, q.name, "", "Dropping malformed job", slog.Any("error", err))
+			dtm.ackJob(q, cfg, msg.ID)
+			continue
+		}
+
+		wg.Add(1)
+		go func(id string, job Job) {
+			defer wg.Done()
+			if err := dtm.runJob(job, q.handler); err != nil {
+				return
+			}
+			dtm.ackJob(q, cfg, id)
+		}(msg.ID, job)
+	}
+	wg.Wait()
+}
+
+// ackJob 确认并删除已处理的消息
+func (dtm *DistributedTaskManager) ackJob(q *workQueue, cfg WorkQueueCfg, id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	pipe := dtm.redisClient.TxPipeline()
+	pipe.XAck(ctx, q.key, cfg.Group, id)
+	pipe.XDel(ctx, q.key, id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		dtm.logRun(slog.LevelError, q.name, "", "Failed to acknowledge job", slog.String("message_id", id), slog.Any("error", err))
+	}
+}
+
+// deadLetter 将投递次数达到上限的消息移入死信队列，死信队列最多保留约 1000 条
+func (dtm *DistributedTaskManager) deadLetter(q *workQueue, cfg WorkQueueCfg, msg goredislib.XMessage, deliveries int64) {
+	ctx, cancel := context.WithTimeout(dtm.ctx, redisOpTimeout)
+	defer cancel()
+
+	values := map[string]interface{}{jobStreamField: msg.Values[jobStreamField], deliveriesStreamField: deliveries}
+	args := &goredislib.XAddArgs{Stream: deadStreamKey(q.key), MaxLen: deadJobsLimit, Approx: true, Values: values}
+	if err := dtm.redisClient.XAdd(ctx, args).Err(); err != nil {
+		dtm.logRun(slog.LevelError, q.name, "", "Failed to move job to dead letter queue", slog.String("message_id", msg.ID), slog.Any("error", err))
+		return
+	}
+	dtm.logRun(slog.LevelWarn, q.name, "", "Job exceeded max deliveries, moved to dead letter queue",
+		slog.String("message_id", msg.ID), slog.Int64("deliveries", deliveries))
+	dtm.ackJob(q, cfg, msg.ID)
+}